@@ -0,0 +1,129 @@
+package bench
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	slogg "github.com/hedzr/logg/slog"
+)
+
+// the common attribute types, boxed in an interface or held in a Value.
+//
+//	go test -benchmem -run=^$ -bench ^BenchmarkTypedAttrs$ github.com/hedzr/logg/bench -v
+func BenchmarkTypedAttrs(b *testing.B) {
+	ctx := context.Background()
+	attrs := make([]any, 6)
+
+	b.Run("build/boxed", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			attrs[0] = slogg.NewAttr("int", i)
+			attrs[1] = slogg.NewAttr("uint", uint64(i))
+			attrs[2] = slogg.NewAttr("float", float64(i))
+			attrs[3] = slogg.NewAttr("bool", i&1 == 0)
+			attrs[4] = slogg.NewAttr("dur", time.Duration(i))
+			attrs[5] = slogg.NewAttr("time", _tenTimes[i%10])
+		}
+	})
+
+	b.Run("build/typed", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			attrs[0] = slogg.Int("int", i)
+			attrs[1] = slogg.Uint64("uint", uint64(i))
+			attrs[2] = slogg.Float64("float", float64(i))
+			attrs[3] = slogg.Bool("bool", i&1 == 0)
+			attrs[4] = slogg.Duration("dur", time.Duration(i))
+			attrs[5] = slogg.Time("time", _tenTimes[i%10])
+		}
+	})
+
+	typed := []any{
+		slogg.String("string", _tenStrings[0]),
+		slogg.Int("int", _tenInts[0]),
+		slogg.Uint64("uint", 3),
+		slogg.Float64("float", 3.14),
+		slogg.Bool("bool", true),
+		slogg.Duration("dur", time.Second),
+		slogg.Time("time", _tenTimes[0]),
+	}
+	for _, c := range []struct {
+		name   string
+		logger slogg.Logger
+	}{
+		{"log/json", newLogg()},
+		{"log/logfmt", newLoggTextMode().SetMode(slogg.ModeLogFmt)},
+		{"log/text", newLoggTextMode()},
+	} {
+		b.Run(c.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				c.logger.InfoContext(ctx, getMessage(0), typed...)
+			}
+		})
+	}
+
+	b.Run("log/json/pairs", func(b *testing.B) {
+		pairs := []any{"string", _tenStrings[0], "int", _tenInts[0], "uint", uint64(3),
+			"float", 3.14, "bool", true, "dur", time.Second, "time", _tenTimes[0]}
+		logger := newLogg()
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			logger.InfoContext(ctx, getMessage(0), pairs...)
+		}
+	})
+
+	b.Run("log/json/logger-attrs", func(b *testing.B) {
+		var fields []slogg.Attr
+		for _, a := range typed {
+			fields = append(fields, a.(slogg.Attr))
+		}
+		logger := newLogg(fields...)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			logger.InfoContext(ctx, getMessage(0))
+		}
+	})
+}
+
+// the fluent events, compared with the typed attributes built for
+// each record.
+//
+//	go test -benchmem -run=^$ -bench ^BenchmarkEvent$ github.com/hedzr/logg/bench -v
+func BenchmarkEvent(b *testing.B) {
+	ctx := context.Background()
+	for _, c := range []struct {
+		name   string
+		logger slogg.Logger
+	}{
+		{"json", newLogg()},
+		{"logfmt", newLoggTextMode().SetMode(slogg.ModeLogFmt)},
+		{"text", newLoggTextMode()},
+	} {
+		b.Run(c.name+"/event", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				c.logger.InfoEvent().Str("string", _tenStrings[0]).Int("int", _tenInts[0]).
+					Float64("float", 3.14).Bool("bool", true).Dur("dur", time.Second).
+					Time("time", _tenTimes[0]).Msg(getMessage(0))
+			}
+		})
+		b.Run(c.name+"/attrs", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				c.logger.InfoContext(ctx, getMessage(0), slogg.String("string", _tenStrings[0]),
+					slogg.Int("int", _tenInts[0]), slogg.Float64("float", 3.14), slogg.Bool("bool", true),
+					slogg.Duration("dur", time.Second), slogg.Time("time", _tenTimes[0]))
+			}
+		})
+	}
+
+	b.Run("disabled", func(b *testing.B) {
+		logger := newLogg()
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			logger.DebugEvent().Str("string", _tenStrings[0]).Int("int", _tenInts[0]).Msg(getMessage(0))
+		}
+	})
+}
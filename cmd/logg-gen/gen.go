@@ -0,0 +1,514 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// maxInlineDepth is the max depth of the inlined embedded structs,
+// the same as the reflection encoder.
+const maxInlineDepth = 16
+
+// typeKind is the category of a field type.
+type typeKind int
+
+const (
+	kindOther    typeKind = iota // the types of the other packages, and the unknown ones
+	kindBasic                    // the predeclared basic types
+	kindTime                     // time.Time
+	kindDuration                 // time.Duration
+	kindStruct                   // the struct types
+	kindSlice                    // the slices
+	kindMap                      // the maps
+	kindArray                    // the arrays
+	kindNilable                  // the pointers, interfaces, funcs and chans
+)
+
+// typeInfo is the resolved info of a field type.
+type typeInfo struct {
+	kind   typeKind
+	basic  string // the predeclared type name of kindBasic, byte and rune are normalized
+	named  string // the name of the type of this package, if it is a named type
+	direct bool   // the type is not a named type of this package
+}
+
+var basicTypes = map[string]string{
+	"bool": "bool", "string": "string",
+	"int": "int", "int8": "int8", "int16": "int16", "int32": "int32", "int64": "int64", "rune": "int32",
+	"uint": "uint", "uint8": "uint8", "uint16": "uint16", "uint32": "uint32", "uint64": "uint64", "uintptr": "uintptr", "byte": "uint8",
+	"float32": "float32", "float64": "float64",
+	"complex64": "complex64", "complex128": "complex128",
+}
+
+// category returns the category of a basic type: string, bool,
+// int, uint, float32, float64, complex64 or complex128.
+func (s typeInfo) category() string {
+	switch {
+	case strings.HasPrefix(s.basic, "int"):
+		return "int"
+	case strings.HasPrefix(s.basic, "uint"):
+		return "uint"
+	}
+	return s.basic
+}
+
+// typeDecl is a type declaration of the package.
+type typeDecl struct {
+	spec *ast.TypeSpec
+	file *ast.File
+}
+
+// field is a struct field to be encoded.
+type field struct {
+	key       string
+	expr      string   // the field selector, such as "v.Base.ID"
+	conds     []string // the conditions of the embedded pointers
+	typ       ast.Expr
+	file      *ast.File
+	omitEmpty bool
+	redact    bool
+	asString  bool
+}
+
+type generator struct {
+	pkgName string
+	decls   map[string]typeDecl
+	gen     map[string]bool // the types to be generated
+	imports map[string]bool // the imports used by the generated code
+	buf     bytes.Buffer
+}
+
+// generate returns the source of the MarshalSlogObject methods of
+// the named struct types in dir. The file skip, the old output, is
+// not parsed.
+func generate(dir, skip string, names []string) ([]byte, error) {
+	g := &generator{
+		decls:   make(map[string]typeDecl),
+		gen:     make(map[string]bool),
+		imports: make(map[string]bool),
+	}
+	if err := g.parseDir(dir, skip); err != nil {
+		return nil, err
+	}
+	for _, name := range names {
+		g.gen[name] = true
+	}
+
+	var body bytes.Buffer
+	for _, name := range names {
+		g.buf.Reset()
+		if err := g.genType(name); err != nil {
+			return nil, err
+		}
+		body.Write(g.buf.Bytes())
+	}
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "// Code generated by logg-gen -type %s; DO NOT EDIT.\n\n", strings.Join(names, ","))
+	fmt.Fprintf(&out, "package %s\n\nimport (\n", g.pkgName)
+	for _, path := range []string{"strconv", "time"} {
+		if g.imports[path] {
+			fmt.Fprintf(&out, "\t%q\n", path)
+		}
+	}
+	out.WriteString("\n\t\"github.com/hedzr/logg/slog\"\n)\n")
+	out.Write(body.Bytes())
+
+	src, err := format.Source(out.Bytes())
+	if err != nil {
+		return out.Bytes(), fmt.Errorf("bad generated code: %w", err)
+	}
+	return src, nil
+}
+
+func (g *generator) parseDir(dir, skip string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	fset := token.NewFileSet()
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") || name == skip {
+			continue
+		}
+		f, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, parser.SkipObjectResolution)
+		if err != nil {
+			return err
+		}
+		if g.pkgName == "" {
+			g.pkgName = f.Name.Name
+		} else if g.pkgName != f.Name.Name {
+			return fmt.Errorf("multiple packages in %s: %s and %s", dir, g.pkgName, f.Name.Name)
+		}
+		for _, decl := range f.Decls {
+			if gd, ok := decl.(*ast.GenDecl); ok && gd.Tok == token.TYPE {
+				for _, spec := range gd.Specs {
+					ts := spec.(*ast.TypeSpec)
+					g.decls[ts.Name.Name] = typeDecl{ts, f}
+				}
+			}
+		}
+	}
+	if g.pkgName == "" {
+		return fmt.Errorf("no Go files in %s", dir)
+	}
+	return nil
+}
+
+func (g *generator) genType(name string) error {
+	d, ok := g.decls[name]
+	if !ok {
+		return fmt.Errorf("type %s not found", name)
+	}
+	st, ok := d.spec.Type.(*ast.StructType)
+	if !ok || d.spec.Assign.IsValid() {
+		return fmt.Errorf("type %s is not a struct", name)
+	}
+	if d.spec.TypeParams != nil {
+		return fmt.Errorf("generic type %s is not supported", name)
+	}
+	fields, err := g.fields(st, d.file, "v", nil, 0)
+	if err != nil {
+		return fmt.Errorf("type %s: %w", name, err)
+	}
+
+	fmt.Fprintf(&g.buf, "\n// MarshalSlogObject encodes %s without reflection.\n", name)
+	fmt.Fprintf(&g.buf, "func (v *%s) MarshalSlogObject(enc *slog.PrintCtx) error {\n", name)
+	g.buf.WriteString("if v == nil {\nenc.AppendValue(nil)\nreturn nil\n}\n")
+	g.buf.WriteString("if !enc.EnterValue(v) {\nreturn nil\n}\ndefer enc.LeaveValue()\n\nenc.Begin()\n")
+
+	conds := make([]string, len(fields))
+	first := len(fields) // the first field which is always encoded
+	for i, f := range fields {
+		c := f.conds
+		if f.omitEmpty {
+			ce, err := g.notEmpty(f)
+			if err != nil {
+				return fmt.Errorf("type %s: %w", name, err)
+			}
+			c = append(c[:len(c):len(c)], ce)
+		}
+		conds[i] = strings.Join(c, " && ")
+		if conds[i] == "" && first == len(fields) {
+			first = i
+		}
+	}
+	// the comma before a field depends on the conditional fields
+	// before it, if none of the fields before it is always encoded.
+	last := min(first, len(fields)-1) // the last field reading comma
+	if last >= 1 {
+		g.buf.WriteString("comma := false\n")
+	}
+
+	for i, f := range fields {
+		code, err := g.valueCode(f)
+		if err != nil {
+			return fmt.Errorf("type %s: %w", name, err)
+		}
+		if conds[i] != "" {
+			fmt.Fprintf(&g.buf, "if %s {\n", conds[i])
+		}
+		switch {
+		case i == 0:
+		case i > first:
+			g.buf.WriteString("enc.AddComma()\n")
+		default:
+			g.buf.WriteString("if comma {\nenc.AddComma()\n}\n")
+		}
+		g.buf.WriteString(code)
+		if conds[i] != "" {
+			if i < last {
+				g.buf.WriteString("comma = true\n")
+			}
+			g.buf.WriteString("}\n")
+		}
+	}
+	g.buf.WriteString("enc.End(false)\nreturn nil\n}\n")
+	return nil
+}
+
+// fields returns the fields of a struct, the embedded structs of
+// this package without tag name are inlined.
+func (g *generator) fields(st *ast.StructType, file *ast.File, prefix string, conds []string, depth int) (fields []field, err error) {
+	for _, af := range st.Fields.List {
+		var tag string
+		if af.Tag != nil {
+			raw, _ := strconv.Unquote(af.Tag.Value)
+			st := reflect.StructTag(raw)
+			var ok bool
+			if tag, ok = st.Lookup("logg"); !ok {
+				tag = st.Get("json")
+			}
+		}
+		if tag == "-" {
+			continue
+		}
+		key, opts, _ := strings.Cut(tag, ",")
+
+		names := make([]string, 0, len(af.Names))
+		for _, n := range af.Names {
+			names = append(names, n.Name)
+		}
+		if len(af.Names) == 0 { // embedded
+			typ, ptr := af.Type, false
+			if star, ok := typ.(*ast.StarExpr); ok {
+				typ, ptr = star.X, true
+			}
+			var typeName string
+			switch t := typ.(type) {
+			case *ast.Ident:
+				typeName = t.Name
+			case *ast.SelectorExpr:
+				typeName = t.Sel.Name
+				if key == "" {
+					return nil, fmt.Errorf("cannot inline the embedded type %s of another package, give it a tag name", types.ExprString(af.Type))
+				}
+			default:
+				return nil, fmt.Errorf("unsupported embedded type %s", types.ExprString(af.Type))
+			}
+			if key == "" {
+				if d, ok := g.decls[typeName]; ok && !d.spec.Assign.IsValid() {
+					if sub, ok := d.spec.Type.(*ast.StructType); ok && depth < maxInlineDepth {
+						expr := prefix + "." + typeName
+						subConds := conds
+						if ptr {
+							subConds = append(conds[:len(conds):len(conds)], expr+" != nil")
+						}
+						more, err := g.fields(sub, d.file, expr, subConds, depth+1)
+						if err != nil {
+							return nil, err
+						}
+						fields = append(fields, more...)
+						continue
+					}
+				}
+			}
+			names = append(names, typeName)
+		}
+
+		for _, n := range names {
+			if !ast.IsExported(n) {
+				continue
+			}
+			f := field{key: key, expr: prefix + "." + n, conds: conds, typ: af.Type, file: file}
+			if f.key == "" {
+				f.key = n
+			}
+			for opts != "" {
+				var opt string
+				opt, opts, _ = strings.Cut(opts, ",")
+				switch opt {
+				case "omitempty":
+					f.omitEmpty = true
+				case "redact":
+					f.redact = true
+				case "string":
+					f.asString = true
+				}
+			}
+			fields = append(fields, f)
+		}
+	}
+	return
+}
+
+// resolve returns the info of a type expression in file.
+func (g *generator) resolve(expr ast.Expr, file *ast.File) typeInfo {
+	return g.resolveDepth(expr, file, 0)
+}
+
+func (g *generator) resolveDepth(expr ast.Expr, file *ast.File, depth int) typeInfo {
+	switch t := expr.(type) {
+	case *ast.ParenExpr:
+		return g.resolveDepth(t.X, file, depth)
+	case *ast.Ident:
+		if basic, ok := basicTypes[t.Name]; ok {
+			return typeInfo{kind: kindBasic, basic: basic, direct: true}
+		}
+		switch t.Name {
+		case "any", "error":
+			return typeInfo{kind: kindNilable, direct: true}
+		}
+		d, ok := g.decls[t.Name]
+		if !ok || depth > maxInlineDepth {
+			return typeInfo{}
+		}
+		ti := g.resolveDepth(d.spec.Type, d.file, depth+1)
+		if !d.spec.Assign.IsValid() { // not an alias
+			ti.named, ti.direct = t.Name, false
+		}
+		return ti
+	case *ast.SelectorExpr:
+		if pkg, ok := t.X.(*ast.Ident); ok && importPath(file, pkg.Name) == "time" {
+			switch t.Sel.Name {
+			case "Time":
+				return typeInfo{kind: kindTime, direct: true}
+			case "Duration":
+				return typeInfo{kind: kindDuration, direct: true}
+			}
+		}
+	case *ast.StarExpr, *ast.InterfaceType, *ast.FuncType, *ast.ChanType:
+		return typeInfo{kind: kindNilable, direct: true}
+	case *ast.ArrayType:
+		if t.Len == nil {
+			return typeInfo{kind: kindSlice, direct: true}
+		}
+		return typeInfo{kind: kindArray, direct: true}
+	case *ast.MapType:
+		return typeInfo{kind: kindMap, direct: true}
+	case *ast.StructType:
+		return typeInfo{kind: kindStruct, direct: true}
+	}
+	return typeInfo{}
+}
+
+// importPath returns the path of an imported package by its name in
+// file.
+func importPath(file *ast.File, name string) string {
+	for _, imp := range file.Imports {
+		path, _ := strconv.Unquote(imp.Path.Value)
+		if imp.Name != nil {
+			if imp.Name.Name == name {
+				return path
+			}
+		} else if path[strings.LastIndex(path, "/")+1:] == name {
+			return path
+		}
+	}
+	return ""
+}
+
+// notEmpty returns the condition that a field is not empty, for the
+// omitempty option.
+func (g *generator) notEmpty(f field) (string, error) {
+	ti, x := g.resolve(f.typ, f.file), f.expr
+	switch ti.kind {
+	case kindBasic:
+		switch ti.category() {
+		case "string":
+			return x + ` != ""`, nil
+		case "bool":
+			return x, nil
+		}
+		return x + " != 0", nil
+	case kindDuration:
+		return x + " != 0", nil
+	case kindTime:
+		if ti.direct {
+			g.imports["time"] = true
+			return x + " != (time.Time{})", nil
+		}
+		return x + " != (" + ti.named + "{})", nil
+	case kindSlice, kindMap, kindArray:
+		return "len(" + x + ") != 0", nil
+	case kindNilable:
+		return x + " != nil", nil
+	case kindStruct:
+		if ti.named != "" {
+			return x + " != (" + ti.named + "{})", nil
+		}
+	}
+	return "", fmt.Errorf("field %s: cannot decide the zero value of %s for omitempty", f.key, types.ExprString(f.typ))
+}
+
+// valueCode returns the code encoding a field.
+func (g *generator) valueCode(f field) (string, error) {
+	ti, key, x := g.resolve(f.typ, f.file), strconv.Quote(f.key), f.expr
+
+	if f.redact {
+		return fmt.Sprintf("enc.AddString(%s, %q)\n", key, "******"), nil
+	}
+
+	if f.asString {
+		if ti.kind != kindBasic {
+			return "", fmt.Errorf("field %s: the string option needs a basic type, but got %s", f.key, types.ExprString(f.typ))
+		}
+		conv := func(to string) string {
+			if ti.direct && ti.basic == to {
+				return x
+			}
+			return to + "(" + x + ")"
+		}
+		var text string
+		switch ti.category() {
+		case "string":
+			return fmt.Sprintf("enc.AddString(%s, %s)\n", key, conv("string")), nil
+		case "bool":
+			text = "strconv.FormatBool(" + conv("bool") + ")"
+		case "int":
+			text = "strconv.FormatInt(" + conv("int64") + ", 10)"
+		case "uint":
+			text = "strconv.FormatUint(" + conv("uint64") + ", 10)"
+		case "float32":
+			text = "strconv.FormatFloat(float64(" + x + "), 'g', -1, 32)"
+		case "float64":
+			text = "strconv.FormatFloat(" + conv("float64") + ", 'g', -1, 64)"
+		default:
+			return "", fmt.Errorf("field %s: the string option doesn't support %s", f.key, types.ExprString(f.typ))
+		}
+		g.imports["strconv"] = true
+		return fmt.Sprintf("enc.AddString(%s, %s)\n", key, text), nil
+	}
+
+	if ti.direct {
+		switch ti.kind {
+		case kindBasic:
+			switch ti.category() {
+			case "string":
+				return fmt.Sprintf("enc.AddString(%s, %s)\n", key, x), nil
+			case "bool":
+				return fmt.Sprintf("enc.AddBool(%s, %s)\n", key, x), nil
+			case "int":
+				if ti.basic != "int64" {
+					x = "int64(" + x + ")"
+				}
+				return fmt.Sprintf("enc.AddInt64(%s, %s)\n", key, x), nil
+			case "uint":
+				if ti.basic != "uint64" {
+					x = "uint64(" + x + ")"
+				}
+				return fmt.Sprintf("enc.AddUint64(%s, %s)\n", key, x), nil
+			case "float32":
+				return fmt.Sprintf("enc.AddFloat32(%s, %s)\n", key, x), nil
+			case "float64":
+				return fmt.Sprintf("enc.AddFloat64(%s, %s)\n", key, x), nil
+			case "complex64":
+				return fmt.Sprintf("enc.AddComplex64(%s, %s)\n", key, x), nil
+			}
+			return fmt.Sprintf("enc.AddComplex128(%s, %s)\n", key, x), nil
+		case kindTime:
+			return fmt.Sprintf("enc.AddTime(%s, %s)\n", key, x), nil
+		case kindDuration:
+			return fmt.Sprintf("enc.AddDuration(%s, %s)\n", key, x), nil
+		}
+	}
+
+	// the nested objects and arrays of the generated types
+	if id, ok := f.typ.(*ast.Ident); ok && g.gen[id.Name] {
+		return fmt.Sprintf("enc.AppendStringKey(%s)\nenc.AddColon()\n"+
+			"if err := %s.MarshalSlogObject(enc); err != nil {\nreturn err\n}\n", key, x), nil
+	}
+	if at, ok := f.typ.(*ast.ArrayType); ok && at.Len == nil {
+		if id, ok := at.Elt.(*ast.Ident); ok && g.gen[id.Name] {
+			return fmt.Sprintf("enc.AppendStringKey(%s)\nenc.AddColon()\n"+
+				"if %[2]s == nil {\nenc.AppendValue(nil)\n} else {\n"+
+				"keep, more := enc.SliceLimit(len(%[2]s))\nenc.BeginArray()\n"+
+				"for i := range %[2]s[:keep] {\nif i > 0 {\nenc.AddComma()\n}\n"+
+				"if err := %[2]s[i].MarshalSlogObject(enc); err != nil {\nreturn err\n}\n}\n"+
+				"enc.AddMoreItems(keep, more)\nenc.EndArray(false)\n}\n", key, x), nil
+		}
+	}
+
+	return fmt.Sprintf("enc.AddAny(%s, %s)\n", key, x), nil
+}
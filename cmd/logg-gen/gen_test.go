@@ -0,0 +1,59 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update the golden file")
+
+// TestGolden checks the generated methods of package sample, whose
+// output is compared with the reflection encoder in sample_test.go.
+func TestGolden(t *testing.T) {
+	const dir, golden = "internal/sample", "sample_logg.go"
+	src, err := generate(dir, golden, []string{"User", "Item", "Note"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(dir, golden)
+	if *update {
+		if err = os.WriteFile(file, src, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(src, want) {
+		t.Fatalf("the generated code is different from %s, run 'go generate' or 'go test -update':\n%s", file, src)
+	}
+}
+
+func TestGenerateErrors(t *testing.T) {
+	for _, c := range []struct {
+		src, typ, want string
+	}{
+		{"type T int", "T", "not a struct"},
+		{"type T struct{}", "U", "not found"},
+		{"type T[E any] struct{ V E }", "T", "generic type"},
+		{"type T = struct{}", "T", "not a struct"},
+		{"import \"io\"\ntype T struct{ io.Reader }", "T", "cannot inline the embedded type io.Reader"},
+		{"import \"io\"\ntype T struct{ R io.LimitedReader `logg:\",omitempty\"` }", "T", "cannot decide the zero value"},
+		{"type T struct{ C complex64 `logg:\",string\"` }", "T", "doesn't support complex64"},
+		{"type T struct{ S []int `logg:\",string\"` }", "T", "needs a basic type"},
+	} {
+		dir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(dir, "a.go"), []byte("package p\n"+c.src+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		_, err := generate(dir, "", []string{c.typ})
+		if err == nil || !strings.Contains(err.Error(), c.want) {
+			t.Fatalf("%q: expect error %q, but got %v", c.src, c.want, err)
+		}
+	}
+}
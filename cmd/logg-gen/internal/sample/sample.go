@@ -0,0 +1,72 @@
+// Package sample holds the types for testing logg-gen, the methods in
+// sample_logg.go are generated by it.
+package sample
+
+import (
+	"errors"
+	stdtime "time"
+)
+
+//go:generate go run github.com/hedzr/logg/cmd/logg-gen -type User,Item,Note -output sample_logg.go
+
+type Base struct {
+	ID      int64        `logg:"id"`
+	Created stdtime.Time `logg:"created,omitempty"`
+}
+
+type Meta struct {
+	Source string
+	Trace  []byte `logg:"trace,omitempty"`
+}
+
+type Status int
+
+func (s Status) String() string {
+	switch s {
+	case 1:
+		return "active"
+	case 2:
+		return "locked"
+	}
+	return "unknown"
+}
+
+type User struct {
+	Base
+	*Meta
+	Name     string           `json:"name"`
+	Password string           `logg:"password,redact"`
+	Age      uint8            `logg:"age"`
+	Score    float32          `logg:"score"`
+	Ratio    float64          `logg:"ratio,omitempty"`
+	Active   bool             `logg:"active"`
+	Count    int              `logg:"count,string"`
+	Status   Status           `logg:"status"`
+	Code     Status           `logg:"code,string"`
+	Timeout  stdtime.Duration `logg:"timeout"`
+	Tags     []string         `logg:"tags,omitempty"`
+	Labels   map[string]int   `logg:"labels"`
+	Note     Note             `logg:"note"`
+	Items    []Item           `logg:"items"`
+	Refs     []*Item          `logg:"refs,omitempty"`
+	Next     *User            `logg:"next,omitempty"`
+	Extra    any              `logg:"extra"`
+	Err      error            `logg:"err,omitempty"`
+	Skipped  string           `logg:"-"`
+	internal int
+}
+
+type Item struct {
+	SKU   string  `logg:"sku"`
+	Price float64 `logg:"price"`
+	Owner *User   `logg:"owner,omitempty"`
+}
+
+type Note struct {
+	Text  string `logg:",omitempty"`
+	Lang  string `logg:",omitempty"`
+	Level int
+}
+
+// ErrSample is an error value for the tests.
+var ErrSample = errors.New("sample")
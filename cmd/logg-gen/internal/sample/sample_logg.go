@@ -0,0 +1,161 @@
+// Code generated by logg-gen -type User,Item,Note; DO NOT EDIT.
+
+package sample
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/hedzr/logg/slog"
+)
+
+// MarshalSlogObject encodes User without reflection.
+func (v *User) MarshalSlogObject(enc *slog.PrintCtx) error {
+	if v == nil {
+		enc.AppendValue(nil)
+		return nil
+	}
+	if !enc.EnterValue(v) {
+		return nil
+	}
+	defer enc.LeaveValue()
+
+	enc.Begin()
+	enc.AddInt64("id", v.Base.ID)
+	if v.Base.Created != (time.Time{}) {
+		enc.AddComma()
+		enc.AddTime("created", v.Base.Created)
+	}
+	if v.Meta != nil {
+		enc.AddComma()
+		enc.AddString("Source", v.Meta.Source)
+	}
+	if v.Meta != nil && len(v.Meta.Trace) != 0 {
+		enc.AddComma()
+		enc.AddAny("trace", v.Meta.Trace)
+	}
+	enc.AddComma()
+	enc.AddString("name", v.Name)
+	enc.AddComma()
+	enc.AddString("password", "******")
+	enc.AddComma()
+	enc.AddUint64("age", uint64(v.Age))
+	enc.AddComma()
+	enc.AddFloat32("score", v.Score)
+	if v.Ratio != 0 {
+		enc.AddComma()
+		enc.AddFloat64("ratio", v.Ratio)
+	}
+	enc.AddComma()
+	enc.AddBool("active", v.Active)
+	enc.AddComma()
+	enc.AddString("count", strconv.FormatInt(int64(v.Count), 10))
+	enc.AddComma()
+	enc.AddAny("status", v.Status)
+	enc.AddComma()
+	enc.AddString("code", strconv.FormatInt(int64(v.Code), 10))
+	enc.AddComma()
+	enc.AddDuration("timeout", v.Timeout)
+	if len(v.Tags) != 0 {
+		enc.AddComma()
+		enc.AddAny("tags", v.Tags)
+	}
+	enc.AddComma()
+	enc.AddAny("labels", v.Labels)
+	enc.AddComma()
+	enc.AppendStringKey("note")
+	enc.AddColon()
+	if err := v.Note.MarshalSlogObject(enc); err != nil {
+		return err
+	}
+	enc.AddComma()
+	enc.AppendStringKey("items")
+	enc.AddColon()
+	if v.Items == nil {
+		enc.AppendValue(nil)
+	} else {
+		keep, more := enc.SliceLimit(len(v.Items))
+		enc.BeginArray()
+		for i := range v.Items[:keep] {
+			if i > 0 {
+				enc.AddComma()
+			}
+			if err := v.Items[i].MarshalSlogObject(enc); err != nil {
+				return err
+			}
+		}
+		enc.AddMoreItems(keep, more)
+		enc.EndArray(false)
+	}
+	if len(v.Refs) != 0 {
+		enc.AddComma()
+		enc.AddAny("refs", v.Refs)
+	}
+	if v.Next != nil {
+		enc.AddComma()
+		enc.AddAny("next", v.Next)
+	}
+	enc.AddComma()
+	enc.AddAny("extra", v.Extra)
+	if v.Err != nil {
+		enc.AddComma()
+		enc.AddAny("err", v.Err)
+	}
+	enc.End(false)
+	return nil
+}
+
+// MarshalSlogObject encodes Item without reflection.
+func (v *Item) MarshalSlogObject(enc *slog.PrintCtx) error {
+	if v == nil {
+		enc.AppendValue(nil)
+		return nil
+	}
+	if !enc.EnterValue(v) {
+		return nil
+	}
+	defer enc.LeaveValue()
+
+	enc.Begin()
+	enc.AddString("sku", v.SKU)
+	enc.AddComma()
+	enc.AddFloat64("price", v.Price)
+	if v.Owner != nil {
+		enc.AddComma()
+		enc.AddAny("owner", v.Owner)
+	}
+	enc.End(false)
+	return nil
+}
+
+// MarshalSlogObject encodes Note without reflection.
+func (v *Note) MarshalSlogObject(enc *slog.PrintCtx) error {
+	if v == nil {
+		enc.AppendValue(nil)
+		return nil
+	}
+	if !enc.EnterValue(v) {
+		return nil
+	}
+	defer enc.LeaveValue()
+
+	enc.Begin()
+	comma := false
+	if v.Text != "" {
+		enc.AddString("Text", v.Text)
+		comma = true
+	}
+	if v.Lang != "" {
+		if comma {
+			enc.AddComma()
+		}
+		enc.AddString("Lang", v.Lang)
+		comma = true
+	}
+	if comma {
+		enc.AddComma()
+	}
+	enc.AddInt64("Level", int64(v.Level))
+	enc.End(false)
+	return nil
+}
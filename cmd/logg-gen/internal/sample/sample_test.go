@@ -0,0 +1,95 @@
+package sample
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/hedzr/logg/slog"
+)
+
+// the types without the generated methods, they are encoded by
+// reflection.
+type (
+	reflectUser User
+	reflectItem Item
+	reflectNote Note
+)
+
+func sampleUser() *User {
+	u := &User{
+		Base:     Base{ID: 42, Created: time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)},
+		Meta:     &Meta{Source: "api", Trace: []byte{1, 2}},
+		Name:     "alice \"a\"",
+		Password: "hunter2",
+		Age:      30,
+		Score:    0.1,
+		Active:   true,
+		Count:    -7,
+		Status:   1,
+		Code:     2,
+		Timeout:  1500 * time.Millisecond,
+		Labels:   map[string]int{"z": 1, "a": 2},
+		Note:     Note{Lang: "en", Level: 3},
+		Items:    []Item{{SKU: "x-1", Price: 9.5}, {SKU: "x-2"}},
+		Extra:    []any{1, "two", nil},
+		Err:      ErrSample,
+	}
+	u.Next = &User{Name: "bob"}
+	u.Next.Next = u.Next // a cycle
+	u.Refs = []*Item{&u.Items[0], nil, {SKU: "y", Owner: u.Next}}
+	return u
+}
+
+func TestSameOutput(t *testing.T) {
+	modes := []slog.Mode{slog.ModeJSON, slog.ModeLogFmt, slog.ModePlain, slog.ModeColorful, slog.ModeCBOR, slog.ModeMsgPack}
+	for _, c := range []struct {
+		name     string
+		gen, ref any
+	}{
+		{"user", sampleUser(), (*reflectUser)(sampleUser())},
+		{"empty", &User{}, &reflectUser{}},
+		{"nil", (*User)(nil), (*reflectUser)(nil)},
+		{"item", &Item{SKU: "s", Owner: &User{}}, &reflectItem{SKU: "s", Owner: &User{}}},
+		{"note", &Note{}, &reflectNote{}},
+		{"note-text", &Note{Text: "t"}, &reflectNote{Text: "t"}},
+	} {
+		for _, mode := range modes {
+			for _, limits := range []slog.Limits{{}, {MaxSliceLen: 1}} {
+				gen, ref := encode(mode, limits, c.gen), encode(mode, limits, c.ref)
+				if !bytes.Equal(gen, ref) {
+					t.Fatalf("%s in %v with %+v: expect the same output\n  generated:  %q\n  reflection: %q", c.name, mode, limits, gen, ref)
+				}
+				if mode == slog.ModeJSON {
+					t.Logf("%s: %s", c.name, gen)
+				}
+			}
+		}
+	}
+}
+
+func encode(mode slog.Mode, limits slog.Limits, val any) []byte {
+	pc := slog.NewPrintCtx(slog.WithPCMode(mode), slog.WithPCLimits(limits))
+	pc.AddAny("v", val)
+	return pc.Bytes()
+}
+
+func BenchmarkGenerated(b *testing.B) {
+	u := sampleUser()
+	pc := slog.NewPrintCtx(slog.WithPCMode(slog.ModeJSON))
+	b.ReportAllocs()
+	for b.Loop() {
+		pc.Reset()
+		pc.AddAny("v", u)
+	}
+}
+
+func BenchmarkReflection(b *testing.B) {
+	u := (*reflectUser)(sampleUser())
+	pc := slog.NewPrintCtx(slog.WithPCMode(slog.ModeJSON))
+	b.ReportAllocs()
+	for b.Loop() {
+		pc.Reset()
+		pc.AddAny("v", u)
+	}
+}
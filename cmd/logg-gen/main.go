@@ -0,0 +1,68 @@
+// Command logg-gen generates the MarshalSlogObject methods of struct
+// types, so that they are logged by logg/slog without reflection.
+//
+// Add a go:generate directive to the package of the types:
+//
+//	//go:generate go run github.com/hedzr/logg/cmd/logg-gen -type User,Item
+//
+// and run "go generate". The methods are written to user_logg.go by
+// default (the first type name in lower case, with the suffix
+// "_logg.go"), see the -output flag.
+//
+// The generated methods print the same output as the reflection
+// encoder of logg/slog, and honor the same struct tags:
+//
+//	`logg:"name,omitempty,redact,string"`
+//
+// or the json tag if there is no logg tag. The embedded structs
+// without tag name are inlined.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	typeNames = flag.String("type", "", "comma-separated list of the struct type names, required")
+	output    = flag.String("output", "", "output file name, default is <type>_logg.go")
+)
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage of logg-gen:\n")
+	fmt.Fprintf(os.Stderr, "\tlogg-gen -type T,U [-output file] [directory]\n")
+	flag.PrintDefaults()
+}
+
+func main() {
+	flag.Usage = usage
+	flag.Parse()
+	if *typeNames == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	types := strings.Split(*typeNames, ",")
+
+	dir := "."
+	if args := flag.Args(); len(args) > 0 {
+		dir = args[0]
+	}
+	outName := *output
+	if outName == "" {
+		outName = strings.ToLower(types[0]) + "_logg.go"
+	}
+	outName = filepath.Join(dir, outName)
+
+	src, err := generate(dir, filepath.Base(outName), types)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "logg-gen: %v\n", err)
+		os.Exit(1)
+	}
+	if err = os.WriteFile(outName, src, 0o644); err != nil {
+		fmt.Fprintf(os.Stderr, "logg-gen: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -2,8 +2,6 @@ package slog
 
 import (
 	"fmt"
-	"slices"
-	"time"
 
 	"github.com/hedzr/logg/slog/internal/strings"
 )
@@ -23,14 +21,9 @@ func (s *kvp) Key() string    { return s.key }
 func (s *kvp) Value() any     { return s.val }
 func (s *kvp) SetValue(v any) { s.val = v }
 
-func (s *kvp) SerializeValueTo(pc *PrintCtx) {
-	pc.AppendStringKey(s.key)
-	pc.AppendByte('=')
-
-	pc.prefix = ""
-	pc.inGroupedMode = false
-	pc.appendValue(s.val)
-}
+// SerializeValueTo writes an attribute used as a value, such as
+// logger.Info("msg", "a", slog.NewAttr("b", 1)), as a group of it.
+func (s *kvp) SerializeValueTo(pc *PrintCtx) { Attrs{s}.SerializeValueTo(pc) }
 
 type Attrs []Attr // slice of Attr
 
@@ -66,73 +59,19 @@ func (s *gkvp) Add(as ...Attr) {
 // 	return s
 // }
 
-func (s *gkvp) SerializeValueTo(pc *PrintCtx) {
-	switch pc.mode1 {
-	case ModeJSON:
-		pc.AppendStringKey(s.key)
-		pc.AppendByte(':')
-	case ModeLogFmt:
-		if pc.Colorful() {
-			pc.AppendStringKey(s.key)
-			pc.AppendByte(':')
-		} else {
-			ct.wrapDimColorTo(pc, s.key)
-			pc.AppendByte(':')
-			ct.echoColorAndBg(pc, pc.clr, pc.bg)
-		}
-	}
-
-	// if pc.jsonMode {
-	// 	if pc.noColor {
-	// 		pc.pcAppendStringKey(s.key)
-	// 		pc.pcAppendByte(':')
-	// 	} else {
-	// 		ct.wrapDimColorTo(pc, s.key)
-	// 		pc.pcAppendByte(':')
-	// 		ct.echoColorAndBg(pc, pc.clr, pc.bg)
-	// 	}
-	// }
-
-	// if sb.jsonMode {
-	// 	sb.appendRune('{')
-	// }
-	// for ix, attr := range s.items {
-	// 	if ix > 0 {
-	// 		sb.appendRune(',')
-	// 	}
-	// 	sb.appendStringKey(attr.Key())
-	// 	sb.appendRune('=')
-	// 	sb.appendValue(attr.Value())
-	// }
-	// if sb.jsonMode {
-	// 	sb.appendRune('}')
-	// }
-	_ = serializeAttrs(pc, s.items)
-}
+// SerializeValueTo writes a group used as a value, such as
+// logger.Info("msg", "a", slog.Group("b", "c", 1)), as a group of it.
+func (s *gkvp) SerializeValueTo(pc *PrintCtx) { Attrs{s}.SerializeValueTo(pc) }
 
 func (s Attrs) SerializeValueTo(pc *PrintCtx) {
-	_ = serializeAttrs(pc, s)
-}
-
-func dedupeSlice[S ~[]E, E any](x S, cmp func(a, b E) bool) S {
-	if len(x) == 0 {
-		return nil
+	if pc.mode1 == ModeJSON {
+		// a group is a nested object in JSON
+		pc.Begin()
+		_ = serializeAttrs(pc, s)
+		pc.End(false)
+		return
 	}
-
-	j := 0
-	for i := 1; i < len(x); i++ {
-		if cmp(x[i], x[j]) {
-			x[j] = x[i]
-			continue
-		}
-		j++
-		// preserve the original data
-		// in[i], in[j] = in[j], in[i]
-		// only set what is required
-		x[j] = x[i]
-	}
-	result := x[:j+1]
-	return result
+	_ = serializeAttrs(pc, s)
 }
 
 // serializeAttrs returns an error object if it's found in the given Attrs.
@@ -140,41 +79,10 @@ func dedupeSlice[S ~[]E, E any](x S, cmp func(a, b E) bool) S {
 // The caller can do something with the object, For instance, printImpl
 // will dump the error's stack trace if necessary.
 func serializeAttrs(pc *PrintCtx, kvps Attrs) (err error) {
-	prefixSave := pc.prefix
-	inGroupedMode := pc.inGroupedMode
-
-	if pc.dedupeAttrs {
-		slices.SortFunc(kvps, func(a, b Attr) int {
-			if a == nil {
-				if b == nil {
-					return 0
-				}
-				return -1
-			}
-			if b == nil {
-				return 1
-			}
+	pc.attrDepth++
+	defer func() { pc.attrDepth-- }()
 
-			k1, k2 := a.Key(), b.Key()
-			if k1 < k2 {
-				return -1
-			}
-			if k1 == k2 {
-				return 0
-			}
-			return 1
-		})
-
-		kvps = dedupeSlice(kvps, func(a, b Attr) bool {
-			if a == nil || b == nil {
-				if b == a {
-					return true
-				}
-				return false
-			}
-			return a.Key() == b.Key()
-		})
-	}
+	dd := newAttrDeduper(pc.dedupe, kvps)
 
 	// DONE: extract value from context by key
 	//     ctx.Value(key)
@@ -184,71 +92,132 @@ func serializeAttrs(pc *PrintCtx, kvps Attrs) (err error) {
 	// TODO: extract from grpc context
 	// TODO: extract from openTraceID
 
-	for _, v := range kvps {
+	written := 0
+	for i, v := range kvps {
 		if v == nil {
 			continue
 		}
+		if pc.lineFull {
+			break
+		}
 
-		if pc.IsColorfulStyle() {
-			pc.pcAppendByte(' ')
-			if pc.Colorful() {
-				ct.echoColorAndBg(pc, pc.clr, pc.bg)
-			}
+		key, a, ok := dd.next(i)
+		if !ok {
+			continue // a duplicate
+		}
+
+		// the attributes holding a Value are written without boxing
+		var val Value
+		if tv, typed := a.(valueAttr); typed && pc.valueStringer == nil {
+			val = tv.TypedValue()
 		} else {
-			pc.pcAppendComma()
+			val = Value{any: a.Value()}
 		}
 
-		if !inGroupedMode {
-			_, inGroupedMode = v.(groupedValue)
+		more := pc.attrsOver(i, len(kvps), written)
+		if more > 0 {
+			// the rest attributes are replaced by a marker
+			key, val = "…", Value{any: limitMarker(moreMarker(more, "attrs"))}
+		} else if g, ok := val.group(); ok && pc.groupTooDeep() {
+			val = Value{any: limitMarker(moreMarker(len(g), "attrs"))}
 		}
+		written++
 
-		key := v.Key()
-		if inGroupedMode && pc.mode1 != ModeJSON && pc.valueStringer == nil {
-			key = strings.DotPrefix(key, prefixSave)
-		} else {
-			if inGroupedMode && pc.mode1 != ModeJSON && pc.valueStringer == nil {
-				panic("impossible condition matched: inGroupedMode && !pc.jsonMode")
-				// if inGroupedMode && !pc.jsonMode {
-				// 	key = DotPrefix(key, prefix)
-				// }
-			}
-			if pc.mode1 != ModeJSON {
-				key = strings.DotPrefix(key, prefixSave)
-			}
-			pc.AppendKey(key)
+		if e := pc.appendAttr(key, val); e != nil {
+			err = e // just a error value
 		}
+		if more > 0 {
+			break
+		}
+	}
 
-		if key == timestampFieldName {
-			// we format timestamp in according to the setting in flags
-			if z, ok := v.Value().(time.Time); ok {
-				// if pc.jsonMode || pc.noColor {
-				// 	pc.WriteRune('"')
-				// 	pc.WriteString(z.Format(time.RFC3339Nano))
-				// 	pc.WriteRune('"')
-				// } else {
-				// 	pc.appendTimestamp(z)
-				// }
-				pc.AppendTimestamp(z)
-				continue
-			}
+	if pc.attrDepth == 1 && pc.event != nil {
+		if e := pc.event.spliceTo(pc); e != nil {
+			err = e
 		}
+	}
 
-		pc.prefix = key
-		val := v.Value()
-		if pc.valueStringer != nil { // && IsAnyBitsSet(Lprettyprint) {
-			pc.valueStringer.WriteValue(val)
-		} else {
-			pc.appendValue(val)
-			if e, ok := val.(error); ok && e != nil {
-				err = e // just a error value
+	if pc.IsColorfulStyle() {
+		ct.echoResetColor(pc)
+	}
+	return
+}
+
+// appendAttr writes an attribute in the current mode, with the leading
+// separator. It returns the error value, or the last error value of a
+// group.
+func (pc *PrintCtx) appendAttr(key string, val Value) (err error) {
+	prefixSave := pc.prefix
+	if pc.mode1 != ModeJSON {
+		key = strings.DotPrefix(key, prefixSave)
+
+		// a group is flattened to the dotted keys, such as
+		// "group.key=value", except in JSON mode, in which it is
+		// a nested object.
+		if g, ok := val.group(); ok && pc.valueStringer == nil {
+			pc.prefix = key
+			err = serializeAttrs(pc, g)
+			pc.prefix = prefixSave
+			return
+		}
+
+		// so are the structs, maps and slices encoded by reflection,
+		// such as "key.field=value" and "key.0=value".
+		if v, ref, ok := flatValue(val); ok && pc.valueStringer == nil && pc.enterFlat(ref) {
+			pc.prefix = key
+			written := pc.appendFlat(v)
+			pc.prefix = prefixSave
+			pc.LeaveValue()
+			if written {
+				return
 			}
 		}
-		pc.prefix = prefixSave
 	}
 
 	if pc.IsColorfulStyle() {
-		ct.echoResetColor(pc)
+		pc.pcAppendByte(' ')
+		if pc.Colorful() {
+			ct.echoColorAndBg(pc, pc.clr, pc.bg)
+		}
+	} else if pc.mode1 != ModeJSON || !pc.atObjectBegin() {
+		pc.pcAppendComma()
+	}
+	pc.AppendKey(key)
+
+	if key == pc.FieldNames().time() {
+		// we format timestamp in according to the setting in flags
+		if z, ok := val.timeOf(); ok {
+			// if pc.jsonMode || pc.noColor {
+			// 	pc.WriteRune('"')
+			// 	pc.WriteString(z.Format(time.RFC3339Nano))
+			// 	pc.WriteRune('"')
+			// } else {
+			// 	pc.appendTimestamp(z)
+			// }
+			pc.AppendTimestamp(z)
+			return
+		}
+	}
+
+	pc.prefix = key
+	if pc.valueStringer != nil { // && IsAnyBitsSet(Lprettyprint) {
+		pc.valueStringer.WriteValue(val.Any())
+	} else {
+		start := len(pc.buf)
+		pc.valueStyled = pc.IsColorfulStyle() && pc.echoValueStyle(key, val)
+		pc.appendTypedValue(val)
+		if pc.valueStyled {
+			ct.echoResetColor(pc)
+			pc.valueStyled = false
+		}
+		if pc.mode1 == ModeLogFmt {
+			pc.ensureLogfmtValue(start)
+		}
+		if e, ok := val.any.(error); ok && val.typ == TypeAny && e != nil {
+			err = e // just a error value
+		}
 	}
+	pc.prefix = prefixSave
 	return
 }
 
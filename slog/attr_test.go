@@ -1,6 +1,8 @@
 package slog
 
 import (
+	"bytes"
+	"encoding/json"
 	"testing"
 	"time"
 )
@@ -84,3 +86,22 @@ func TestGkvp_SetValue(t *testing.T) {
 	g.SerializeValueTo(pc)
 	t.Logf("%v", pc.String())
 }
+
+func TestJSONGroupAttrs(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(WithWriter(&buf), WithJSONMode(), WithLevel(InfoLevel))
+	l.Info("m", "a", 1, Group("g", "x", 1, Group("h", "z", 2)), Group("empty"))
+
+	var m map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &m); err != nil {
+		t.Fatalf("bad json %q: %v", buf.String(), err)
+	}
+	g, _ := m["g"].(map[string]any)
+	h, _ := g["h"].(map[string]any)
+	if g["x"] != float64(1) || h["z"] != float64(2) {
+		t.Fatalf("expect the groups nested, but got %q", buf.String())
+	}
+	if e, ok := m["empty"].(map[string]any); !ok || len(e) != 0 {
+		t.Fatalf("expect an empty object for empty group, but got %q", buf.String())
+	}
+}
@@ -0,0 +1,285 @@
+package slog
+
+import (
+	"encoding"
+	"time"
+
+	"github.com/hedzr/is/term/color"
+)
+
+// binaryEncoder is implemented by the painters of binary formats,
+// such as ModeCBOR and ModeMsgPack. If the painter of a PrintCtx
+// is a binaryEncoder, the values are encoded by it instead of
+// being formatted as text, so [ObjectMarshaller] and
+// [ArrayMarshaller] work with the binary formats transparently.
+type binaryEncoder interface {
+	appendNil(pc *PrintCtx)
+	appendBool(pc *PrintCtx, v bool)
+	appendInt(pc *PrintCtx, v int64)
+	appendUint(pc *PrintCtx, v uint64)
+	appendFloat(pc *PrintCtx, v float64, bitSize int)
+	appendString(pc *PrintCtx, v string)
+	appendBytes(pc *PrintCtx, v []byte)
+}
+
+// binFormat encodes the data items of a binary format.
+type binFormat interface {
+	appendNil(b []byte) []byte
+	appendBool(b []byte, v bool) []byte
+	appendInt(b []byte, v int64) []byte
+	appendUint(b []byte, v uint64) []byte
+	appendFloat(b []byte, v float64, bitSize int) []byte
+	appendString(b []byte, v string) []byte
+	appendBytes(b []byte, v []byte) []byte
+	appendTime(b []byte, v time.Time) []byte
+	// appendHeader appends the header of a map (n pairs) or an
+	// array (n elements), which is 5 bytes at most.
+	appendHeader(b []byte, array bool, n int) []byte
+}
+
+// binHeaderLen is the space reserved for the header of a map or an
+// array, since the number of items is unknown until it is ended.
+const binHeaderLen = 5
+
+// binFrame is an open map or array.
+type binFrame struct {
+	off   int  // the offset of the reserved header
+	n     int  // the number of keys and values written
+	array bool //
+}
+
+// binaryPainter writes the same logical structure as jsonPainter,
+// in a binary format.
+type binaryPainter struct {
+	f binFormat
+}
+
+var _ Painter = (*binaryPainter)(nil)
+var _ LinePainter = (*binaryPainter)(nil)
+var _ binaryEncoder = (*binaryPainter)(nil)
+
+// PaintLine implements [LinePainter].
+func (s *binaryPainter) PaintLine(pc *PrintCtx) (holdErrorValue error) {
+	pc.mode1 = ModeJSON // the groups are nested maps
+	pc.valueStringer = nil
+
+	var msgDone bool
+	for _, f := range pc.FieldNames().order() {
+		switch f {
+		case TimeField:
+			s.AddTimestampField(pc, pc.now)
+		case LoggerField:
+			if pc.name != "" {
+				s.AddLoggerNameField(pc, pc.name)
+			}
+		case LevelField:
+			s.AddSeverity(pc, pc.lvl)
+		case MessageField:
+			if !msgDone {
+				s.AddMsgField(pc, pc.msg)
+				holdErrorValue = serializeAttrs(pc, pc.kvps)
+				msgDone = true
+			}
+		case CallerField:
+			if src := pc.Source(); src != nil && IsAnyBitsSet(Lcaller) {
+				s.AddPCField(pc, src)
+			}
+		}
+	}
+	if !msgDone {
+		s.AddMsgField(pc, pc.msg)
+		holdErrorValue = serializeAttrs(pc, pc.kvps)
+	}
+	return
+}
+
+// item counts a key or a value in the current map or array.
+func (s *binaryPainter) item(pc *PrintCtx) {
+	if n := len(pc.binFrames); n > 0 {
+		pc.binFrames[n-1].n++
+	}
+}
+
+func (s *binaryPainter) begin(pc *PrintCtx, array bool) {
+	s.item(pc)
+	pc.binFrames = append(pc.binFrames, binFrame{off: len(pc.buf), array: array})
+	pc.buf = append(pc.buf, make([]byte, binHeaderLen)...)
+}
+
+// end writes the header of the current map or array, the contents
+// are moved forward if the header is shorter than reserved.
+func (s *binaryPainter) end(pc *PrintCtx, array bool) {
+	n := len(pc.binFrames)
+	if n == 0 || pc.binFrames[n-1].array != array {
+		return // unbalanced
+	}
+	f := pc.binFrames[n-1]
+	pc.binFrames = pc.binFrames[:n-1]
+
+	count := f.n
+	if !array {
+		if count%2 != 0 { // a key without value
+			pc.buf = s.f.appendNil(pc.buf)
+			count++
+		}
+		count /= 2
+	}
+
+	var hdr [binHeaderLen]byte
+	h := s.f.appendHeader(hdr[:0], array, count)
+	body := f.off + binHeaderLen
+	copy(pc.buf[f.off+len(h):], pc.buf[body:])
+	copy(pc.buf[f.off:], h)
+	pc.buf = pc.buf[:len(pc.buf)-binHeaderLen+len(h)]
+}
+
+func (s *binaryPainter) appendNil(pc *PrintCtx) {
+	s.item(pc)
+	pc.buf = s.f.appendNil(pc.buf)
+}
+
+func (s *binaryPainter) appendBool(pc *PrintCtx, v bool) {
+	s.item(pc)
+	pc.buf = s.f.appendBool(pc.buf, v)
+}
+
+func (s *binaryPainter) appendInt(pc *PrintCtx, v int64) {
+	s.item(pc)
+	pc.buf = s.f.appendInt(pc.buf, v)
+}
+
+func (s *binaryPainter) appendUint(pc *PrintCtx, v uint64) {
+	s.item(pc)
+	pc.buf = s.f.appendUint(pc.buf, v)
+}
+
+func (s *binaryPainter) appendFloat(pc *PrintCtx, v float64, bitSize int) {
+	s.item(pc)
+	pc.buf = s.f.appendFloat(pc.buf, v, bitSize)
+}
+
+func (s *binaryPainter) appendString(pc *PrintCtx, v string) {
+	s.item(pc)
+	pc.buf = s.f.appendString(pc.buf, v)
+}
+
+func (s *binaryPainter) appendBytes(pc *PrintCtx, v []byte) {
+	s.item(pc)
+	pc.buf = s.f.appendBytes(pc.buf, v)
+}
+
+func (s *binaryPainter) appendTime(pc *PrintCtx, v time.Time) {
+	s.item(pc)
+	pc.buf = s.f.appendTime(pc.buf, v)
+}
+
+func (s *binaryPainter) Colorful() bool { return false }
+
+func (s *binaryPainter) Begin(pc *PrintCtx) { s.begin(pc, false) }
+
+// End ends a map. No newline is written since the data items of
+// binary formats are self-delimiting.
+func (s *binaryPainter) End(pc *PrintCtx, newline bool) { s.end(pc, false) }
+
+func (s *binaryPainter) BeginArray(pc *PrintCtx) { s.begin(pc, true) }
+
+func (s *binaryPainter) EndArray(pc *PrintCtx, newline bool) { s.end(pc, true) }
+
+func (s *binaryPainter) AppendColon(pc *PrintCtx) {}
+func (s *binaryPainter) AppendComma(pc *PrintCtx) {}
+
+func (s *binaryPainter) AppendTime(pc *PrintCtx, z time.Time) { s.appendTime(pc, z) }
+
+func (s *binaryPainter) AppendTimeSlice(pc *PrintCtx, z []time.Time) {
+	s.BeginArray(pc)
+	for _, t := range z {
+		s.appendTime(pc, t)
+	}
+	s.EndArray(pc, false)
+}
+
+// AppendTimestamp writes tm as a time value, the layout is ignored.
+func (s *binaryPainter) AppendTimestamp(pc *PrintCtx, tm time.Time, layout string) {
+	s.appendTime(pc, tm)
+}
+
+func (s *binaryPainter) AppendDuration(pc *PrintCtx, z time.Duration) {
+	s.appendString(pc, z.String())
+}
+
+func (s *binaryPainter) AppendDurationSlice(pc *PrintCtx, z []time.Duration) {
+	s.BeginArray(pc)
+	for _, d := range z {
+		s.AppendDuration(pc, d)
+	}
+	s.EndArray(pc, false)
+}
+
+func (s *binaryPainter) Append(pc *PrintCtx, data []byte) { s.appendBytes(pc, data) }
+
+func (s *binaryPainter) AppendStringKeyPrefixed(pc *PrintCtx, str, prefix string) {
+	s.appendString(pc, prefix+"."+str)
+}
+
+func (s *binaryPainter) AppendStringKey(pc *PrintCtx, str string) { s.appendString(pc, str) }
+
+func (s *binaryPainter) AppendKey(pc *PrintCtx, key string, clr, bg color.Color) {
+	s.appendString(pc, key)
+}
+
+func (s *binaryPainter) AppendError(pc *PrintCtx, err error) {
+	theJSONPainter.AppendError(pc, err) // it works via pc
+}
+
+func (s *binaryPainter) AppendErrorAfterPrinted(pc *PrintCtx, err error) {}
+
+func (s *binaryPainter) AddTimestampField(pc *PrintCtx, tm time.Time) {
+	s.appendString(pc, pc.FieldNames().time())
+	tm, _ = pc.timestamp(tm)
+	s.appendTime(pc, tm)
+}
+
+func (s *binaryPainter) AddLoggerNameField(pc *PrintCtx, name string) {
+	pc.AddString(pc.FieldNames().logger(), name)
+}
+
+func (s *binaryPainter) AddSeverity(pc *PrintCtx, lvl Level) {
+	pc.AddString(pc.FieldNames().level(), lvl.String())
+}
+
+func (s *binaryPainter) AddPCField(pc *PrintCtx, source *Source) {
+	s.appendString(pc, pc.FieldNames().caller())
+	s.Begin(pc)
+	pc.AddString("file", source.File)
+	pc.AddInt("line", source.Line)
+	pc.AddString("function", source.Function)
+	s.End(pc, false)
+}
+
+func (s *binaryPainter) AddMsgField(pc *PrintCtx, msg string) {
+	pc.AddString(pc.FieldNames().message(), msg)
+}
+
+func (s *binaryPainter) AddMsgFieldFirstLine(pc *PrintCtx, firstLine string)           {}
+func (s *binaryPainter) AddMsgFieldRestLines(pc *PrintCtx, restLines string, eol bool) {}
+
+func (s *binaryPainter) AddPrefixedString(pc *PrintCtx, prefix, name string, value string) {
+	s.AppendStringKeyPrefixed(pc, name, prefix)
+	s.appendString(pc, value)
+}
+
+func (s *binaryPainter) TryQuoteValue(pc *PrintCtx, val string) { s.appendString(pc, val) }
+
+func (s *binaryPainter) MarshalValue(pc *PrintCtx, val any) (handled bool, err error) {
+	if m, ok := val.(encoding.TextMarshaler); ok {
+		var data []byte
+		data, err = m.MarshalText()
+		if err != nil {
+			hintInternal(err, "MarshalText failed")
+			return
+		}
+		s.appendString(pc, string(data))
+		handled = true
+	}
+	return
+}
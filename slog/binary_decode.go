@@ -0,0 +1,539 @@
+package slog
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"strconv"
+	"time"
+)
+
+// CBORToJSON reads the CBOR data items from r, such as the logging
+// lines printed by ModeCBOR, and writes them to w as JSON, one line
+// per item.
+//
+// The epoch-based (tag 1) and the string (tag 0) date/times are
+// written as RFC 3339 strings, the byte strings as base64 strings,
+// the other tags are ignored.
+func CBORToJSON(w io.Writer, r io.Reader) error {
+	d := &cborDecoder{binDecoder: newBinDecoder(r)}
+	return d.toJSON(w, d.item)
+}
+
+// MsgPackToJSON reads the MessagePack objects from r, such as the
+// logging lines printed by ModeMsgPack, and writes them to w as
+// JSON, one line per object.
+//
+// The timestamps (extension type -1) are written as RFC 3339
+// strings, the binaries and the other extension types as base64
+// strings.
+func MsgPackToJSON(w io.Writer, r io.Reader) error {
+	d := &msgpackDecoder{binDecoder: newBinDecoder(r)}
+	return d.toJSON(w, d.item)
+}
+
+var errMalformedBinary = errors.New("slog: malformed binary data")
+
+// binMaxDepth limits the nesting of maps and arrays.
+const binMaxDepth = 256
+
+// binDecoder reads a binary format and writes JSON to out.
+type binDecoder struct {
+	r     *bufio.Reader
+	out   *PrintCtx
+	tmp   bytes.Buffer
+	depth int
+}
+
+func newBinDecoder(r io.Reader) binDecoder {
+	return binDecoder{r: bufio.NewReader(r), out: NewPrintCtxBytes(nil)}
+}
+
+func (s *binDecoder) toJSON(w io.Writer, item func() error) error {
+	for {
+		if _, err := s.r.Peek(1); err == io.EOF {
+			return nil
+		}
+		s.out.Reset()
+		if err := item(); err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return err
+		}
+		s.out.AppendByte('\n')
+		if _, err := w.Write(s.out.Bytes()); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *binDecoder) enter() error {
+	if s.depth++; s.depth > binMaxDepth {
+		return fmt.Errorf("%w: nested too deep", errMalformedBinary)
+	}
+	return nil
+}
+
+func (s *binDecoder) leave() { s.depth-- }
+
+func (s *binDecoder) readUint(size int) (uint64, error) {
+	var b [8]byte
+	if _, err := io.ReadFull(s.r, b[8-size:]); err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint64(b[:]), nil
+}
+
+// read reads n bytes, the buffer grows as the data arrives, so a
+// bad length cannot allocate too much memory.
+func (s *binDecoder) read(n uint64) ([]byte, error) {
+	s.tmp.Reset()
+	if n > math.MaxInt64 {
+		return nil, errMalformedBinary
+	}
+	if _, err := io.CopyN(&s.tmp, s.r, int64(n)); err != nil {
+		return nil, err
+	}
+	return s.tmp.Bytes(), nil
+}
+
+func (s *binDecoder) writeString(str []byte) {
+	s.out.AppendByte('"')
+	s.out.appendEscapedJSONString(string(str))
+	s.out.AppendByte('"')
+}
+
+func (s *binDecoder) writeBase64(data []byte) {
+	s.out.AppendByte('"')
+	s.out.Buf(func(buf []byte) []byte { return base64.StdEncoding.AppendEncode(buf, data) })
+	s.out.AppendByte('"')
+}
+
+// writeFloat writes NaN and infinities as strings, which are not
+// allowed by JSON.
+func (s *binDecoder) writeFloat(v float64, bitSize int) {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		s.out.AppendQuotedString(strconv.FormatFloat(v, 'g', -1, bitSize))
+		return
+	}
+	s.out.Buf(func(buf []byte) []byte { return strconv.AppendFloat(buf, v, 'g', -1, bitSize) })
+}
+
+func (s *binDecoder) writeTime(t time.Time) {
+	s.out.AppendByte('"')
+	s.out.Buf(func(buf []byte) []byte { return t.UTC().AppendFormat(buf, time.RFC3339Nano) })
+	s.out.AppendByte('"')
+}
+
+// writeKey writes a map key, which is quoted if it is not a string.
+func (s *binDecoder) writeKey(item func() error) error {
+	start := len(s.out.buf)
+	if err := item(); err != nil {
+		return err
+	}
+	if key := s.out.buf[start:]; len(key) == 0 || key[0] != '"' {
+		str := string(key)
+		s.out.buf = s.out.buf[:start]
+		s.writeString([]byte(str))
+	}
+	s.out.AppendByte(':')
+	return nil
+}
+
+func (s *binDecoder) writeMap(n int, item func() error) (err error) {
+	if err = s.enter(); err != nil {
+		return
+	}
+	s.out.AppendByte('{')
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			s.out.AppendByte(',')
+		}
+		if err = s.writeKey(item); err != nil {
+			return
+		}
+		if err = item(); err != nil {
+			return
+		}
+	}
+	s.out.AppendByte('}')
+	s.leave()
+	return
+}
+
+func (s *binDecoder) writeArray(n int, item func() error) (err error) {
+	if err = s.enter(); err != nil {
+		return
+	}
+	s.out.AppendByte('[')
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			s.out.AppendByte(',')
+		}
+		if err = item(); err != nil {
+			return
+		}
+	}
+	s.out.AppendByte(']')
+	s.leave()
+	return
+}
+
+//
+//
+//
+
+type cborDecoder struct {
+	binDecoder
+}
+
+// errCBORBreak is returned by item when the "break" stop code of
+// an indefinite-length item is read.
+var errCBORBreak = errors.New("cbor: break")
+
+// head reads the initial byte and the argument of a data item.
+// indefinite is true if the additional information is 31.
+func (s *cborDecoder) head() (major, info byte, n uint64, indefinite bool, err error) {
+	var b byte
+	if b, err = s.r.ReadByte(); err != nil {
+		return
+	}
+	major, info = b&0xe0, b&0x1f
+	switch {
+	case info < 24:
+		n = uint64(info)
+	case info <= 27:
+		n, err = s.readUint(1 << (info - 24))
+	case info == 31:
+		indefinite = true
+	default:
+		err = fmt.Errorf("%w: bad additional information %d", errMalformedBinary, info)
+	}
+	return
+}
+
+func (s *cborDecoder) item() (err error) {
+	major, info, n, indefinite, err := s.head()
+	if err != nil {
+		return
+	}
+	if indefinite {
+		return s.indefinite(major)
+	}
+
+	switch major {
+	case cborUint:
+		s.out.Buf(func(buf []byte) []byte { return strconv.AppendUint(buf, n, 10) })
+	case cborNegInt:
+		if n <= math.MaxInt64 {
+			s.out.Buf(func(buf []byte) []byte { return strconv.AppendInt(buf, -1-int64(n), 10) })
+		} else {
+			v := new(big.Int).SetUint64(n)
+			s.out.AppendString(v.Not(v).String()) // -1-n
+		}
+	case cborBytes, cborText:
+		var data []byte
+		if data, err = s.read(n); err != nil {
+			return
+		}
+		if major == cborText {
+			s.writeString(data)
+		} else {
+			s.writeBase64(data)
+		}
+	case cborArray:
+		if n > math.MaxInt32 {
+			return errMalformedBinary
+		}
+		return s.writeArray(int(n), s.item)
+	case cborMap:
+		if n > math.MaxInt32 {
+			return errMalformedBinary
+		}
+		return s.writeMap(int(n), s.item)
+	case cborTag:
+		return s.tag(n)
+	default:
+		return s.simple(info, n)
+	}
+	return
+}
+
+func (s *cborDecoder) simple(info byte, n uint64) (err error) {
+	switch info {
+	case cborFalse & 0x1f:
+		s.out.AppendString("false")
+	case cborTrue & 0x1f:
+		s.out.AppendString("true")
+	case cborFloat16 & 0x1f:
+		s.writeFloat(float64(float16(uint16(n))), 32)
+	case cborFloat32 & 0x1f:
+		s.writeFloat(float64(math.Float32frombits(uint32(n))), 32)
+	case cborFloat64 & 0x1f:
+		s.writeFloat(math.Float64frombits(n), 64)
+	default: // null, undefined and the other simple values
+		s.out.AppendString("null")
+	}
+	return
+}
+
+// float16 converts an IEEE 754 half-precision float.
+func float16(h uint16) float32 {
+	sign, exp, frac := uint32(h>>15), uint32(h>>10)&0x1f, uint32(h&0x3ff)
+	switch exp {
+	case 0: // subnormal
+		v := float32(frac) / (1 << 24)
+		if sign != 0 {
+			v = -v
+		}
+		return v
+	case 0x1f: // infinity or NaN
+		return math.Float32frombits(sign<<31 | 0xff<<23 | frac<<13)
+	}
+	return math.Float32frombits(sign<<31 | (exp+127-15)<<23 | frac<<13)
+}
+
+func (s *cborDecoder) tag(tag uint64) (err error) {
+	if tag != cborTagEpoch {
+		return s.item() // tag 0 is a RFC 3339 string already
+	}
+
+	major, info, n, _, err := s.head()
+	if err != nil {
+		return
+	}
+	switch {
+	case major == cborUint && n <= math.MaxInt64:
+		s.writeTime(time.Unix(int64(n), 0))
+	case major == cborNegInt && n < math.MaxInt64:
+		s.writeTime(time.Unix(-1-int64(n), 0))
+	case major == cborSimple && info >= 25 && info <= 27:
+		var v float64
+		switch info {
+		case 25:
+			v = float64(float16(uint16(n)))
+		case 26:
+			v = float64(math.Float32frombits(uint32(n)))
+		default:
+			v = math.Float64frombits(n)
+		}
+		sec, frac := math.Modf(v)
+		s.writeTime(time.Unix(int64(sec), int64(math.Round(frac*1e9))))
+	default:
+		return fmt.Errorf("%w: bad epoch-based date/time", errMalformedBinary)
+	}
+	return
+}
+
+// indefinite decodes an indefinite-length item, which ends with
+// the "break" stop code.
+func (s *cborDecoder) indefinite(major byte) (err error) {
+	switch major {
+	case cborBytes, cborText:
+		var data []byte
+		for {
+			m, _, n, ind, e := s.head()
+			if e != nil {
+				return e
+			}
+			if m == cborSimple && ind {
+				break
+			}
+			if m != major || ind {
+				return fmt.Errorf("%w: bad chunk of indefinite-length string", errMalformedBinary)
+			}
+			chunk, e := s.read(n)
+			if e != nil {
+				return e
+			}
+			data = append(data, chunk...)
+		}
+		if major == cborText {
+			s.writeString(data)
+		} else {
+			s.writeBase64(data)
+		}
+		return
+
+	case cborArray, cborMap:
+		if err = s.enter(); err != nil {
+			return
+		}
+		open, closing := byte('['), byte(']')
+		if major == cborMap {
+			open, closing = '{', '}'
+		}
+		s.out.AppendByte(open)
+		for i := 0; ; i++ {
+			if i > 0 {
+				s.out.AppendByte(',')
+			}
+			if major == cborMap {
+				err = s.writeKey(s.itemOrBreak)
+			} else {
+				err = s.itemOrBreak()
+			}
+			if err == errCBORBreak {
+				if i > 0 {
+					s.out.buf = s.out.buf[:len(s.out.buf)-1] // the comma
+				}
+				break
+			}
+			if err != nil {
+				return
+			}
+			if major == cborMap {
+				if err = s.item(); err != nil {
+					return
+				}
+			}
+		}
+		s.out.AppendByte(closing)
+		s.leave()
+		return nil
+
+	case cborSimple:
+		return errCBORBreak
+	}
+	return fmt.Errorf("%w: bad indefinite-length item", errMalformedBinary)
+}
+
+func (s *cborDecoder) itemOrBreak() error {
+	if b, err := s.r.Peek(1); err == nil && b[0] == cborBreak {
+		_, _ = s.r.ReadByte()
+		return errCBORBreak
+	}
+	return s.item()
+}
+
+//
+//
+//
+
+type msgpackDecoder struct {
+	binDecoder
+}
+
+func (s *msgpackDecoder) item() (err error) {
+	b, err := s.r.ReadByte()
+	if err != nil {
+		return
+	}
+
+	switch {
+	case b <= 0x7f:
+		s.out.AppendInt(int(b))
+		return
+	case b >= 0xe0:
+		s.out.AppendInt(int(int8(b)))
+		return
+	case b&0xf0 == mpFixMap:
+		return s.writeMap(int(b&0x0f), s.item)
+	case b&0xf0 == mpFixArray:
+		return s.writeArray(int(b&0x0f), s.item)
+	case b&0xe0 == mpFixStr:
+		return s.str(uint64(b & 0x1f))
+	}
+
+	var n uint64
+	switch b {
+	case mpNil:
+		s.out.AppendString("null")
+	case mpFalse:
+		s.out.AppendString("false")
+	case mpTrue:
+		s.out.AppendString("true")
+	case mpBin8, mpBin16, mpBin32:
+		if n, err = s.readUint(1 << (b - mpBin8)); err == nil {
+			var data []byte
+			if data, err = s.read(n); err == nil {
+				s.writeBase64(data)
+			}
+		}
+	case mpExt8, mpExt16, mpExt32:
+		if n, err = s.readUint(1 << (b - mpExt8)); err == nil {
+			err = s.ext(n)
+		}
+	case mpFixExt1, mpFixExt2, mpFixExt4, mpFixExt8, mpFixExt16:
+		err = s.ext(1 << (b - mpFixExt1))
+	case mpFloat32:
+		if n, err = s.readUint(4); err == nil {
+			s.writeFloat(float64(math.Float32frombits(uint32(n))), 32)
+		}
+	case mpFloat64:
+		if n, err = s.readUint(8); err == nil {
+			s.writeFloat(math.Float64frombits(n), 64)
+		}
+	case mpUint8, mpUint16, mpUint32, mpUint64:
+		if n, err = s.readUint(1 << (b - mpUint8)); err == nil {
+			s.out.Buf(func(buf []byte) []byte { return strconv.AppendUint(buf, n, 10) })
+		}
+	case mpInt8, mpInt16, mpInt32, mpInt64:
+		size := 1 << (b - mpInt8)
+		if n, err = s.readUint(size); err == nil {
+			shift := 64 - 8*size
+			v := int64(n<<shift) >> shift // sign extension
+			s.out.Buf(func(buf []byte) []byte { return strconv.AppendInt(buf, v, 10) })
+		}
+	case mpStr8, mpStr16, mpStr32:
+		if n, err = s.readUint(1 << (b - mpStr8)); err == nil {
+			err = s.str(n)
+		}
+	case mpArray16, mpArray32:
+		if n, err = s.readUint(2 << (b - mpArray16)); err == nil {
+			err = s.writeArray(int(n), s.item)
+		}
+	case mpMap16, mpMap32:
+		if n, err = s.readUint(2 << (b - mpMap16)); err == nil {
+			err = s.writeMap(int(n), s.item)
+		}
+	default:
+		err = fmt.Errorf("%w: bad format 0x%02x", errMalformedBinary, b)
+	}
+	return
+}
+
+func (s *msgpackDecoder) str(n uint64) error {
+	data, err := s.read(n)
+	if err == nil {
+		s.writeString(data)
+	}
+	return err
+}
+
+// ext decodes an extension type of n bytes.
+func (s *msgpackDecoder) ext(n uint64) (err error) {
+	typ, err := s.r.ReadByte()
+	if err != nil {
+		return
+	}
+	data, err := s.read(n)
+	if err != nil {
+		return
+	}
+	if typ != mpExtTimestamp {
+		s.writeBase64(data)
+		return
+	}
+
+	switch len(data) {
+	case 4:
+		s.writeTime(time.Unix(int64(binary.BigEndian.Uint32(data)), 0))
+	case 8:
+		v := binary.BigEndian.Uint64(data)
+		s.writeTime(time.Unix(int64(v&(1<<34-1)), int64(v>>34)))
+	case 12:
+		ns := binary.BigEndian.Uint32(data)
+		s.writeTime(time.Unix(int64(binary.BigEndian.Uint64(data[4:])), int64(ns)))
+	default:
+		err = fmt.Errorf("%w: bad timestamp", errMalformedBinary)
+	}
+	return
+}
@@ -0,0 +1,280 @@
+package slog
+
+import (
+	"bytes"
+	enchex "encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+type binUser struct {
+	Name string
+	Age  uint8
+	Tags []string
+}
+
+func (u binUser) MarshalSlogObject(enc *PrintCtx) error {
+	enc.Begin()
+	enc.AddString("name", u.Name)
+	enc.AddComma()
+	enc.AddUint8("age", u.Age)
+	enc.AddComma()
+	enc.AppendKey("tags")
+	enc.AppendStringSlice(u.Tags)
+	enc.End(false)
+	return nil
+}
+
+type binUsers []binUser
+
+func (uu binUsers) MarshalSlogArray(enc *PrintCtx) error {
+	enc.BeginArray()
+	for i := range uu {
+		if i > 0 {
+			enc.AddComma()
+		}
+		_ = uu[i].MarshalSlogObject(enc)
+	}
+	enc.EndArray(false)
+	return nil
+}
+
+func TestBinaryModes(t *testing.T) {
+	defer SaveFlagsAndMod(Lcaller)()
+
+	tm := time.Date(2024, 5, 6, 7, 8, 9, 500_000_000, time.UTC)
+	for _, c := range []struct {
+		mode   Mode
+		toJSON func(w io.Writer, r io.Reader) error
+	}{
+		{ModeCBOR, CBORToJSON},
+		{ModeMsgPack, MsgPackToJSON},
+	} {
+		t.Run(c.mode.String(), func(t *testing.T) {
+			var buf bytes.Buffer
+			l := New("bin", WithWriter(&buf), WithErrorWriter(&buf), WithLevel(InfoLevel), WithMode(c.mode))
+			l.Info("hello\nworld",
+				"i", -1000, "u", uint64(1<<40), "f32", float32(1.5), "f64", 0.1, "b", true, "s", "x\"y",
+				"raw", []byte{1, 2, 3}, "t", tm, "d", time.Second, "n", nil, "c", complex(1, 2),
+				"ints", []int{1, -2}, "floats", []float64{0.5}, "bools", []bool{true},
+				Group("g", "x", 1, Group("h", "z", "deep")),
+				"user", binUser{"alice", 30, []string{"a", "b"}},
+				"users", binUsers{{Name: "bob"}, {Name: "carol"}},
+				"err", errors.New("boom"),
+			)
+			l.Warn("second")
+			if bytes.Contains(buf.Bytes(), []byte("\n\x00")) || buf.Len() == 0 {
+				t.Fatalf("unexpected output: %q", buf.Bytes())
+			}
+
+			var out bytes.Buffer
+			if err := c.toJSON(&out, &buf); err != nil {
+				t.Fatalf("decode failed: %v, got %q", err, out.String())
+			}
+			lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+			if len(lines) != 2 {
+				t.Fatalf("expect 2 lines, but got %q", out.String())
+			}
+			t.Log(lines[0])
+
+			var m map[string]any
+			if err := json.Unmarshal([]byte(lines[0]), &m); err != nil {
+				t.Fatalf("bad json %q: %v", lines[0], err)
+			}
+			for k, v := range map[string]any{
+				"logger": "bin",
+				"level":  "info",
+				"msg":    "hello\nworld",
+				"i":      float64(-1000),
+				"u":      float64(1 << 40),
+				"f32":    1.5,
+				"f64":    0.1,
+				"b":      true,
+				"s":      "x\"y",
+				"raw":    "AQID",
+				"t":      "2024-05-06T07:08:09.5Z",
+				"d":      "1s",
+				"n":      nil,
+				"c":      "(1+2i)",
+			} {
+				if m[k] != v {
+					t.Fatalf("expect %q = %v (%T), but got %v (%T)", k, v, v, m[k], m[k])
+				}
+			}
+			for k, v := range map[string]string{
+				"ints":   `[1,-2]`,
+				"floats": `[0.5]`,
+				"bools":  `[true]`,
+				"g":      `{"h":{"z":"deep"},"x":1}`,
+				"user":   `{"age":30,"name":"alice","tags":["a","b"]}`,
+				"users":  `[{"age":0,"name":"bob","tags":[]},{"age":0,"name":"carol","tags":[]}]`,
+				"err":    `{"message":"boom"}`,
+			} {
+				if got, _ := json.Marshal(m[k]); string(got) != v {
+					t.Fatalf("expect %q = %s, but got %s", k, v, got)
+				}
+			}
+			if ts, err := time.Parse(time.RFC3339Nano, m["time"].(string)); err != nil || time.Since(ts) > time.Minute {
+				t.Fatalf("bad time: %v, %v", m["time"], err)
+			}
+			if caller, ok := m["caller"].(map[string]any); !ok || !strings.HasSuffix(caller["file"].(string), "binary_test.go") {
+				t.Fatalf("bad caller: %v", m["caller"])
+			}
+
+			if err := json.Unmarshal([]byte(lines[1]), &m); err != nil || m["msg"] != "second" || m["level"] != "warning" {
+				t.Fatalf("unexpected line %q: %v", lines[1], err)
+			}
+		})
+	}
+}
+
+func TestBinaryNestedGroups(t *testing.T) {
+	for _, c := range []struct {
+		mode   Mode
+		toJSON func(w io.Writer, r io.Reader) error
+	}{
+		{ModeCBOR, CBORToJSON},
+		{ModeMsgPack, MsgPackToJSON},
+	} {
+		t.Run(c.mode.String(), func(t *testing.T) {
+			var buf bytes.Buffer
+			l := New("bin", WithWriter(&buf), WithLevel(InfoLevel), WithMode(c.mode))
+			l.Info("first",
+				"g", Group("gg", "a", 1, Group("ggg", "b", "x")),
+				"a", NewAttr("b", NewAttr("c", 2)),
+				"as", Attrs{NewAttr("d", 3), Int("e", 4)},
+				"s", String("f", "y"),
+				"x", 5,
+			)
+			l.Info("second", "y", 6)
+
+			var out bytes.Buffer
+			if err := c.toJSON(&out, &buf); err != nil {
+				t.Fatalf("decode failed: %v, got %q", err, out.String())
+			}
+			lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+			if len(lines) != 2 {
+				t.Fatalf("expect 2 lines, but got %q", out.String())
+			}
+
+			var m map[string]any
+			if err := json.Unmarshal([]byte(lines[0]), &m); err != nil {
+				t.Fatalf("bad json %q: %v", lines[0], err)
+			}
+			for k, v := range map[string]string{
+				"g":  `{"gg":{"a":1,"ggg":{"b":"x"}}}`,
+				"a":  `{"b":{"c":2}}`,
+				"as": `{"d":3,"e":4}`,
+				"s":  `{"f":"y"}`,
+				"x":  `5`,
+			} {
+				if got, _ := json.Marshal(m[k]); string(got) != v {
+					t.Fatalf("expect %q = %s, but got %s", k, v, got)
+				}
+			}
+
+			if err := json.Unmarshal([]byte(lines[1]), &m); err != nil || m["msg"] != "second" || m["y"] != float64(6) {
+				t.Fatalf("unexpected line %q: %v", lines[1], err)
+			}
+		})
+	}
+}
+
+func TestCBORFormat(t *testing.T) {
+	var f cborFormat
+	for _, c := range []struct {
+		b    []byte
+		want string
+	}{ // RFC 8949, Appendix A
+		{f.appendInt(nil, 0), "00"},
+		{f.appendInt(nil, 24), "1818"},
+		{f.appendInt(nil, 1000000), "1a000f4240"},
+		{f.appendUint(nil, 18446744073709551615), "1bffffffffffffffff"},
+		{f.appendInt(nil, -1), "20"},
+		{f.appendInt(nil, -1000), "3903e7"},
+		{f.appendFloat(nil, 100000.0, 64), "fa47c35000"},
+		{f.appendFloat(nil, 1.1, 64), "fb3ff199999999999a"},
+		{f.appendBool(nil, false), "f4"},
+		{f.appendNil(nil), "f6"},
+		{f.appendString(nil, "IETF"), "6449455446"},
+		{f.appendBytes(nil, []byte{1, 2, 3, 4}), "4401020304"},
+		{f.appendTime(nil, time.Unix(1363896240, 0)), "c11a514b67b0"},
+		{f.appendTime(nil, time.Unix(1363896240, 500_000_000)), "c1fb41d452d9ec200000"},
+		{f.appendHeader(nil, true, 25), "9819"},
+		{f.appendHeader(nil, false, 2), "a2"},
+	} {
+		if got := enchex.EncodeToString(c.b); got != c.want {
+			t.Fatalf("expect %s, but got %s", c.want, got)
+		}
+	}
+}
+
+func TestMsgPackFormat(t *testing.T) {
+	var f msgpackFormat
+	for _, c := range []struct {
+		b    []byte
+		want string
+	}{
+		{f.appendInt(nil, 127), "7f"},
+		{f.appendInt(nil, -32), "e0"},
+		{f.appendInt(nil, -33), "d0df"},
+		{f.appendInt(nil, 256), "cd0100"},
+		{f.appendInt(nil, -40000), "d2ffff63c0"},
+		{f.appendUint(nil, 1<<32), "cf0000000100000000"},
+		{f.appendFloat(nil, 1.5, 32), "ca3fc00000"},
+		{f.appendBool(nil, true), "c3"},
+		{f.appendNil(nil), "c0"},
+		{f.appendString(nil, "abc"), "a3616263"},
+		{f.appendString(nil, strings.Repeat("x", 32))[:2], "d920"},
+		{f.appendBytes(nil, []byte{1}), "c40101"},
+		{f.appendTime(nil, time.Unix(1, 0)), "d6ff00000001"},
+		{f.appendTime(nil, time.Unix(1, 1)), "d7ff0000000400000001"},
+		{f.appendTime(nil, time.Unix(-1, 0)), "c70cff00000000ffffffffffffffff"},
+		{f.appendHeader(nil, true, 16), "dc0010"},
+		{f.appendHeader(nil, false, 15), "8f"},
+	} {
+		if got := enchex.EncodeToString(c.b); got != c.want {
+			t.Fatalf("expect %s, but got %s", c.want, got)
+		}
+	}
+}
+
+func TestBinaryToJSON(t *testing.T) {
+	for _, c := range []struct {
+		name, data, want string
+	}{
+		// indefinite-length map, string and array; half floats; tag 0
+		{"cbor", "bf61617f6261626163ff609f01f93c00f97e00ff6174c074323031332d30332d32315432303a30343a30305aff",
+			`{"a":"abc","":[1,1,"NaN"],"t":"2013-03-21T20:04:00Z"}`},
+		{"cbor", "a201f5a10102f6", `{"1":true,"{\"1\":2}":null}`},
+		{"cbor", "3bffffffffffffffff", `-18446744073709551616`},
+		{"msgpack", "82a161d0ffa162c70201ffff", `{"a":-1,"b":"//8="}`},
+		{"msgpack", "93d1fc18d7ff00000004000000019100", `[-1000,"1970-01-01T00:00:01.000000001Z",[0]]`},
+	} {
+		data, _ := enchex.DecodeString(c.data)
+		var out bytes.Buffer
+		var err error
+		if c.name == "cbor" {
+			err = CBORToJSON(&out, bytes.NewReader(data))
+		} else {
+			err = MsgPackToJSON(&out, bytes.NewReader(data))
+		}
+		if err != nil || strings.TrimSuffix(out.String(), "\n") != c.want {
+			t.Fatalf("%s %s: expect %s, but got %s (%v)", c.name, c.data, c.want, out.String(), err)
+		}
+	}
+
+	for _, data := range []string{"a201", "bf61", "c1", "ff"} {
+		b, _ := enchex.DecodeString(data)
+		if err := CBORToJSON(new(bytes.Buffer), bytes.NewReader(b)); err == nil {
+			t.Fatalf("expect an error for %s", data)
+		}
+	}
+	if err := MsgPackToJSON(new(bytes.Buffer), bytes.NewReader([]byte{0xc1})); !errors.Is(err, errMalformedBinary) {
+		t.Fatalf("expect errMalformedBinary, but got %v", err)
+	}
+}
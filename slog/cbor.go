@@ -0,0 +1,116 @@
+package slog
+
+import (
+	"encoding/binary"
+	"math"
+	"time"
+)
+
+// ModeCBOR prints each logging line as a CBOR (RFC 8949) map, with
+// the same logical structure as ModeJSON. The integers and the
+// floats are typed, and the times are tagged as epoch-based
+// date/time (tag 1).
+//
+// The lines are concatenated data items without delimiters, use
+// CBORToJSON to read them.
+var ModeCBOR, _ = RegisterMode("cbor", func() Painter { return NewCBORPainter() })
+
+// NewCBORPainter returns the painter of ModeCBOR.
+func NewCBORPainter() Painter {
+	return &binaryPainter{f: cborFormat{}}
+}
+
+// The major types of CBOR.
+const (
+	cborUint   = 0 << 5
+	cborNegInt = 1 << 5
+	cborBytes  = 2 << 5
+	cborText   = 3 << 5
+	cborArray  = 4 << 5
+	cborMap    = 5 << 5
+	cborTag    = 6 << 5
+	cborSimple = 7 << 5
+
+	cborFalse     = cborSimple | 20
+	cborTrue      = cborSimple | 21
+	cborNull      = cborSimple | 22
+	cborUndefined = cborSimple | 23
+	cborFloat16   = cborSimple | 25
+	cborFloat32   = cborSimple | 26
+	cborFloat64   = cborSimple | 27
+	cborBreak     = cborSimple | 31
+
+	cborTagDateTime = 0 // RFC 3339 string
+	cborTagEpoch    = 1 // epoch-based date/time
+)
+
+type cborFormat struct{}
+
+// appendHead appends the initial byte of major type and the
+// argument n in the shortest form.
+func (cborFormat) appendHead(b []byte, major byte, n uint64) []byte {
+	switch {
+	case n < 24:
+		return append(b, major|byte(n))
+	case n <= math.MaxUint8:
+		return append(b, major|24, byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, major|25), uint16(n))
+	case n <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(b, major|26), uint32(n))
+	}
+	return binary.BigEndian.AppendUint64(append(b, major|27), n)
+}
+
+func (f cborFormat) appendNil(b []byte) []byte { return append(b, cborNull) }
+
+func (f cborFormat) appendBool(b []byte, v bool) []byte {
+	if v {
+		return append(b, cborTrue)
+	}
+	return append(b, cborFalse)
+}
+
+func (f cborFormat) appendInt(b []byte, v int64) []byte {
+	if v < 0 {
+		return f.appendHead(b, cborNegInt, uint64(^v)) // -1-v
+	}
+	return f.appendHead(b, cborUint, uint64(v))
+}
+
+func (f cborFormat) appendUint(b []byte, v uint64) []byte {
+	return f.appendHead(b, cborUint, v)
+}
+
+// appendFloat writes a float64 as float32 if it is lossless.
+func (f cborFormat) appendFloat(b []byte, v float64, bitSize int) []byte {
+	if bitSize == 32 || float64(float32(v)) == v {
+		return binary.BigEndian.AppendUint32(append(b, cborFloat32), math.Float32bits(float32(v)))
+	}
+	return binary.BigEndian.AppendUint64(append(b, cborFloat64), math.Float64bits(v))
+}
+
+func (f cborFormat) appendString(b []byte, v string) []byte {
+	return append(f.appendHead(b, cborText, uint64(len(v))), v...)
+}
+
+func (f cborFormat) appendBytes(b []byte, v []byte) []byte {
+	return append(f.appendHead(b, cborBytes, uint64(len(v))), v...)
+}
+
+// appendTime writes the seconds since epoch with tag 1, as an
+// integer, or a float if it has fractional seconds.
+func (f cborFormat) appendTime(b []byte, v time.Time) []byte {
+	b = f.appendHead(b, cborTag, cborTagEpoch)
+	if ns := v.Nanosecond(); ns != 0 {
+		return f.appendFloat(b, float64(v.Unix())+float64(ns)/1e9, 64)
+	}
+	return f.appendInt(b, v.Unix())
+}
+
+func (f cborFormat) appendHeader(b []byte, array bool, n int) []byte {
+	if array {
+		return f.appendHead(b, cborArray, uint64(n))
+	}
+	return f.appendHead(b, cborMap, uint64(n))
+}
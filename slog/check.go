@@ -0,0 +1,62 @@
+package slog
+
+import (
+	"context"
+	"sync"
+)
+
+// CheckedEntry is a record whose level is tested, like zap's. It
+// guards the expensive attributes:
+//
+//	if ce := logger.Check(ctx, DebugLevel, "dumped"); ce != nil {
+//		ce.Write("state", dump())
+//	}
+//
+// The caller is captured by Check, and the hooks run once while
+// writing. A CheckedEntry is pooled, it must not be used after Write.
+type CheckedEntry struct {
+	s     *Entry
+	ctx   context.Context
+	lvl   Level
+	frame uintptr // the caller of Check
+	msg   string
+}
+
+var poolCheckedEntry = sync.Pool{New: func() any { return &CheckedEntry{} }}
+
+// Check tests lvl as the logging methods, the custom levels are
+// tested as the levels they are registered to be treated as. It
+// returns nil if lvl is disabled.
+func (s *Entry) Check(ctx context.Context, lvl Level, msg string) *CheckedEntry {
+	if ctx == nil {
+		ctx = context.TODO()
+	}
+	if !s.EnabledContext(ctx, lvl) || s.handlerOpt != nil {
+		return nil
+	}
+	ce := poolCheckedEntry.Get().(*CheckedEntry)
+	ce.s, ce.ctx, ce.lvl, ce.msg = s, ctx, lvl, msg
+	ce.frame = getpc(2, s.extraFrames)
+	return ce
+}
+
+// Level returns the level checked.
+func (ce *CheckedEntry) Level() Level {
+	if ce == nil {
+		return OffLevel
+	}
+	return ce.lvl
+}
+
+// Write logs the record with args, which are the same as the ones of
+// Info, Debug, .... The level isn't tested again. Write on a nil
+// CheckedEntry does nothing.
+func (ce *CheckedEntry) Write(args ...any) {
+	if ce == nil {
+		return
+	}
+	s, ctx, lvl, frame, msg := ce.s, ce.ctx, ce.lvl, ce.frame, ce.msg
+	*ce = CheckedEntry{}
+	poolCheckedEntry.Put(ce) // before logContext, which may panic
+	s.logContext(ctx, lvl, false, frame, msg, args...)
+}
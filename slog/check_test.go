@@ -0,0 +1,72 @@
+package slog
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	defer SaveFlagsAndMod(Lcaller|Llineno, LattrsR)()
+
+	var buf bytes.Buffer
+	var hooked []Level
+	l := New("ck", WithWriter(&buf), WithErrorWriter(&buf), WithLevel(WarnLevel), WithJSONMode(),
+		WithHook(nil, func(ctx context.Context, rec *Record) error {
+			hooked = append(hooked, rec.Level)
+			return nil
+		}))
+	ctx := context.Background()
+
+	built := false
+	if ce := l.Check(ctx, TraceLevel, "skipped"); ce != nil {
+		built = true
+		ce.Write("k", "v")
+	}
+	var nilEntry *CheckedEntry
+	nilEntry.Write("k", "v")
+	if built || buf.Len() != 0 || len(hooked) != 0 || nilEntry.Level() != OffLevel {
+		t.Fatalf("expect nothing built for a disabled level, but got %q", buf.String())
+	}
+
+	_, _, line, _ := runtime.Caller(0)
+	ce := l.Check(ctx, WarnLevel, "checked") // the caller is here
+	ce.Write("k", "v")
+	out := buf.String()
+	if !strings.Contains(out, `"msg":"checked"`) || !strings.Contains(out, `"k":"v"`) ||
+		!strings.Contains(out, fmt.Sprintf(`"line":%d`, line+1)) {
+		t.Fatalf("expect the record logged at the Check site, but got %q", out)
+	}
+	if len(hooked) != 1 || hooked[0] != WarnLevel {
+		t.Fatalf("expect the hooks run once, but got %v", hooked)
+	}
+}
+
+func TestCheckCustomLevels(t *testing.T) {
+	var buf bytes.Buffer
+	l := New("ck", WithWriter(&buf), WithErrorWriter(&buf), WithLevel(WarnLevel), WithColorMode(false))
+	ctx := context.Background()
+
+	// OKLevel is treated as InfoLevel, and FailLevel as ErrorLevel
+	if ce := l.Check(ctx, OKLevel, "ok"); ce != nil {
+		t.Fatalf("expect OKLevel disabled as InfoLevel, but got %v", ce.Level())
+	}
+	ce := l.Check(ctx, FailLevel, "failed")
+	if ce.Level() != FailLevel {
+		t.Fatalf("expect FailLevel enabled as ErrorLevel, but got %v", ce.Level())
+	}
+	ce.Write()
+	if out := buf.String(); !strings.Contains(out, "failed") {
+		t.Fatalf("expect the record logged, but got %q", out)
+	}
+
+	buf.Reset()
+	l.SetLevel(InfoLevel)
+	l.Check(ctx, OKLevel, "ok").Write("n", 1)
+	if out := buf.String(); !strings.Contains(out, "ok") || !strings.Contains(out, "n=1") {
+		t.Fatalf("expect OKLevel enabled as InfoLevel, but got %q", out)
+	}
+}
@@ -0,0 +1,331 @@
+package slog
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The modes for CI systems. If LsmartJSONMode is set, one of them
+// will be selected automatically for the loggers in ModeColorful or
+// ModePlain by the environment variables of CI systems.
+var (
+	// ModeGitHubActions prints Warn, Error, Fail, Panic and Fatal
+	// records as the workflow commands of GitHub Actions, such as
+	// "::warning file=main.go,line=12::msg", so they are shown as
+	// annotations in the pull requests.
+	ModeGitHubActions, _ = RegisterMode("github-actions", func() Painter { return NewGitHubActionsPainter() },
+		ModeAutoSelect(func() bool { return os.Getenv("GITHUB_ACTIONS") == "true" }))
+	// ModeGitLab prints colorful text lines, and supports the
+	// collapsible sections of GitLab CI.
+	ModeGitLab, _ = RegisterMode("gitlab", func() Painter { return NewGitLabPainter() },
+		ModeAutoSelect(func() bool { return os.Getenv("GITLAB_CI") == "true" }))
+	// ModeTeamCity prints Warn, Error, Fail, Panic and Fatal records
+	// as the service messages of TeamCity.
+	ModeTeamCity, _ = RegisterMode("teamcity", func() Painter { return NewTeamCityPainter() },
+		ModeAutoSelect(func() bool { return os.Getenv("TEAMCITY_VERSION") != "" }))
+)
+
+// SectionPainter can be implemented by a [Painter] to print the
+// beginning and the end of a collapsible section. See [Section].
+type SectionPainter interface {
+	PaintSection(pc *PrintCtx, name, header string, begin bool)
+}
+
+// SectionLogger is implemented by the loggers which print the
+// collapsible sections, such as *Entry. See [Section].
+type SectionLogger interface {
+	Section(name, header string) (end func())
+}
+
+var _ SectionLogger = (*Entry)(nil)
+
+// Section prints the beginning of a collapsible section by logger,
+// and returns a func to print the end of it:
+//
+//	end := slog.Section(logger, "build", "Building the binaries")
+//	defer end()
+//
+// If logger is not a [SectionLogger], the header is printed as an
+// Info line, and the end prints nothing. A nil logger means Default().
+func Section(logger Logger, name, header string) (end func()) {
+	if logger == nil {
+		logger = Default()
+	}
+	if sl, ok := logger.(SectionLogger); ok {
+		return sl.Section(name, header)
+	}
+	logger.Info(header)
+	return func() {}
+}
+
+// Section implements [SectionLogger]. If the painter of this logger
+// is not a [SectionPainter], the header is printed as an Info line,
+// and the end prints nothing.
+func (s *Entry) Section(name, header string) (end func()) {
+	if !s.paintSection(name, header, true) {
+		s.Info(header)
+		return func() {}
+	}
+	return func() { s.paintSection(name, header, false) }
+}
+
+func (s *Entry) paintSection(name, header string, begin bool) (painted bool) {
+	if !AlwaysLevel.Enabled(context.Background(), s.level) {
+		return true // nothing to print
+	}
+
+	pc := poolPrintCtx.Get().(*PrintCtx)
+	defer pc.putBack()
+	pc.set(s, InfoLevel, time.Now(), 0, header, nil)
+
+	sp, ok := pc.ip.(SectionPainter)
+	if !ok {
+		return false
+	}
+	sp.PaintSection(pc, name, header, begin)
+	_, _ = s.printOut(InfoLevel, pc.Bytes()) // it counts the bytes and warns on error
+	return true
+}
+
+// ciTextLayout is used for the lines which are not annotations.
+const ciTextLayout = "{?logger}[{logger}] {/}{msg}{?attrs} {attrs}{/}"
+
+// ciFile returns the file of the caller relative to the workspace
+// (dir), or the absolute one if it is out of the workspace.
+func ciFile(pc *PrintCtx, dir string) (file string, line int) {
+	if pc.stackFrame == 0 {
+		return
+	}
+	frame, _ := runtime.CallersFrames([]uintptr{pc.stackFrame}).Next()
+	file, line = frame.File, frame.Line
+	if dir == "" {
+		dir, _ = os.Getwd()
+	}
+	if rel, err := filepath.Rel(dir, file); err == nil && !strings.HasPrefix(rel, "..") {
+		file = filepath.ToSlash(rel)
+	}
+	return
+}
+
+// paintText paints the text of a logging line by layout, and
+// returns the start offset of it in pc.
+func paintText(pc *PrintCtx, layout *LayoutPainter) (start int, holdErrorValue error) {
+	start = len(pc.buf)
+	holdErrorValue = layout.PaintLine(pc)
+	return
+}
+
+// escapeFrom replaces the text from start by escaping it.
+func (s *PrintCtx) escapeFrom(start int, escape func(string) string) {
+	text := escape(string(s.buf[start:]))
+	s.buf = append(s.buf[:start], text...)
+}
+
+//
+//
+//
+
+// NewGitHubActionsPainter returns the painter of ModeGitHubActions.
+//
+// The file paths in the annotations are relative to GITHUB_WORKSPACE.
+func NewGitHubActionsPainter() Painter {
+	return &githubPainter{
+		LayoutPainter: MustLayoutPainter(ciTextLayout, LayoutColorful(false)),
+		workspace:     os.Getenv("GITHUB_WORKSPACE"),
+	}
+}
+
+type githubPainter struct {
+	*LayoutPainter
+	workspace string
+}
+
+var _ LinePainter = (*githubPainter)(nil)
+var _ SectionPainter = (*githubPainter)(nil)
+
+func githubCommand(lvl Level) string {
+	if l, ok := mLevelIsEnabledAs[lvl]; ok {
+		switch lvl {
+		case OKLevel, SuccessLevel:
+			return "notice"
+		}
+		lvl = l
+	}
+	switch lvl {
+	case PanicLevel, FatalLevel, ErrorLevel:
+		return "error"
+	case WarnLevel:
+		return "warning"
+	case DebugLevel, TraceLevel:
+		return "debug"
+	}
+	return ""
+}
+
+// githubEscapeData escapes the message of a workflow command.
+var githubEscapeData = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace
+
+// githubEscapeProperty escapes a property value of a workflow command.
+var githubEscapeProperty = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace
+
+// PaintLine implements [LinePainter].
+func (s *githubPainter) PaintLine(pc *PrintCtx) (holdErrorValue error) {
+	cmd := githubCommand(pc.lvl)
+	if cmd == "" {
+		_, holdErrorValue = paintText(pc, s.LayoutPainter)
+		return
+	}
+
+	pc.AppendString("::")
+	pc.AppendString(cmd)
+	if cmd != "debug" {
+		sep := byte(' ')
+		if file, line := ciFile(pc, s.workspace); file != "" {
+			pc.AppendString(" file=")
+			pc.AppendString(githubEscapeProperty(file))
+			pc.AppendString(",line=")
+			pc.AppendInt(line)
+			sep = ','
+		}
+		if pc.name != "" {
+			pc.AppendByte(sep)
+			pc.AppendString("title=")
+			pc.AppendString(githubEscapeProperty(pc.name))
+		}
+	}
+	pc.AppendString("::")
+
+	// a workflow command must be in one line, so the error value
+	// is not held for dumping after the line.
+	start, _ := paintText(pc, s.LayoutPainter)
+	pc.escapeFrom(start, githubEscapeData)
+	return
+}
+
+// PaintSection implements [SectionPainter].
+func (s *githubPainter) PaintSection(pc *PrintCtx, name, header string, begin bool) {
+	if begin {
+		pc.AppendString("::group::")
+		pc.AppendString(githubEscapeData(header))
+	} else {
+		pc.AppendString("::endgroup::")
+	}
+	pc.AppendByte('\n')
+}
+
+//
+//
+//
+
+// NewGitLabPainter returns the painter of ModeGitLab.
+func NewGitLabPainter() Painter {
+	return &gitlabPainter{MustLayoutPainter("{time} {level} "+ciTextLayout, LayoutColorful(true))}
+}
+
+type gitlabPainter struct {
+	*LayoutPainter
+}
+
+var _ SectionPainter = (*gitlabPainter)(nil)
+
+// PaintSection implements [SectionPainter].
+func (s *gitlabPainter) PaintSection(pc *PrintCtx, name, header string, begin bool) {
+	pc.AppendString("\x1b[0K")
+	if begin {
+		pc.AppendString("section_start:")
+	} else {
+		pc.AppendString("section_end:")
+	}
+	pc.AppendString(strconv.FormatInt(pc.now.Unix(), 10))
+	pc.AppendByte(':')
+	pc.AppendString(gitlabSectionName(name))
+	if begin {
+		pc.AppendString("[collapsed=true]\r\x1b[0K")
+		pc.AppendString(header)
+	} else {
+		pc.AppendString("\r\x1b[0K")
+	}
+	pc.AppendByte('\n')
+}
+
+// gitlabSectionName replaces the characters not allowed in the
+// name of a section, [^A-Za-z0-9_.-], with '_'.
+func gitlabSectionName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '_' || r == '.' || r == '-' || (r >= '0' && r <= '9') || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') {
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+//
+//
+//
+
+// NewTeamCityPainter returns the painter of ModeTeamCity.
+func NewTeamCityPainter() Painter {
+	return &teamcityPainter{MustLayoutPainter(ciTextLayout, LayoutColorful(false))}
+}
+
+type teamcityPainter struct {
+	*LayoutPainter
+}
+
+var _ LinePainter = (*teamcityPainter)(nil)
+var _ SectionPainter = (*teamcityPainter)(nil)
+
+// teamcityEscape escapes a value of the attribute of a service message.
+var teamcityEscape = strings.NewReplacer("|", "||", "'", "|'", "\n", "|n", "\r", "|r", "[", "|[", "]", "|]").Replace
+
+func teamcityStatus(lvl Level) string {
+	if l, ok := mLevelIsEnabledAs[lvl]; ok {
+		lvl = l
+	}
+	switch lvl {
+	case PanicLevel, FatalLevel:
+		return "ERROR"
+	case ErrorLevel:
+		return "FAILURE"
+	case WarnLevel:
+		return "WARNING"
+	}
+	return ""
+}
+
+// PaintLine implements [LinePainter].
+func (s *teamcityPainter) PaintLine(pc *PrintCtx) (holdErrorValue error) {
+	status := teamcityStatus(pc.lvl)
+	if status == "" {
+		_, holdErrorValue = paintText(pc, s.LayoutPainter)
+		return
+	}
+
+	pc.AppendString("##teamcity[message text='")
+	start, _ := paintText(pc, s.LayoutPainter) // a service message must be in one line
+	pc.escapeFrom(start, teamcityEscape)
+	pc.AppendString("' status='")
+	pc.AppendString(status)
+	pc.AppendString("']")
+	return
+}
+
+// PaintSection implements [SectionPainter].
+func (s *teamcityPainter) PaintSection(pc *PrintCtx, name, header string, begin bool) {
+	if begin {
+		pc.AppendString("##teamcity[blockOpened name='")
+		pc.AppendString(teamcityEscape(name))
+		pc.AppendString("' description='")
+		pc.AppendString(teamcityEscape(header))
+		pc.AppendString("']\n")
+	} else {
+		pc.AppendString("##teamcity[blockClosed name='")
+		pc.AppendString(teamcityEscape(name))
+		pc.AppendString("']\n")
+	}
+}
@@ -0,0 +1,138 @@
+package slog
+
+import (
+	"bytes"
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestGitHubActionsPainter(t *testing.T) {
+	defer SaveFlagsAndMod(Lcaller)()
+
+	var buf bytes.Buffer
+	l := New("ci", WithWriter(&buf), WithErrorWriter(&buf), WithLevel(InfoLevel), WithMode(ModeGitHubActions))
+	l.Info("plain", "a", 1)
+	l.Warn("careful: 100%\nsecond", "k", "v")
+	l.Error("broken", "err", errors.New("x"))
+	l.OK("good")
+	t.Log(buf.String())
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) < 4 {
+		t.Fatalf("unexpected output %q", buf.String())
+	}
+	if lines[0] != "[ci] plain a=1" {
+		t.Fatalf("unexpected line %q", lines[0])
+	}
+	re := regexp.MustCompile(`^::warning file=ci_test\.go,line=\d+,title=ci::\[ci\] careful: 100%25 k="v"%0A    second$`)
+	if !re.MatchString(lines[1]) {
+		t.Fatalf("unexpected line %q", lines[1])
+	}
+	if !regexp.MustCompile(`^::error file=ci_test\.go,line=\d+,title=ci::\[ci\] broken err=x$`).MatchString(lines[2]) {
+		t.Fatalf("unexpected line %q", lines[2])
+	}
+	if !strings.HasPrefix(lines[3], "::notice file=") {
+		t.Fatalf("unexpected line %q", lines[3])
+	}
+
+	buf.Reset()
+	end := Section(l, "build", "Building, now")
+	l.Info("inside")
+	end()
+	if got := buf.String(); got != "::group::Building, now\n[ci] inside\n::endgroup::\n" {
+		t.Fatalf("unexpected section %q", got)
+	}
+}
+
+func TestGitLabPainter(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(WithWriter(&buf), WithLevel(InfoLevel), WithMode(ModeGitLab))
+	end := Section(l, "my step!", "Step")
+	l.Info("inside")
+	end()
+	t.Logf("%q", buf.String())
+
+	lines := strings.Split(buf.String(), "\n")
+	if !regexp.MustCompile("^\x1b\\[0Ksection_start:\\d+:my_step_\\[collapsed=true\\]\r\x1b\\[0KStep$").MatchString(lines[0]) {
+		t.Fatalf("unexpected section start %q", lines[0])
+	}
+	if !strings.Contains(lines[1], "inside") {
+		t.Fatalf("unexpected line %q", lines[1])
+	}
+	if !regexp.MustCompile("^\x1b\\[0Ksection_end:\\d+:my_step_\r\x1b\\[0K$").MatchString(lines[2]) {
+		t.Fatalf("unexpected section end %q", lines[2])
+	}
+}
+
+func TestTeamCityPainter(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(WithWriter(&buf), WithErrorWriter(&buf), WithLevel(InfoLevel), WithMode(ModeTeamCity))
+	end := Section(l, "b", "it's [x]")
+	l.Info("normal")
+	l.Warn("it's [bad]|\nreally")
+	l.Fail("failed")
+	end()
+	want := "##teamcity[blockOpened name='b' description='it|'s |[x|]']\n" +
+		"normal\n" +
+		"##teamcity[message text='it|'s |[bad|]|||n    really' status='WARNING']\n" +
+		"##teamcity[message text='failed' status='FAILURE']\n" +
+		"##teamcity[blockClosed name='b']\n"
+	if got := buf.String(); got != want {
+		t.Fatalf("unexpected output:\n%q\nwant:\n%q", got, want)
+	}
+}
+
+func TestSectionFallback(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(WithWriter(&buf), WithJSONMode(), WithLevel(InfoLevel))
+	Section(l, "s", "header")()
+	if got := buf.String(); !strings.Contains(got, `"msg":"header"`) || strings.Count(got, "\n") != 1 {
+		t.Fatalf("expect the header printed as an info line, but got %q", got)
+	}
+}
+
+func TestSectionLogger(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(WithWriter(&buf), WithLevel(InfoLevel), WithMode(ModeGitHubActions))
+	wrapped := struct{ Logger }{l} // an external Logger, which isn't a SectionLogger
+	Section(wrapped, "s", "header")()
+	if got := buf.String(); got != "header\n" {
+		t.Fatalf("expect the header printed as an info line, but got %q", got)
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) { return 0, errors.New("disk full") }
+
+func TestSectionWriteFailed(t *testing.T) {
+	var buf bytes.Buffer
+	l := New("ci", WithWriter(failingWriter{}), WithErrorWriter(&buf), WithLevel(InfoLevel), WithMode(ModeGitHubActions))
+	Section(l, "s", "header")()
+	if got := strings.Count(buf.String(), "slog print log failed"); got != 2 {
+		t.Fatalf("expect one warning for each section line, but got %q", buf.String())
+	}
+}
+
+func TestCIAutoSelect(t *testing.T) {
+	defer SaveFlagsAndMod(LsmartJSONMode)()
+	clearCIEnv(t)
+
+	for env, mode := range map[string]Mode{
+		"GITHUB_ACTIONS":   ModeGitHubActions,
+		"GITLAB_CI":        ModeGitLab,
+		"TEAMCITY_VERSION": ModeTeamCity,
+	} {
+		t.Setenv(env, "true")
+		smartModeCache.Store(0)
+		if got := smartMode(ModeColorful); got != mode {
+			t.Fatalf("expect %v selected by %s, but got %v", mode, env, got)
+		}
+		if got := smartMode(ModeJSON); got != ModeJSON {
+			t.Fatalf("expect the explicit mode kept, but got %v", got)
+		}
+		t.Setenv(env, "")
+	}
+}
@@ -203,6 +203,7 @@ const (
 	levelFieldName     = "level"
 	callerFieldName    = "caller"
 	messageFieldName   = "msg"
+	loggerFieldName    = "logger"
 )
 
 // var errNotReady = errors.New("not ready") // here we just need a very simple error message object
@@ -0,0 +1,167 @@
+package slog
+
+import (
+	"strconv"
+)
+
+// DedupeMode is the strategy to handle the attributes with the same
+// key in a record, or in a group.
+//
+// The attributes of a record are collected in the order of their
+// precedence, from low to high: the values extracted from context
+// (see SetContextKeys), the attributes of the root logger, ..., of
+// the parent logger, of the logger itself, and the arguments of the
+// logging call. A later attribute overrides an earlier one with the
+// same key in DedupeLastWins.
+//
+// All strategies keep the order of the attributes, a duplicated key
+// is printed at the position of its first occurrence.
+type DedupeMode int
+
+const (
+	DedupeLastWins  DedupeMode = iota // the last value wins, so the call arguments override the logger attributes
+	DedupeFirstWins                   // the first value wins, so the logger attributes cannot be overridden
+	DedupeKeepAll                     // print all of the duplicated attributes
+	DedupeRename                      // print all, and rename the duplicates to "key#2", "key#3", ...
+)
+
+// WithDedupe sets the [DedupeMode] of a logger.
+func WithDedupe(mode DedupeMode) Opt {
+	return func(s *Entry) {
+		s.SetDedupe(mode)
+	}
+}
+
+// SetDedupe sets the [DedupeMode] of this logger, it is inherited by
+// the child loggers. The default is DedupeLastWins.
+func (s *Entry) SetDedupe(mode DedupeMode) *Entry {
+	s.dedupe = &mode
+	return s
+}
+
+// WithDedupe makes a child logger with the given [DedupeMode].
+func (s *Entry) WithDedupe(mode DedupeMode) (newLogger *Entry) {
+	return s.newChildLogger(WithDedupe(mode))
+}
+
+// Dedupe returns the [DedupeMode] of this logger or its parents.
+func (s *Entry) Dedupe() DedupeMode {
+	for p := s; p != nil; p = p.owner {
+		if p.dedupe != nil {
+			return *p.dedupe
+		}
+	}
+	return DedupeLastWins
+}
+
+// WithPCDedupe sets the [DedupeMode] of a standalone PrintCtx.
+func WithPCDedupe(mode DedupeMode) PCOpt {
+	return func(pc *PrintCtx) {
+		pc.dedupe = mode
+	}
+}
+
+// dedupeScanMax is the max number of attributes which are deduped by
+// scanning, without allocation. An index of the keys is built for the
+// larger ones.
+const dedupeScanMax = 32
+
+// keyIndex is the occurrences of a key in the attributes.
+type keyIndex struct {
+	first, last int // the indices of the first and the last occurrences
+	seen        int // the occurrences met so far, for DedupeRename
+}
+
+// attrDeduper decides the attributes to be serialized by a DedupeMode.
+// The attributes are never modified or copied.
+type attrDeduper struct {
+	mode  DedupeMode
+	kvps  Attrs
+	index map[string]*keyIndex
+}
+
+func newAttrDeduper(mode DedupeMode, kvps Attrs) attrDeduper {
+	d := attrDeduper{mode: mode, kvps: kvps}
+	if mode != DedupeKeepAll && len(kvps) > dedupeScanMax {
+		d.index = make(map[string]*keyIndex, len(kvps))
+		for i, a := range kvps {
+			if a == nil {
+				continue
+			}
+			if ki, ok := d.index[a.Key()]; ok {
+				ki.last = i
+			} else {
+				d.index[a.Key()] = &keyIndex{first: i, last: i}
+			}
+		}
+	}
+	return d
+}
+
+// next returns the key and the attribute to be serialized of the
+// attribute i, which must not be nil. It returns false if the
+// attribute is a duplicate to be skipped.
+func (d *attrDeduper) next(i int) (key string, a Attr, ok bool) {
+	a = d.kvps[i]
+	key = a.Key()
+	switch d.mode {
+	case DedupeKeepAll:
+		return key, a, true
+	case DedupeFirstWins:
+		return key, a, d.first(i, key) == i
+	case DedupeRename:
+		if n := d.seen(i, key); n > 1 {
+			key += "#" + strconv.Itoa(n)
+		}
+		return key, a, true
+	}
+	if d.first(i, key) != i {
+		return key, nil, false
+	}
+	return key, d.kvps[d.last(i, key)], true
+}
+
+// first returns the index of the first occurrence of key, i is an
+// occurrence of it.
+func (d *attrDeduper) first(i int, key string) int {
+	if d.index != nil {
+		return d.index[key].first
+	}
+	for j, a := range d.kvps[:i] {
+		if a != nil && a.Key() == key {
+			return j
+		}
+	}
+	return i
+}
+
+// last returns the index of the last occurrence of key, i is an
+// occurrence of it.
+func (d *attrDeduper) last(i int, key string) int {
+	if d.index != nil {
+		return d.index[key].last
+	}
+	for j := len(d.kvps) - 1; j > i; j-- {
+		if a := d.kvps[j]; a != nil && a.Key() == key {
+			return j
+		}
+	}
+	return i
+}
+
+// seen returns the occurrences of key till i, inclusive. It must be
+// called in the order of i.
+func (d *attrDeduper) seen(i int, key string) int {
+	if d.index != nil {
+		ki := d.index[key]
+		ki.seen++
+		return ki.seen
+	}
+	n := 1
+	for _, a := range d.kvps[:i] {
+		if a != nil && a.Key() == key {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,113 @@
+package slog
+
+import (
+	"bytes"
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestDedupeModes(t *testing.T) {
+	for _, c := range []struct {
+		mode DedupeMode
+		want string
+	}{
+		{DedupeLastWins, `"a":3,"b":5,"c":4`},
+		{DedupeFirstWins, `"a":1,"b":2,"c":4`},
+		{DedupeKeepAll, `"a":1,"b":2,"a":3,"c":4,"b":5`},
+		{DedupeRename, `"a":1,"b":2,"a#2":3,"c":4,"b#2":5`},
+	} {
+		// the small records are scanned, the large ones are indexed
+		for _, pad := range []int{0, dedupeScanMax} {
+			kvps := Attrs{NewAttr("a", 1), NewAttr("b", 2), nil, NewAttr("a", 3), NewAttr("c", 4), NewAttr("b", 5)}
+			for i := 0; i < pad; i++ {
+				kvps = append(kvps, NewAttr("p"+strconv.Itoa(i), i))
+			}
+			pc := NewPrintCtx(WithPCMode(ModeJSON), WithPCDedupe(c.mode))
+			pc.Begin()
+			_ = serializeAttrs(pc, kvps)
+			pc.End(false)
+			if got := pc.String(); !strings.HasPrefix(got, "{"+c.want+`,"p0":0`) && got != "{"+c.want+"}" {
+				t.Fatalf("%v with %d attrs: expect %s, but got %s", c.mode, len(kvps), c.want, got)
+			}
+		}
+	}
+}
+
+func TestDedupeGroups(t *testing.T) {
+	g := Attrs{NewAttr("x", 1), NewAttr("y", 2), NewAttr("x", 3)}
+	kvps := Attrs{NewGroupedAttr("g", g...), NewAttr("x", 0)}
+	for _, c := range []struct {
+		mode Mode
+		want string
+	}{
+		{ModeJSON, `{"g":{"x":3,"y":2},"x":0}`},
+		{ModeLogFmt, ` g.x=3 g.y=2 x=0`},
+	} {
+		pc := NewPrintCtx(WithPCMode(c.mode))
+		if c.mode == ModeJSON {
+			pc.Begin()
+		}
+		_ = serializeAttrs(pc, kvps)
+		if c.mode == ModeJSON {
+			pc.End(false)
+		}
+		if got := pc.String(); got != c.want {
+			t.Fatalf("%v: expect %s, but got %s", c.mode, c.want, got)
+		}
+	}
+	if g[0].Value() != 1 || g[2].Value() != 3 {
+		t.Fatalf("the attributes of group were modified: %v", g)
+	}
+}
+
+func TestDedupePrecedence(t *testing.T) {
+	defer SaveFlagsAndMod(LattrsR)()
+
+	ctx := context.WithValue(context.Background(), "k", "context") //nolint:staticcheck
+	for _, c := range []struct {
+		mode DedupeMode
+		want []string
+	}{
+		{DedupeLastWins, []string{`"k":"call"`, `"k":"child"`, `"k":"parent"`}},
+		{DedupeFirstWins, []string{`"k":"context"`, `"k":"context"`, `"k":"context"`}},
+		{DedupeRename, []string{`"k":"context","k#2":"parent","k#3":"child","k#4":"call"`}},
+	} {
+		var buf bytes.Buffer
+		parent := New("dedupe", WithWriter(&buf), WithLevel(InfoLevel), WithJSONMode(),
+			WithDedupe(c.mode), WithAttrs(NewAttr("k", "parent"))).(*logimp)
+		parent.SetContextKeys("k")
+		child := parent.New("child", WithWriter(&buf), WithJSONMode(), WithAttrs(NewAttr("k", "child")))
+		child.SetContextKeys("k")
+		bare := parent.New("bare", WithWriter(&buf), WithJSONMode()) // no attrs of its own
+		bare.SetContextKeys("k")
+
+		child.InfoContext(ctx, "call", "k", "call")
+		child.InfoContext(ctx, "child")
+		bare.InfoContext(ctx, "bare")
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		if len(lines) != 3 {
+			t.Fatalf("%v: expect 3 lines, but got %q", c.mode, buf.String())
+		}
+		for i, want := range c.want {
+			if !strings.Contains(lines[i], want) || (c.mode != DedupeRename && strings.Count(lines[i], `"k"`) != 1) {
+				t.Fatalf("%v: expect %s in line %d, but got %s", c.mode, want, i, lines[i])
+			}
+		}
+	}
+}
+
+func TestDedupeAllocs(t *testing.T) {
+	kvps := Attrs{NewAttr("a", "1"), NewAttr("b", true), NewAttr("a", "2"), NewAttr("c", "3")}
+	pc := NewPrintCtx(WithPCMode(ModeJSON))
+	for _, mode := range []DedupeMode{DedupeLastWins, DedupeFirstWins, DedupeKeepAll} {
+		pc.dedupe = mode
+		if n := testing.AllocsPerRun(100, func() {
+			pc.Reset()
+			_ = serializeAttrs(pc, kvps)
+		}); n != 0 {
+			t.Fatalf("%v: expect no allocation, but got %v", mode, n)
+		}
+	}
+}
@@ -0,0 +1,289 @@
+package slog
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+
+	errorsv3 "gopkg.in/hedzr/errors.v3"
+)
+
+// ECSVersion is the default version of Elastic Common Schema.
+const ECSVersion = "8.11.0"
+
+// ECSOptions configures the painter for Elastic Common Schema, see
+// NewECSPainter.
+type ECSOptions struct {
+	// Version is the ecs.version, default is ECSVersion.
+	Version string
+	// Namespace is the field which the attributes are nested under,
+	// default is "labels". As ECS labels are keyword to keyword, the
+	// groups are flattened by '_' into them, and the values are
+	// printed as strings.
+	Namespace string
+	// Custom is the field which the nested values, such as the maps,
+	// the slices and the structs, are put under, if Namespace is
+	// "labels". Default is "custom".
+	Custom string
+}
+
+// ModeECS prints the logging lines in the JSON format of Elastic
+// Common Schema, with the default ECSOptions.
+var ModeECS, _ = RegisterMode("ecs", func() Painter { return NewECSPainter(ECSOptions{}) })
+
+// NewECSPainter returns a [Painter] which prints the logging lines
+// in the JSON format of Elastic Common Schema:
+//
+//	{"@timestamp":"...","log":{"level":"info","logger":"app","origin":{"file":{"name":"main.go","line":12},"function":"main.main"}},
+//	 "message":"...","error":{"type":"...","message":"...","stack_trace":"..."},"ecs":{"version":"8.11.0"},
+//	 "labels":{"user":"u1","group_key":"1"},"custom":{"list":[1,2]}}
+//
+// The log.origin is printed if Lcaller is set. The first error in
+// the attributes is printed as the error field, with the stack
+// trace if it carries one (see gopkg.in/hedzr/errors.v3). The rest
+// attributes are nested under the namespace, see ECSOptions.
+func NewECSPainter(opts ECSOptions) Painter {
+	if opts.Version == "" {
+		opts.Version = ECSVersion
+	}
+	if opts.Namespace == "" {
+		opts.Namespace = "labels"
+	}
+	if opts.Custom == "" {
+		opts.Custom = "custom"
+	}
+	return &ecsPainter{opts: opts}
+}
+
+type ecsPainter struct {
+	jsonPainter
+	opts ECSOptions
+}
+
+var _ LinePainter = (*ecsPainter)(nil)
+
+// PaintLine implements [LinePainter].
+func (s *ecsPainter) PaintLine(pc *PrintCtx) (holdErrorValue error) {
+	pc.mode1 = ModeJSON
+
+	pc.AppendStringKey("@timestamp")
+	pc.AddColon()
+	s.AppendTime(pc, pc.now.UTC()) // the built-in field ignores Encoding
+
+	pc.AddComma()
+	pc.AppendStringKey("log")
+	pc.AddColon()
+	pc.Begin()
+	pc.AddString("level", pc.lvl.String())
+	if pc.name != "" {
+		pc.AddComma()
+		pc.AddString("logger", pc.name)
+	}
+	if src := pc.Source(); src != nil && IsAnyBitsSet(Lcaller) {
+		pc.AddComma()
+		pc.AppendStringKey("origin")
+		pc.AddColon()
+		pc.Begin()
+		pc.AppendStringKey("file")
+		pc.AddColon()
+		pc.Begin()
+		pc.AddString("name", src.File)
+		pc.AddComma()
+		pc.AddInt("line", src.Line)
+		pc.End(false)
+		pc.AddComma()
+		pc.AddString("function", src.Function)
+		pc.End(false)
+	}
+	pc.End(false)
+
+	pc.AddComma()
+	pc.AddString("message", pc.msg)
+
+	attrs := pc.kvps
+	for i, a := range attrs {
+		if a == nil {
+			continue
+		}
+		if err, ok := a.Value().(error); ok {
+			s.paintError(pc, err)
+			attrs = append(attrs[:i:i], attrs[i+1:]...)
+			break
+		}
+	}
+
+	pc.AddComma()
+	pc.AppendStringKey("ecs")
+	pc.AddColon()
+	pc.Begin()
+	pc.AddString("version", s.opts.Version)
+	pc.End(false)
+
+	namespace := s.opts.Namespace
+	if namespace == "labels" {
+		var labels int
+		attrs, holdErrorValue = s.paintLabels(pc, "", attrs, &labels)
+		if labels > 0 {
+			pc.End(false)
+		}
+		namespace = s.opts.Custom
+	}
+	if len(attrs) > 0 {
+		pc.AddComma()
+		pc.AppendStringKey(namespace)
+		pc.AddColon()
+		pc.Begin()
+		if err := serializeAttrs(pc, attrs); err != nil {
+			holdErrorValue = err
+		}
+		pc.End(false)
+	}
+	return
+}
+
+// paintLabels prints the attributes as the labels, the keys of the
+// groups are joined by '_', and the values are printed as strings.
+// labels counts the printed ones, the labels object is opened by the
+// first one. It returns the nested values in their groups, and the
+// last error value.
+func (s *ecsPainter) paintLabels(pc *PrintCtx, prefix string, attrs Attrs, labels *int) (nested Attrs, err error) {
+	pc.attrDepth++
+	defer func() { pc.attrDepth-- }()
+
+	dd := newAttrDeduper(pc.dedupe, attrs)
+	written := 0
+	for i, a := range attrs {
+		if a == nil {
+			continue
+		}
+		key, a, ok := dd.next(i)
+		if !ok {
+			continue // a duplicate
+		}
+		val := Value{any: a.Value()}
+		if tv, typed := a.(valueAttr); typed {
+			val = tv.TypedValue()
+		}
+		if more := pc.attrsOver(i, len(attrs), written); more > 0 {
+			key, val = "…", StringValue(moreMarker(more, "attrs"))
+		}
+		written++
+
+		if g, ok := val.group(); ok {
+			if pc.groupTooDeep() {
+				val = StringValue(moreMarker(len(g), "attrs"))
+			} else {
+				n, e := s.paintLabels(pc, ecsLabelKey(prefix, key), g, labels)
+				if len(n) > 0 {
+					nested = append(nested, NewGroupedAttr(key, n...))
+				}
+				if e != nil {
+					err = e
+				}
+				continue
+			}
+		} else if ecsNested(val) {
+			nested = append(nested, a)
+			continue
+		}
+
+		if *labels == 0 {
+			pc.AddComma()
+			pc.AppendStringKey(s.opts.Namespace)
+			pc.AddColon()
+			pc.Begin()
+		} else {
+			pc.AddComma()
+		}
+		*labels++
+		if e, ok := val.any.(error); ok && val.typ == TypeAny && e != nil {
+			err, val = e, StringValue(e.Error())
+		}
+		pc.AppendStringKey(ecsLabelKey(prefix, key))
+		pc.AddColon()
+		start := len(pc.buf)
+		pc.appendTypedValue(val)
+		if text := pc.buf[start:]; len(text) == 0 || text[0] != '"' {
+			str := string(text) // a keyword
+			pc.buf = pc.buf[:start]
+			pc.AppendQuotedString(str)
+		}
+		if key == "…" {
+			break
+		}
+	}
+	return
+}
+
+func ecsLabelKey(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "_" + key
+}
+
+// ecsNested reports whether val is a nested value, which cannot be a
+// label.
+func ecsNested(val Value) bool {
+	if val.typ != TypeAny {
+		return false
+	}
+	switch val.any.(type) {
+	case nil, error, Stringer, ToString, encoding.TextMarshaler, time.Time, []byte:
+		return false
+	case ObjectMarshaller, ArrayMarshaller, ObjectSerializer:
+		return true
+	}
+	v := reflect.ValueOf(val.any)
+	for v.Kind() == reflect.Pointer && !v.IsNil() {
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array:
+		return true
+	}
+	return false
+}
+
+func (s *ecsPainter) paintError(pc *PrintCtx, err error) {
+	pc.AddComma()
+	pc.AppendStringKey("error")
+	pc.AddColon()
+	pc.Begin()
+	pc.AddString("type", fmt.Sprintf("%T", err))
+	pc.AddComma()
+	pc.AddString("message", err.Error())
+	var f *errorsv3.WithStackInfo
+	if errors.As(err, &f) {
+		if st := f.StackTrace(); len(st) > 0 {
+			pc.AddComma()
+			pc.AddString("stack_trace", stackTraceText(pc, st))
+		}
+	}
+	pc.End(false)
+}
+
+// stackTraceText returns the frames of st, one frame in two lines as
+// the traces of a panic:
+//
+//	function
+//		file:line
+func stackTraceText(pc *PrintCtx, st errorsv3.StackTrace) string {
+	var b []byte
+	for _, frame := range st {
+		src := pc.cachedSource.Extract(uintptr(frame))
+		if src.Function == "" && src.File == "" {
+			continue
+		}
+		b = append(b, src.Function...)
+		b = append(b, "\n\t"...)
+		b = append(b, src.File...)
+		b = append(b, ':')
+		b = strconv.AppendInt(b, int64(src.Line), 10)
+		b = append(b, '\n')
+	}
+	return string(b)
+}
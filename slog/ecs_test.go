@@ -0,0 +1,81 @@
+package slog
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"runtime"
+	"strings"
+	"testing"
+
+	errorsv3 "gopkg.in/hedzr/errors.v3"
+)
+
+func TestECSPainter(t *testing.T) {
+	defer SaveFlagsAndMod(Lcaller)()
+
+	var buf bytes.Buffer
+	l := New("ecs", WithWriter(&buf), WithErrorWriter(&buf), WithLevel(InfoLevel), WithMode(ModeECS))
+	_, _, line, _ := runtime.Caller(0)
+	l.Error("failed", "user", "u1", "err", errorsv3.New("boom"), Group("g", "x", 1, "m", map[string]int{"a": 2}),
+		"err2", errors.New("plain"), "list", []int{1, 2})
+	t.Log(buf.String())
+
+	var m map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &m); err != nil {
+		t.Fatalf("bad json %q: %v", buf.String(), err)
+	}
+	get := func(path string) any {
+		var v any = m
+		for _, k := range strings.Split(path, ".") {
+			mm, _ := v.(map[string]any)
+			v = mm[k]
+		}
+		return v
+	}
+	for path, want := range map[string]any{
+		"log.level":            "error",
+		"log.logger":           "ecs",
+		"log.origin.file.line": float64(line + 1),
+		"log.origin.function":  "github.com/hedzr/logg/slog.TestECSPainter",
+		"message":              "failed",
+		"error.message":        "boom",
+		"ecs.version":          ECSVersion,
+		"labels.user":          "u1",
+		"labels.g_x":           "1",
+		"labels.err2":          "plain",
+		"custom.g.m.a":         float64(2),
+	} {
+		if get(path) != want {
+			t.Fatalf("expect %q = %v, but got %v", path, want, get(path))
+		}
+	}
+	if !strings.HasSuffix(get("log.origin.file.name").(string), "ecs_test.go") {
+		t.Fatalf("bad log.origin.file.name: %v", get("log.origin.file.name"))
+	}
+	if l, ok := get("custom.list").([]any); !ok || len(l) != 2 || get("labels.list") != nil || get("labels.g") != nil {
+		t.Fatalf("expect the nested values under custom, but got %q", buf.String())
+	}
+	if get("labels.err") != nil || get("labels.err2") == nil {
+		t.Fatalf("expect the first error moved to error field only, but got %v", m["labels"])
+	}
+	if st, _ := get("error.stack_trace").(string); !strings.Contains(st, "ecs_test.go") ||
+		!strings.HasPrefix(st, "github.com/hedzr/logg/slog.TestECSPainter\n\t") || strings.Contains(st, "boom") {
+		t.Fatalf("expect the frames of the stack trace only, but got %q", st)
+	}
+	if typ, _ := get("error.type").(string); typ == "" {
+		t.Fatal("expect error.type")
+	}
+
+	// custom namespace and version, no error
+	buf.Reset()
+	l = New(WithWriter(&buf), WithLevel(InfoLevel), WithPainter(NewECSPainter(ECSOptions{Version: "1.0", Namespace: "app"})))
+	l.Info("hi", "a", 1)
+	m = nil
+	if err := json.Unmarshal(buf.Bytes(), &m); err != nil {
+		t.Fatalf("bad json %q: %v", buf.String(), err)
+	}
+	if get("app.a") != float64(1) || get("ecs.version") != "1.0" || m["error"] != nil || m["labels"] != nil {
+		t.Fatalf("unexpected %q", buf.String())
+	}
+}
@@ -0,0 +1,214 @@
+package slog
+
+import (
+	"encoding/base64"
+	enchex "encoding/hex"
+	"strconv"
+	"time"
+
+	"github.com/hedzr/logg/slog/internal/times"
+)
+
+// Encoding tells how the values of some types are encoded, like the
+// EncoderConfig of zap. The zero value keeps the default encodings of
+// the painters.
+//
+// The encodings apply to the attribute values in all painters, the
+// built-in fields, such as the timestamp, are kept.
+type Encoding struct {
+	Bytes          BytesEncoding
+	Duration       DurationEncoding
+	Time           TimeEncoding
+	FloatPrecision int // the digits after the decimal point, 0 means the shortest representation
+}
+
+// BytesEncoding is the encoding of []byte values.
+type BytesEncoding int
+
+const (
+	BytesRaw    BytesEncoding = iota // the raw bytes in the text modes, a byte string of binary formats, or base64 in JSON as encoding/json
+	BytesBase64                      // a string of standard base64
+	BytesHex                         // a string of hex digits
+	BytesQuoted                      // a quoted string of the bytes as text
+	BytesLength                      // the length only, such as "<12 bytes>"
+)
+
+// DurationEncoding is the encoding of time.Duration values.
+type DurationEncoding int
+
+const (
+	DurationString  DurationEncoding = iota // time.Duration.String, such as "1m30s"
+	DurationSmart                           // a string with days, such as "3d1h"
+	DurationSeconds                         // a float number of seconds
+	DurationNanos                           // an integer of nanoseconds
+)
+
+// TimeEncoding is the encoding of time.Time values.
+type TimeEncoding int
+
+const (
+	TimeDefault     TimeEncoding = iota // the default of the painter, RFC3339Nano or a time value of binary formats
+	TimeRFC3339Nano                     // a string of time.RFC3339Nano
+	TimeUnix                            // an integer of Unix epoch seconds
+	TimeUnixMilli                       // an integer of Unix epoch milliseconds
+	TimeUnixNano                        // an integer of Unix epoch nanoseconds
+)
+
+// WithEncoding sets the value [Encoding] of a logger.
+//
+//	logger := slog.New(slog.WithEncoding(slog.Encoding{
+//		Bytes:          slog.BytesBase64,
+//		Duration:       slog.DurationSeconds,
+//		Time:           slog.TimeUnixMilli,
+//		FloatPrecision: 3,
+//	}))
+func WithEncoding(enc Encoding) Opt {
+	return func(s *Entry) {
+		s.SetEncoding(enc)
+	}
+}
+
+// SetEncoding sets the value [Encoding] of this logger, it is
+// inherited by the child loggers.
+func (s *Entry) SetEncoding(enc Encoding) *Entry {
+	s.encoding = &enc
+	return s
+}
+
+// WithEncoding makes a child logger with the given [Encoding].
+func (s *Entry) WithEncoding(enc Encoding) (newLogger *Entry) {
+	return s.newChildLogger(WithEncoding(enc))
+}
+
+// Encoding returns the value [Encoding] of this logger or its parents.
+func (s *Entry) Encoding() Encoding {
+	for p := s; p != nil; p = p.owner {
+		if p.encoding != nil {
+			return *p.encoding
+		}
+	}
+	return Encoding{}
+}
+
+// WithPCEncoding sets the value encoding of a standalone PrintCtx.
+func WithPCEncoding(enc Encoding) PCOpt {
+	return func(pc *PrintCtx) {
+		pc.encoding = enc
+	}
+}
+
+// appendBytesValue writes a []byte value in according to the
+// encoding and the limits.
+func (s *PrintCtx) appendBytesValue(z []byte) {
+	enc := s.encoding.Bytes
+	if enc == BytesRaw && s.mode1 == ModeJSON && s.bin == nil {
+		enc = BytesBase64 // the raw bytes would break the JSON
+	}
+	switch enc {
+	case BytesBase64, BytesHex:
+		var more int
+		if limit := s.stringLimit(); limit >= 0 && s.attrDepth > 0 {
+			if enc == BytesHex {
+				limit /= 2
+			} else {
+				limit = limit / 4 * 3
+			}
+			if len(z) > limit {
+				z, more = z[:limit], len(z)-limit
+			}
+		}
+		var text string
+		if enc == BytesHex {
+			text = enchex.EncodeToString(z)
+		} else {
+			text = base64.StdEncoding.EncodeToString(z)
+		}
+		if more > 0 {
+			text += moreMarker(more, "bytes")
+		}
+		s.AppendQuotedString(text)
+	case BytesQuoted:
+		s.AppendQuotedString(s.limitString(string(z)))
+	case BytesLength:
+		s.AppendQuotedString("<" + strconv.Itoa(len(z)) + " bytes>")
+	default:
+		z = s.limitBytes(z)
+		if s.bin != nil {
+			s.bin.appendBytes(s, z)
+			return
+		}
+		s.AppendBytes(z)
+	}
+}
+
+// appendDurationValue writes a time.Duration value in according to
+// the encoding, it returns false for the default encoding.
+func (s *PrintCtx) appendDurationValue(z time.Duration) bool {
+	switch s.encoding.Duration {
+	case DurationSmart:
+		s.AppendQuotedString(times.SmartDurationString(z))
+	case DurationSeconds:
+		ftoaS(s, z.Seconds())
+	case DurationNanos:
+		itoaS(s, int64(z))
+	default:
+		return false
+	}
+	return true
+}
+
+// appendTimeValue writes a time.Time value in according to the
+// encoding, it returns false for the default encoding.
+func (s *PrintCtx) appendTimeValue(z time.Time) bool {
+	switch s.encoding.Time {
+	case TimeRFC3339Nano:
+		s.AppendQuotedString(z.Format(time.RFC3339Nano))
+	case TimeUnix:
+		itoaS(s, z.Unix())
+	case TimeUnixMilli:
+		itoaS(s, z.UnixMilli())
+	case TimeUnixNano:
+		itoaS(s, z.UnixNano())
+	default:
+		return false
+	}
+	return true
+}
+
+// floatPrecision returns the precision for strconv.AppendFloat.
+func (s *PrintCtx) floatPrecision() int {
+	if p := s.encoding.FloatPrecision; p > 0 {
+		return p
+	}
+	return -1
+}
+
+// roundFloat rounds v to prec digits after the decimal point, in the
+// same way as strconv.FormatFloat.
+func roundFloat(v float64, prec int) float64 {
+	var buf [64]byte
+	r, err := strconv.ParseFloat(string(strconv.AppendFloat(buf[:0], v, 'f', prec, 64)), 64)
+	if err != nil {
+		return v
+	}
+	return r
+}
+
+// sliceTo writes a slice by appendValue, in the text formats or the
+// binary formats.
+func sliceTo[S ~[]E, E any](s *PrintCtx, val S, appendValue func(v E)) {
+	if s.bin != nil {
+		binSliceTo(s, val, appendValue)
+		return
+	}
+	keep, more := s.SliceLimit(len(val))
+	s.buf = append(s.buf, '[')
+	for i, v := range val[:keep] {
+		if i > 0 {
+			s.buf = append(s.buf, ',')
+		}
+		appendValue(v)
+	}
+	s.appendMoreItems(keep > 0, more)
+	s.buf = append(s.buf, ']')
+}
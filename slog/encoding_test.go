@@ -0,0 +1,206 @@
+package slog
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEncodingValues(t *testing.T) {
+	tm := time.Date(2024, 5, 6, 7, 8, 9, 500_000_000, time.UTC)
+	raw := []byte("hi\x00")
+	for i, c := range []struct {
+		enc  Encoding
+		val  any
+		want string
+	}{
+		{Encoding{}, raw, `"v":"aGkA"`},
+		{Encoding{}, struct{ B []byte }{[]byte("xy")}, `"v":{"B":"eHk="}`},
+		{Encoding{Bytes: BytesBase64}, raw, `"v":"aGkA"`},
+		{Encoding{Bytes: BytesHex}, raw, `"v":"686900"`},
+		{Encoding{Bytes: BytesQuoted}, raw, `"v":"hi\u0000"`},
+		{Encoding{Bytes: BytesLength}, raw, `"v":"<3 bytes>"`},
+		{Encoding{}, 26 * time.Hour, `"v":"26h0m0s"`},
+		{Encoding{Duration: DurationSmart}, 26 * time.Hour, `"v":"1d2h"`},
+		{Encoding{Duration: DurationSeconds}, 1500 * time.Millisecond, `"v":"1.5"`},
+		{Encoding{Duration: DurationNanos}, time.Microsecond, `"v":1000`},
+		{Encoding{Duration: DurationNanos}, []time.Duration{1, 2}, `"v":[1,2]`},
+		{Encoding{}, tm, `"v":"2024-05-06T07:08:09.5Z"`},
+		{Encoding{Time: TimeRFC3339Nano}, tm, `"v":"2024-05-06T07:08:09.5Z"`},
+		{Encoding{Time: TimeUnix}, tm, `"v":1714979289`},
+		{Encoding{Time: TimeUnixMilli}, tm, `"v":1714979289500`},
+		{Encoding{Time: TimeUnixNano}, tm, `"v":1714979289500000000`},
+		{Encoding{Time: TimeUnixMilli}, []time.Time{tm, tm}, `"v":[1714979289500,1714979289500]`},
+		{Encoding{}, 3.14159, `"v":"3.14159"`},
+		{Encoding{FloatPrecision: 2}, 3.14159, `"v":"3.14"`},
+		{Encoding{FloatPrecision: 2}, []float64{1, 2.345}, `"v":["1.00","2.35"]`},
+	} {
+		pc := NewPrintCtx(WithPCMode(ModeJSON), WithPCEncoding(c.enc))
+		pc.AddAny("v", c.val)
+		if got := pc.String(); got != c.want {
+			t.Fatalf("%d. expect %q, but got %q", i, c.want, got)
+		}
+	}
+}
+
+func TestEncodingPainters(t *testing.T) {
+	enc := Encoding{
+		Bytes:          BytesHex,
+		Duration:       DurationSmart,
+		Time:           TimeUnixMilli,
+		FloatPrecision: 1,
+	}
+	tm := time.Date(2024, 5, 6, 7, 8, 9, 500_000_000, time.UTC)
+	for _, opt := range []Opt{
+		WithJSONMode(),
+		WithMode(ModeLogFmt),
+		WithMode(ModePlain),
+		WithColorMode(),
+		WithPainter(MustLayoutPainter("{level} {msg} {attrs}")),
+		WithMode(ModeGELF),
+	} {
+		var buf bytes.Buffer
+		l := New("encoding", WithWriter(&buf), WithLevel(InfoLevel), opt, WithEncoding(enc))
+		l.Info("hello", "b", []byte{0xca, 0xfe}, "d", 49*time.Hour, "t", tm, "f", 2.25)
+		out := buf.String()
+		for _, want := range []string{"cafe", "2d1h", "1714979289500", "2.2"} {
+			if !strings.Contains(out, want) {
+				t.Fatalf("expect %q in output, but got %q", want, out)
+			}
+		}
+		if strings.Contains(out, "2024-05-06T07:08:09") || strings.Contains(out, "2.25") {
+			t.Fatalf("expect the encoded values, but got %q", out)
+		}
+	}
+}
+
+func TestEncodingBytesDefault(t *testing.T) {
+	for _, c := range []struct {
+		opt  Opt
+		want string
+	}{
+		{WithJSONMode(), `"b":"eCB5InoA","s":{"B":"eHk="}`},
+		{WithMode(ModeLogFmt), ` b="x y\"z\u0000" s.B=xy `},
+		{WithMode(ModePlain), ` b=x y"z` + "\x00" + ` s.B=xy `},
+	} {
+		var buf bytes.Buffer
+		l := New("encoding", WithWriter(&buf), WithLevel(InfoLevel), c.opt)
+		l.Info("hello", "b", []byte("x y\"z\x00"), "s", struct{ B []byte }{[]byte("xy")})
+		if out := buf.String(); !strings.Contains(out, c.want) {
+			t.Fatalf("expect %q in output, but got %q", c.want, out)
+		}
+	}
+
+	var buf bytes.Buffer
+	l := New("encoding", WithWriter(&buf), WithLevel(InfoLevel), WithJSONMode())
+	l.Info("hello", "b", []byte("x y\"z"))
+	var m map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &m); err != nil || m["b"] != "eCB5Ino=" {
+		t.Fatalf("expect base64 in valid JSON, but got %q: %v", buf.String(), err)
+	}
+}
+
+func TestEncodingBinary(t *testing.T) {
+	enc := Encoding{
+		Bytes:          BytesBase64,
+		Duration:       DurationNanos,
+		Time:           TimeUnix,
+		FloatPrecision: 2,
+	}
+	tm := time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)
+	for _, c := range []struct {
+		mode   Mode
+		toJSON func(w io.Writer, r io.Reader) error
+	}{
+		{ModeCBOR, CBORToJSON},
+		{ModeMsgPack, MsgPackToJSON},
+	} {
+		var buf bytes.Buffer
+		l := New("encoding", WithWriter(&buf), WithLevel(InfoLevel), WithMode(c.mode), WithEncoding(enc))
+		l.Info("hello", "b", []byte("hi"), "d", time.Second, "t", tm, "f", 3.14159)
+
+		var out bytes.Buffer
+		if err := c.toJSON(&out, &buf); err != nil {
+			t.Fatalf("%v: decode failed: %v, got %q", c.mode, err, out.String())
+		}
+		var m map[string]any
+		if err := json.Unmarshal(out.Bytes(), &m); err != nil {
+			t.Fatalf("%v: bad json %q: %v", c.mode, out.String(), err)
+		}
+		for k, v := range map[string]any{
+			"b": "aGk=",
+			"d": float64(time.Second),
+			"t": float64(1714979289),
+			"f": 3.14,
+		} {
+			if m[k] != v {
+				t.Fatalf("%v: expect %s = %v, but got %v in %q", c.mode, k, v, m[k], out.String())
+			}
+		}
+		if _, ok := m["time"].(string); !ok {
+			t.Fatalf("%v: expect the built-in timestamp kept, but got %q", c.mode, out.String())
+		}
+	}
+}
+
+func TestEncodingGELF(t *testing.T) {
+	enc := Encoding{
+		Bytes:          BytesHex,
+		Duration:       DurationNanos,
+		Time:           TimeUnix,
+		FloatPrecision: 2,
+	}
+	tm := time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)
+	var buf bytes.Buffer
+	l := New("encoding", WithWriter(&buf), WithLevel(InfoLevel), WithMode(ModeGELF), WithEncoding(enc))
+	l.Info("hello", "b", []byte{1, 2, 255}, "d", 1500*time.Millisecond, "t", tm, "f", 3.14159)
+
+	var m map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &m); err != nil {
+		t.Fatalf("bad json %q: %v", buf.String(), err)
+	}
+	for k, v := range map[string]any{
+		"_b": "0102ff",
+		"_d": float64(1500 * time.Millisecond),
+		"_t": float64(1714979289),
+		"_f": 3.14,
+	} {
+		if m[k] != v {
+			t.Fatalf("expect %s = %v, but got %v in %q", k, v, m[k], buf.String())
+		}
+	}
+
+	// the seconds are a number too
+	buf.Reset()
+	l = New("encoding", WithWriter(&buf), WithLevel(InfoLevel), WithMode(ModeGELF),
+		WithEncoding(Encoding{Duration: DurationSeconds}))
+	l.Info("hello", "d", 1500*time.Millisecond)
+	if err := json.Unmarshal(buf.Bytes(), &m); err != nil || m["_d"] != 1.5 {
+		t.Fatalf("expect the seconds, but got %q: %v", buf.String(), err)
+	}
+}
+
+func TestEncodingLimits(t *testing.T) {
+	pc := NewPrintCtx(WithPCMode(ModeJSON), WithPCEncoding(Encoding{Bytes: BytesHex}),
+		WithPCLimits(Limits{MaxStringLen: 8}))
+	pc.attrDepth = 1 // as an attribute value
+	pc.AddAny("v", []byte("0123456789"))
+	if got, want := pc.String(), `"v":"30313233…(+6 bytes)"`; got != want {
+		t.Fatalf("expect %q, but got %q", want, got)
+	}
+}
+
+func TestEncodingInherit(t *testing.T) {
+	parent := New("encoding", WithEncoding(Encoding{Time: TimeUnix})).(*logimp)
+	child := parent.New("child")
+	if got := child.Encoding(); got.Time != TimeUnix {
+		t.Fatalf("expect the encoding of parent, but got %+v", got)
+	}
+	child.SetEncoding(Encoding{Bytes: BytesHex})
+	if got := child.Encoding(); got.Time != TimeDefault || got.Bytes != BytesHex {
+		t.Fatalf("expect the encoding of child, but got %+v", got)
+	}
+}
@@ -93,6 +93,17 @@ type Entry struct {
 	extraFrames   int
 	contextKeys   []any
 	painter       Painter
+	recorders     []Recorder
+	middlewares   []Middleware
+	hookset       atomic.Pointer[hookSet] // the hooks and written callbacks, copy-on-write
+	fieldNames    *FieldNames
+	theme         *Theme
+	redactRules   []*redactRule
+	limits        *Limits
+	encoding      *Encoding
+	dedupe        *DedupeMode
+	modeWriters   []*modeWriter
+	pipe          atomic.Pointer[pipeline]
 
 	muWrite writeLock
 }
@@ -1094,7 +1105,8 @@ func (s *Entry) writeInternal(ctx context.Context, lvl Level, stackFrame uintptr
 //
 
 var poolAttrs = sync.Pool{New: func() any {
-	return newFixedAttrs()
+	kvps := newFixedAttrs()
+	return &kvps
 
 	// return &PrintCtx{
 	// 	buf:      make([]byte, 0, 1024),
@@ -1117,7 +1129,7 @@ func (s *Entry) collectArgs(ctx context.Context, kvps *Attrs, roughSize int, lvl
 	if s.ctxKeysWanted() {
 		s.fromCtx(ctx, kvps)
 	}
-	if len(s.attrs) > 0 {
+	if len(s.attrs) > 0 || IsAnyBitsSet(LattrsR) {
 		s.walkParentAttrs(ctx, lvl, s, kvps)
 	}
 	if len(args) > 0 {
@@ -1268,7 +1280,8 @@ func (s *Entry) logContext(ctx context.Context, lvl Level, isformat bool, stackF
 	if roughSize > int(atomic.LoadInt32(&fixedSize)) && roughSize < maxFixedSize {
 		atomic.StoreInt32(&fixedSize, int32(roughSize))
 	}
-	kvps = poolAttrs.Get().(Attrs)
+	pkvps := poolAttrs.Get().(*Attrs)
+	kvps = *pkvps
 	// kvps = make(Attrs, 0, roughSize) // pre-allocate slice spaces roughly
 	// }
 
@@ -1282,10 +1295,16 @@ func (s *Entry) logContext(ctx context.Context, lvl Level, isformat bool, stackF
 	s.print(ctx, lvl, now, stackFrame, msg, kvps)
 
 	// if kvps != nil {
-	kvps = kvps[:0]     // keep array cap but set slice to empty
-	poolAttrs.Put(kvps) // and return it for next request
+	*pkvps = kvps[:0]    // keep array cap but set slice to empty
+	poolAttrs.Put(pkvps) // and return it for next request, by pointer to avoid boxing
 	// }
 
+	interrupt(lvl, msg)
+}
+
+// interrupt panics or exits after a record of PanicLevel or FatalLevel
+// is logged.
+func interrupt(lvl Level, msg string) {
 	if !inTesting || IsAnyBitsSet(Linterruptalways) {
 		if IsAllBitsSet(LnoInterrupt) {
 			return
@@ -1310,6 +1329,15 @@ func (s *Entry) findWriter(lvl Level) (lw LogWriter) {
 	return
 }
 
+// writersOf returns the writers of lvl, it is findWriter without
+// boxing the LWs in a LogWriter, for printOut.
+func (s *Entry) writersOf(lvl Level) LWs {
+	if s.writer != nil {
+		return s.writer.Get(lvl)
+	}
+	return defaultWriter.Get(lvl)
+}
+
 var inTesting = is.InTesting()
 var inBenching = is.InBenchmark()
 var isDebuggingOrBuild = is.InDebugging()
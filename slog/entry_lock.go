@@ -3,25 +3,26 @@
 
 package slog
 
+import "sync"
+
 type writeLock struct {
 	mu sync.Mutex
 }
 
-func (s *Entry) printOut(lvl Level, msg []byte) {
-	if w := s.findWriter(lvl); w != nil {
-		s.muWrite.Lock()
-		defer s.muWrite.Unlock()
+func (s *Entry) printOut(lvl Level, msg []byte) (n int, err error) {
+	if w := s.writersOf(lvl); w != nil {
+		s.muWrite.mu.Lock()
 
 		// if a target user-defined writer can be SetLevel, set it before writing.
-		if x, ok := w.(LevelSettable); ok {
-			x.SetLevel(lvl)
-		}
+		w.setLevel(lvl)
 
-		n, err := w.Write(msg)
+		n, err = w.Write(msg)
+		s.muWrite.mu.Unlock() // unlock before warning to avoid deadlock
 		collectWrittenBytes(n)
 
 		if err != nil && lvl != WarnLevel { // don't warn on warning to avoid infinite calls
 			s.Warn("slog print log failed", "error", err)
 		}
 	}
+	return
 }
@@ -0,0 +1,244 @@
+package slog
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Event is a logging record built by the fluent calls, like zerolog:
+//
+//	logger.InfoEvent().Str("user", u).Int("n", n).Err(err).Dur("took", d).Msg("done")
+//
+// The fields are written at once into a pooled PrintCtx by the
+// painter, without building the Attrs, and the output is the same as
+// logger.Info("done", "user", u, "n", n, "error", err, "took", d).
+//
+// The Event of a disabled level is nil, whose methods do nothing. An
+// Event is pooled, it must not be used after Msg, Msgf or Send.
+type Event struct {
+	s      *Entry
+	ctx    context.Context
+	lvl    Level
+	frame  uintptr   // the caller's stack frame
+	pc     *PrintCtx // the fields written, nil if the record must be built as usual
+	fields []vkvp    // the fields, for building the record
+	err    error     // the last error value of the fields
+}
+
+var poolEvent = sync.Pool{New: func() any {
+	return &Event{fields: make([]vkvp, 0, 16)}
+}}
+
+// Event starts an [Event] of the given level, it returns nil if the
+// level is disabled.
+func (s *Entry) Event(ctx context.Context, lvl Level) *Event {
+	if !s.EnabledContext(ctx, lvl) {
+		return nil
+	}
+	return s.newEvent(ctx, lvl, getpc(2, s.extraFrames))
+}
+
+func (s *Entry) TraceEvent() *Event { return s.levelEvent(TraceLevel) } // starts an Event of TraceLevel, see Event.
+func (s *Entry) DebugEvent() *Event { return s.levelEvent(DebugLevel) } // starts an Event of DebugLevel, see Event.
+func (s *Entry) InfoEvent() *Event  { return s.levelEvent(InfoLevel) }  // starts an Event of InfoLevel, see Event.
+func (s *Entry) WarnEvent() *Event  { return s.levelEvent(WarnLevel) }  // starts an Event of WarnLevel, see Event.
+func (s *Entry) ErrorEvent() *Event { return s.levelEvent(ErrorLevel) } // starts an Event of ErrorLevel, see Event.
+
+func (s *Entry) levelEvent(lvl Level) *Event {
+	ctx := context.Background()
+	if !s.EnabledContext(ctx, lvl) {
+		return nil
+	}
+	return s.newEvent(ctx, lvl, getpc(3, s.extraFrames)) // caller -> InfoEvent -> levelEvent
+}
+
+func (s *Entry) newEvent(ctx context.Context, lvl Level, frame uintptr) *Event {
+	if s.handlerOpt != nil {
+		return nil // nothing is logged, same as logContext
+	}
+	if ctx == nil {
+		ctx = context.TODO()
+	}
+
+	e := poolEvent.Get().(*Event)
+	e.s, e.ctx, e.lvl, e.frame = s, ctx, lvl, frame
+	if s.eventDirect() {
+		e.pc = poolPrintCtx.Get().(*PrintCtx)
+		if !e.pc.beginEvent(s, lvl) {
+			e.pc.putBack()
+			e.pc = nil
+		}
+	}
+	return e
+}
+
+// eventDirect reports whether the fields of an Event can be written at
+// once. The records to be handled as a whole, by the hooks, the
+// redactions, the recorders, the limits of attributes, and so on, are
+// built as usual. The painter is checked by beginEvent.
+func (s *Entry) eventDirect() bool {
+	if s.ctxKeysWanted() || IsAnyBitsSet(LattrsR) || len(s.modeWriters) > 0 ||
+		s.hooked() || s.pipeline() != nil || len(s.redactors()) > 0 {
+		return false
+	}
+	for p := s; p != nil; p = p.owner {
+		if len(p.recorders) > 0 || len(p.hooks().writtenFns) > 0 {
+			return false
+		}
+	}
+	l := s.Limits()
+	return l.MaxAttrs == 0 && l.MaxLineBytes == 0
+}
+
+// beginEvent prepares pc for writing the fields of an Event, in the
+// same state as serializeAttrs in render. It returns false if the
+// painter, which may come from the mode or the parent logger, doesn't
+// print the attributes by serializeAttrs, such as the ones of ECS and
+// GELF, which pick the attributes.
+func (s *PrintCtx) beginEvent(e *Entry, lvl Level) bool {
+	s.set(e, lvl, time.Time{}, 0, "", nil)
+	switch s.ip.(type) {
+	case *colorfulPainter, *logfmtPainter, *jsonPainter, *binaryPainter, *LayoutPainter:
+	default:
+		return false
+	}
+	if _, painting := s.ip.(LinePainter); painting || s.IsColorStyle() {
+		s.SetupColors()
+	}
+	if s.bin != nil {
+		s.mode1 = ModeJSON // as binaryPainter.PaintLine
+		s.valueStringer = nil
+		s.binFrames = append(s.binFrames, binFrame{}) // counts the fields
+	}
+	s.attrDepth = 1
+	return true
+}
+
+func (e *Event) Str(key, val string) *Event               { return e.add(key, StringValue(val)) }   // adds a string field.
+func (e *Event) Bool(key string, val bool) *Event         { return e.add(key, BoolValue(val)) }     // adds a bool field.
+func (e *Event) Int(key string, val int) *Event           { return e.add(key, IntValue(val)) }      // adds an int field.
+func (e *Event) Int64(key string, val int64) *Event       { return e.add(key, Int64Value(val)) }    // adds an int64 field.
+func (e *Event) Uint64(key string, val uint64) *Event     { return e.add(key, Uint64Value(val)) }   // adds an uint64 field.
+func (e *Event) Float64(key string, val float64) *Event   { return e.add(key, Float64Value(val)) }  // adds a float64 field.
+func (e *Event) Dur(key string, val time.Duration) *Event { return e.add(key, DurationValue(val)) } // adds a time.Duration field.
+func (e *Event) Time(key string, val time.Time) *Event    { return e.add(key, TimeValue(val)) }     // adds a time.Time field.
+func (e *Event) Any(key string, val any) *Event           { return e.add(key, Value{any: val}) }    // adds a field of any value.
+
+// Attr adds an attribute as a field.
+func (e *Event) Attr(a Attr) *Event {
+	if e == nil || a == nil {
+		return e
+	}
+	if tv, ok := a.(valueAttr); ok {
+		return e.add(a.Key(), tv.TypedValue())
+	}
+	return e.add(a.Key(), Value{any: a.Value()})
+}
+
+// Err adds an "error" field if err is not nil.
+func (e *Event) Err(err error) *Event {
+	if err == nil {
+		return e
+	}
+	return e.add("error", Value{any: err})
+}
+
+func (e *Event) add(key string, val Value) *Event {
+	if e == nil {
+		return e
+	}
+	if e.pc != nil && e.duplicated(key) {
+		e.pc.putBack() // the duplicated keys are deduped in the record
+		e.pc = nil
+	}
+	e.fields = append(e.fields, vkvp{key, val})
+	if e.pc != nil {
+		if err := e.pc.appendAttr(key, val); err != nil {
+			e.err = err
+		}
+	}
+	return e
+}
+
+// duplicated reports whether key is used by the logger or the fields,
+// which has to be deduped.
+func (e *Event) duplicated(key string) bool {
+	if e.pc.dedupe == DedupeKeepAll {
+		return false
+	}
+	for _, a := range e.s.attrs {
+		if a != nil && a.Key() == key {
+			return true
+		}
+	}
+	for i := range e.fields {
+		if e.fields[i].key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// Msg logs the Event with msg, the Event must not be used after.
+func (e *Event) Msg(msg string) {
+	if e == nil {
+		return
+	}
+	s, lvl := e.s, e.lvl
+	if e.pc != nil {
+		s.printEvent(e, time.Now(), msg)
+	} else {
+		args := make([]any, len(e.fields))
+		for i := range e.fields {
+			a := e.fields[i] // the hooks and the recorders may keep the attributes
+			args[i] = &a
+		}
+		s.logContext(e.ctx, lvl, false, e.frame, msg, args...)
+		lvl = AlwaysLevel // interrupted by logContext already
+	}
+	e.putBack()
+	interrupt(lvl, msg)
+}
+
+// Msgf logs the Event with a formatted message.
+func (e *Event) Msgf(format string, args ...any) {
+	if e == nil {
+		return
+	}
+	e.Msg(fmt.Sprintf(format, args...))
+}
+
+// Send logs the Event without message.
+func (e *Event) Send() { e.Msg("") }
+
+// spliceTo writes the fields into pc, at the end of the attributes,
+// and returns the last error value of them.
+func (e *Event) spliceTo(pc *PrintCtx) error {
+	pc.buf = append(pc.buf, e.pc.buf...)
+	if n := len(pc.binFrames); n > 0 && len(e.pc.binFrames) > 0 {
+		pc.binFrames[n-1].n += e.pc.binFrames[0].n
+	}
+	return e.err
+}
+
+func (e *Event) putBack() {
+	if e.pc != nil {
+		e.pc.putBack()
+	}
+	clear(e.fields) // don't hold the values
+	*e = Event{fields: e.fields[:0]}
+	poolEvent.Put(e)
+}
+
+// printEvent paints an Event whose fields are written, the logger
+// attributes come before the fields, as collectArgs.
+func (s *Entry) printEvent(e *Event, timestamp time.Time, msg string) {
+	pc := poolPrintCtx.Get().(*PrintCtx)
+	pc.set(s, e.lvl, timestamp, e.frame, msg, s.attrs)
+	pc.event = e
+	_, _, _ = s.printImpl(e.ctx, pc)
+	pc.event = nil
+	pc.putBack()
+}
@@ -0,0 +1,162 @@
+package slog
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEvent(t *testing.T) {
+	defer SaveFlagsAndMod(Lempty, Ldatetimeflags|Lcaller|Llineno|LattrsR)()
+
+	err := errors.New("failed")
+	tm := time.Date(2024, 5, 6, 7, 8, 9, 500, time.UTC)
+	layout := MustLayoutPainter("{level:4} {msg}|{?attrs} {attrs}{/}", LayoutColorful(false))
+	for _, c := range []struct {
+		name string
+		opts []Opt
+	}{
+		{"json", []Opt{WithJSONMode()}},
+		{"logfmt", []Opt{WithMode(ModeLogFmt)}},
+		{"plain", []Opt{WithColorMode(false)}},
+		{"colorful", []Opt{WithMode(ModeColorful)}},
+		{"cbor", []Opt{WithMode(ModeCBOR)}},
+		{"msgpack", []Opt{WithMode(ModeMsgPack)}},
+		{"layout", []Opt{WithPainter(layout)}},
+		{"ecs", []Opt{WithMode(ModeECS)}},
+		{"gelf", []Opt{WithMode(ModeGELF)}},
+		{"gcp", []Opt{WithMode(ModeGCP)}},
+		{"github-actions", []Opt{WithMode(ModeGitHubActions)}},
+		{"attrs", []Opt{WithJSONMode(), WithAttrs(String("app", "x"), Int("pid", 1))}},
+		{"attrs/logfmt", []Opt{WithMode(ModeLogFmt), WithAttrs(String("app", "x"))}},
+	} {
+		for _, caller := range []bool{false, true} {
+			if caller && (c.name == "cbor" || c.name == "msgpack") {
+				continue // the line numbers are encoded as is
+			}
+			var out [2]bytes.Buffer
+			opts := []any{"ev", WithWriter(&out[0]), WithErrorWriter(&out[0]), WithLevel(InfoLevel), WithTimeFormat("2006")}
+			for _, o := range c.opts {
+				opts = append(opts, o)
+			}
+			restore := SaveFlagsAndMod(Lempty)
+			if caller {
+				AddFlags(Lcaller | Llineno)
+			}
+			l := New(opts...)
+			l.Info("done", "user", "u", "n", 3, "u64", uint64(4), "f", 2.5, "ok", true,
+				"took", time.Second, "at", tm, "list", []int{1, 2}, "error", err)
+
+			opts[1], opts[2] = WithWriter(&out[1]), WithErrorWriter(&out[1])
+			l = New(opts...)
+			l.InfoEvent().Str("user", "u").Int("n", 3).Uint64("u64", 4).Float64("f", 2.5).Bool("ok", true).
+				Dur("took", time.Second).Time("at", tm).Any("list", []int{1, 2}).Err(err).Msg("done")
+			restore()
+
+			if !bytes.Equal(withoutTime(out[0].Bytes()), withoutTime(out[1].Bytes())) {
+				t.Fatalf("%s (caller %v): expect %q, but got %q", c.name, caller, out[0].String(), out[1].String())
+			}
+		}
+	}
+}
+
+var (
+	timestampRe = regexp.MustCompile(`("(?:@timestamp|timestamp)":)("[^"]*"|[0-9.]+|\{[^}]*\})`)
+	lineRe      = regexp.MustCompile(`((?:line"?[:=]"?)|(?:\.go:))[0-9]+`)
+	fileRe      = regexp.MustCompile(`[~.][^"\s]*/(event_test\.go)`) // the path may be shortened or not
+)
+
+// withoutTime drops the timestamp, which is encoded as is in the
+// binary formats, or which is kept by the painters such as GELF. The
+// line numbers and the paths of the callers are dropped too.
+func withoutTime(b []byte) []byte {
+	if i, j := bytes.Index(b, []byte("time")), bytes.Index(b, []byte("logger")); i >= 0 && j > i && b[0] != '{' {
+		b = append(b[:i:i], b[j:]...)
+	}
+	b = timestampRe.ReplaceAll(b, []byte("${1}0"))
+	b = fileRe.ReplaceAll(b, []byte("$1"))
+	return lineRe.ReplaceAll(b, []byte("${1}0"))
+}
+
+func TestEventFallback(t *testing.T) {
+	defer SaveFlagsAndMod(Lempty, Ldatetimeflags|Lcaller|Llineno|LattrsR)()
+
+	var buf bytes.Buffer
+	l := New("ev", WithWriter(&buf), WithLevel(InfoLevel), WithJSONMode(), WithAttrs(String("app", "x")))
+
+	// the duplicated keys are deduped as the attributes
+	l.InfoEvent().Str("app", "y").Int("n", 1).Int("n", 2).Msg("dup")
+	if got := buf.String(); strings.Count(got, `"app"`) != 1 || strings.Count(got, `"n"`) != 1 || !strings.Contains(got, `"n":2`) {
+		t.Fatalf("expect the keys deduped, but got %q", got)
+	}
+
+	// the hooks see the fields as the attributes
+	buf.Reset()
+	var keys []string
+	l = New("ev", WithWriter(&buf), WithErrorWriter(&buf), WithLevel(InfoLevel), WithColorMode(false),
+		WithHook(nil, func(ctx context.Context, rec *Record) error {
+			for _, a := range rec.Attrs {
+				keys = append(keys, a.Key())
+				if a.Key() == "password" {
+					a.SetValue("***")
+				}
+			}
+			return nil
+		}))
+	l.WarnEvent().Str("user", "u").Str("password", "secret").Msgf("login %d", 1)
+	if got := strings.Join(keys, ","); got != "user,password" {
+		t.Fatalf("expect the fields hooked, but got %q", got)
+	}
+	if got := buf.String(); strings.Contains(got, "secret") || !strings.Contains(got, "login 1") {
+		t.Fatalf("expect the field redacted, but got %q", got)
+	}
+}
+
+func TestEventDisabled(t *testing.T) {
+	var buf bytes.Buffer
+	l := New("ev", WithWriter(&buf), WithLevel(WarnLevel))
+	if e := l.TraceEvent(); e != nil {
+		t.Fatalf("expect a nil event of the disabled level, but got %v", e)
+	}
+	l.InfoEvent().Str("k", "v").Err(errors.New("x")).Attr(Int("i", 1)).Send()
+	l.Event(context.Background(), TraceLevel).Int("n", 1).Msgf("%d", 1)
+	if buf.Len() != 0 {
+		t.Fatalf("expect nothing logged, but got %q", buf.String())
+	}
+}
+
+func TestEventCaller(t *testing.T) {
+	defer SaveFlagsAndMod(Lcaller | Llineno)()
+
+	var buf bytes.Buffer
+	l := New("ev", WithWriter(&buf), WithErrorWriter(&buf), WithLevel(InfoLevel), WithJSONMode())
+	l.InfoEvent().Send()
+	l.Event(context.Background(), WarnLevel).Send()
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if !strings.Contains(line, "event_test.go") {
+			t.Fatalf("expect the caller of the event, but got %q", line)
+		}
+	}
+}
+
+func TestEventAllocs(t *testing.T) {
+	if raceEnabled {
+		t.Skip("skipping the allocation test under the race detector")
+	}
+	defer SaveFlagsAndMod(Lempty, Lcaller|LattrsR)()
+
+	for _, opt := range []Opt{WithJSONMode(), WithMode(ModeLogFmt), WithColorMode(false)} {
+		l := New("ev", WithWriter(io.Discard), WithLevel(InfoLevel), opt)
+		if n := testing.AllocsPerRun(100, func() {
+			l.InfoEvent().Str("user", "u").Int("n", 1).Float64("f", 2.5).Bool("ok", true).
+				Dur("took", time.Second).Msg("done")
+		}); n != 0 {
+			t.Fatalf("%v: expect no allocation, but got %v", l.(*logimp).mode, n)
+		}
+	}
+}
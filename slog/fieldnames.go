@@ -0,0 +1,120 @@
+package slog
+
+// BuiltinField identifies a built-in field of a logging line.
+type BuiltinField int
+
+const (
+	TimeField    BuiltinField = iota // the timestamp
+	LoggerField                      // the logger name, omitted if the logger has no name
+	LevelField                       // the severity
+	MessageField                     // the message, the attributes follow it
+	CallerField                      // the caller info, omitted if Lcaller is not set
+)
+
+// FieldNames holds the key names and the order of the built-in
+// fields for JSON and logfmt modes.
+//
+// An empty name means the default name. A built-in field not in
+// Order is omitted, except MessageField which is always written.
+// The attributes always follow the message.
+//
+// Use WithFieldNames or SetFieldNames to apply it to a logger. A
+// [Painter] can override it by implementing [FieldNamesAware].
+type FieldNames struct {
+	Time    string // default is "time"
+	Level   string // default is "level"
+	Caller  string // default is "caller"
+	Message string // default is "msg"
+	Logger  string // default is "logger"
+
+	Order []BuiltinField // nil means the default order: time, logger, level, message, caller
+}
+
+// FieldNamesAware can be implemented by a [Painter] to override
+// the FieldNames of a logger.
+type FieldNamesAware interface {
+	FieldNames() *FieldNames
+}
+
+// The presets of FieldNames.
+var (
+	// FieldNamesDefault is what we are using by default.
+	FieldNamesDefault = FieldNames{
+		Time: timestampFieldName, Level: levelFieldName, Caller: callerFieldName, Message: messageFieldName, Logger: loggerFieldName,
+		Order: []BuiltinField{TimeField, LoggerField, LevelField, MessageField, CallerField},
+	}
+	// FieldNamesSlog matches log/slog JSONHandler.
+	FieldNamesSlog = FieldNames{
+		Time: "time", Level: "level", Caller: "source", Message: "msg", Logger: "logger",
+		Order: []BuiltinField{TimeField, LevelField, CallerField, LoggerField, MessageField},
+	}
+	// FieldNamesZap matches the production encoder of zap.
+	FieldNamesZap = FieldNames{
+		Time: "ts", Level: "level", Caller: "caller", Message: "msg", Logger: "logger",
+		Order: []BuiltinField{LevelField, TimeField, LoggerField, CallerField, MessageField},
+	}
+	// FieldNamesZerolog matches zerolog.
+	FieldNamesZerolog = FieldNames{
+		Time: "time", Level: "level", Caller: "caller", Message: "message", Logger: "logger",
+		Order: []BuiltinField{LevelField, TimeField, LoggerField, CallerField, MessageField},
+	}
+	// FieldNamesLogrus matches the JSONFormatter of logrus.
+	FieldNamesLogrus = FieldNames{
+		Time: "time", Level: "level", Caller: "func", Message: "msg", Logger: "logger",
+		Order: []BuiltinField{CallerField, LevelField, LoggerField, MessageField, TimeField},
+	}
+	// FieldNamesECS matches Elastic Common Schema.
+	FieldNamesECS = FieldNames{
+		Time: "@timestamp", Level: "log.level", Caller: "log.origin", Message: "message", Logger: "log.logger",
+		Order: []BuiltinField{TimeField, LevelField, LoggerField, CallerField, MessageField},
+	}
+)
+
+func (s *FieldNames) time() string    { return orDefault(s.Time, timestampFieldName) }
+func (s *FieldNames) level() string   { return orDefault(s.Level, levelFieldName) }
+func (s *FieldNames) caller() string  { return orDefault(s.Caller, callerFieldName) }
+func (s *FieldNames) message() string { return orDefault(s.Message, messageFieldName) }
+func (s *FieldNames) logger() string  { return orDefault(s.Logger, loggerFieldName) }
+
+func (s *FieldNames) order() []BuiltinField {
+	if s.Order == nil {
+		return FieldNamesDefault.Order
+	}
+	return s.Order
+}
+
+func orDefault(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
+
+// WithFieldNames sets the [FieldNames] of a logger.
+func WithFieldNames(names FieldNames) Opt {
+	return func(s *Entry) {
+		s.SetFieldNames(names)
+	}
+}
+
+// SetFieldNames sets the [FieldNames] of this logger, it is
+// inherited by the child loggers.
+func (s *Entry) SetFieldNames(names FieldNames) *Entry {
+	s.fieldNames = &names
+	return s
+}
+
+// WithFieldNames makes a child logger with the given [FieldNames].
+func (s *Entry) WithFieldNames(names FieldNames) (newLogger *Entry) {
+	return s.newChildLogger(WithFieldNames(names))
+}
+
+// FieldNames returns the [FieldNames] of this logger or its parents.
+func (s *Entry) FieldNames() *FieldNames {
+	for p := s; p != nil; p = p.owner {
+		if p.fieldNames != nil {
+			return p.fieldNames
+		}
+	}
+	return &FieldNamesDefault
+}
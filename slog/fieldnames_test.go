@@ -0,0 +1,88 @@
+package slog
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFieldNames(t *testing.T) {
+	defer SaveFlagsAndMod(Lcaller)()
+
+	var buf bytes.Buffer
+	names := FieldNames{
+		Time: "ts", Level: "severity", Caller: "src", Message: "message",
+		Order: []BuiltinField{LevelField, MessageField, TimeField, CallerField},
+	}
+	l := New("fn", WithWriter(&buf), WithJSONMode(), WithLevel(InfoLevel), WithFieldNames(names))
+
+	l.Info("hello", "a", 1, "ts", time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC))
+	line := buf.String()
+	t.Log(line)
+
+	var m map[string]any
+	if err := json.Unmarshal([]byte(line), &m); err != nil {
+		t.Fatalf("bad json %q: %v", line, err)
+	}
+	for _, k := range []string{"severity", "message", "ts", "src", "a"} {
+		if _, ok := m[k]; !ok {
+			t.Fatalf("expect key %q in %q", k, line)
+		}
+	}
+	if _, ok := m["logger"]; ok {
+		t.Fatalf("expect logger omitted since it's not in Order, but got %q", line)
+	}
+	if !strings.HasPrefix(line, `{"severity":"info","message":"hello","a":1,`) {
+		t.Fatalf("expect the fields ordered, but got %q", line)
+	}
+	if !strings.Contains(line, `"ts":"03:04:05`) {
+		t.Fatalf("expect the time attr formatted as timestamp, but got %q", line)
+	}
+	if ix, iy := strings.Index(line, `"message"`), strings.Index(line, `"src"`); ix > iy {
+		t.Fatalf("expect message before src, but got %q", line)
+	}
+
+	// children inherit the field names
+	buf.Reset()
+	l.New("child", WithWriter(&buf)).Info("from child")
+	if line = buf.String(); !strings.Contains(line, `"message":"from child"`) {
+		t.Fatalf("expect child logger inherited the field names, but got %q", line)
+	}
+}
+
+func TestFieldNamesPresets(t *testing.T) {
+	for _, c := range []struct {
+		names  FieldNames
+		prefix string
+	}{
+		{FieldNamesDefault, `{"time":`},
+		{FieldNamesSlog, `{"time":`},
+		{FieldNamesZap, `{"level":"info","ts":`},
+		{FieldNamesZerolog, `{"level":"info","time":`},
+		{FieldNamesLogrus, `{"func":`},
+		{FieldNamesECS, `{"@timestamp":`},
+	} {
+		var buf bytes.Buffer
+		l := New("preset", WithWriter(&buf), WithJSONMode(), WithLevel(InfoLevel), WithFieldNames(c.names))
+		l.Info("hello")
+		line := buf.String()
+		if !strings.HasPrefix(line, c.prefix) || !strings.Contains(line, `"`+c.names.Message+`":"hello"`) {
+			t.Fatalf("expect prefix %q, but got %q", c.prefix, line)
+		}
+		if !json.Valid([]byte(line)) {
+			t.Fatalf("bad json: %q", line)
+		}
+	}
+}
+
+func TestFieldNamesLogfmt(t *testing.T) {
+	var buf bytes.Buffer
+	l := New("fmt", WithWriter(&buf), WithMode(ModeLogFmt), WithLevel(InfoLevel),
+		WithFieldNames(FieldNames{Level: "severity", Message: "message", Order: []BuiltinField{LevelField, MessageField}}))
+	l.Info("hello", "a", 1)
+	if line := buf.String(); !strings.HasPrefix(line, `severity="info" message="hello" a=1`) {
+		t.Fatalf("unexpected logfmt line: %q", line)
+	}
+}
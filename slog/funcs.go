@@ -1,10 +1,12 @@
 package slog
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"io"
 	"log"
+	"strings"
 	"time"
 
 	"github.com/hedzr/is"
@@ -183,31 +185,63 @@ func FailContext(ctx context.Context, msg string, args ...any) {
 
 // String constructs a key-value pair with string value like log/slog.
 //
-// The different is we have not optimized these functions (String, Int, ...) for
-// performance and memory allocations. So they are just compatible with log/slog.
+// The typed constructors (String, Int, Duration, ...) hold the value in a
+// [Value] without boxing it, but the attribute itself is allocated, so
+// an attribute costs one allocation, not zero. [Attr.Value] returns the
+// integers in their original types, such as an int for Int.
 //
-// For performance, using With(attrs...) / WithAttrs(...) to get prefer effects.
-func String(key, val string) Attr                 { return &kvp{key, val} }
-func Bool(key string, val bool) Attr              { return &kvp{key, val} } // constructs boolean k-v pair. see String for performance tip.
-func Int(key string, val int) Attr                { return &kvp{key, val} } // constructs Int k-v pair. see String for performance tip.
-func Int8(key string, val int8) Attr              { return &kvp{key, val} } // constructs Int8 k-v pair. see String for performance tip.
-func Int16(key string, val int16) Attr            { return &kvp{key, val} } // constructs Int16 k-v pair. see String for performance tip.
-func Int32(key string, val int32) Attr            { return &kvp{key, val} } // constructs Int32 k-v pair. see String for performance tip.
-func Int64(key string, val int64) Attr            { return &kvp{key, val} } // constructs Int64 k-v pair. see String for performance tip.
-func Uint(key string, val uint) Attr              { return &kvp{key, val} } // constructs Uint k-v pair. see String for performance tip.
-func Uint8(key string, val uint8) Attr            { return &kvp{key, val} } // constructs Uint8 k-v pair. see String for performance tip.
-func Uint16(key string, val uint16) Attr          { return &kvp{key, val} } // constructs Uint16 k-v pair. see String for performance tip.
-func Uint32(key string, val uint32) Attr          { return &kvp{key, val} } // constructs Uint32 k-v pair. see String for performance tip.
-func Uint64(key string, val uint64) Attr          { return &kvp{key, val} } // constructs Uint64 k-v pair. see String for performance tip.
-func Float32(key string, val float32) Attr        { return &kvp{key, val} } // constructs Float32 k-v pair. see String for performance tip.
-func Float64(key string, val float64) Attr        { return &kvp{key, val} } // constructs Float64 k-v pair. see String for performance tip.
-func Complex64(key string, val complex64) Attr    { return &kvp{key, val} } // constructs Complex64 k-v pair. see String for performance tip.
-func Complex128(key string, val complex128) Attr  { return &kvp{key, val} } // constructs Complex128 k-v pair. see String for performance tip.
-func Time(key string, val time.Time) Attr         { return &kvp{key, val} } // constructs Time k-v pair. see String for performance tip.
-func Duration(key string, val time.Duration) Attr { return &kvp{key, val} } // constructs Duration k-v pair. see String for performance tip.
-func Any(key string, val any) Attr                { return &kvp{key, val} } // constructs Any k-v pair. see String for performance tip.
-
-func Numeric[T Numerics](key string, val T) Attr { return &kvp{key, val} } // constructs Numeric k-v pair. see String for performance tip.
+// For performance, using With(attrs...) / WithAttrs(...) to get prefer effects,
+// the attributes of a logger are built once.
+func String(key, val string) Attr                 { return &vkvp{key, StringValue(val)} }
+func Bool(key string, val bool) Attr              { return &vkvp{key, BoolValue(val)} }                    // constructs boolean k-v pair. see String for performance tip.
+func Int(key string, val int) Attr                { return &vkvp{key, IntValue(val)} }                     // constructs Int k-v pair. see String for performance tip.
+func Int8(key string, val int8) Attr              { return &vkvp{key, intValue(int64(val), int8(0))} }     // constructs Int8 k-v pair. see String for performance tip.
+func Int16(key string, val int16) Attr            { return &vkvp{key, intValue(int64(val), int16(0))} }    // constructs Int16 k-v pair. see String for performance tip.
+func Int32(key string, val int32) Attr            { return &vkvp{key, intValue(int64(val), int32(0))} }    // constructs Int32 k-v pair. see String for performance tip.
+func Int64(key string, val int64) Attr            { return &vkvp{key, Int64Value(val)} }                   // constructs Int64 k-v pair. see String for performance tip.
+func Uint(key string, val uint) Attr              { return &vkvp{key, uintValue(uint64(val), uint(0))} }   // constructs Uint k-v pair. see String for performance tip.
+func Uint8(key string, val uint8) Attr            { return &vkvp{key, uintValue(uint64(val), uint8(0))} }  // constructs Uint8 k-v pair. see String for performance tip.
+func Uint16(key string, val uint16) Attr          { return &vkvp{key, uintValue(uint64(val), uint16(0))} } // constructs Uint16 k-v pair. see String for performance tip.
+func Uint32(key string, val uint32) Attr          { return &vkvp{key, uintValue(uint64(val), uint32(0))} } // constructs Uint32 k-v pair. see String for performance tip.
+func Uint64(key string, val uint64) Attr          { return &vkvp{key, Uint64Value(val)} }                  // constructs Uint64 k-v pair. see String for performance tip.
+func Float32(key string, val float32) Attr        { return &vkvp{key, Float32Value(val)} }                 // constructs Float32 k-v pair. see String for performance tip.
+func Float64(key string, val float64) Attr        { return &vkvp{key, Float64Value(val)} }                 // constructs Float64 k-v pair. see String for performance tip.
+func Complex64(key string, val complex64) Attr    { return &kvp{key, val} }                                // constructs Complex64 k-v pair. see String for performance tip.
+func Complex128(key string, val complex128) Attr  { return &kvp{key, val} }                                // constructs Complex128 k-v pair. see String for performance tip.
+func Time(key string, val time.Time) Attr         { return &vkvp{key, TimeValue(val)} }                    // constructs Time k-v pair. see String for performance tip.
+func Duration(key string, val time.Duration) Attr { return &vkvp{key, DurationValue(val)} }                // constructs Duration k-v pair. see String for performance tip.
+func Any(key string, val any) Attr                { return &kvp{key, val} }                                // constructs Any k-v pair. see String for performance tip.
+
+// Numeric constructs Numeric k-v pair. see String for performance tip.
+func Numeric[T Numerics](key string, val T) Attr {
+	switch z := any(val).(type) {
+	case int:
+		return Int(key, z)
+	case int8:
+		return Int8(key, z)
+	case int16:
+		return Int16(key, z)
+	case int32:
+		return Int32(key, z)
+	case int64:
+		return Int64(key, z)
+	case uint:
+		return Uint(key, z)
+	case uint8:
+		return Uint8(key, z)
+	case uint16:
+		return Uint16(key, z)
+	case uint32:
+		return Uint32(key, z)
+	case uint64:
+		return Uint64(key, z)
+	case float32:
+		return Float32(key, z)
+	case float64:
+		return Float64(key, z)
+	}
+	return &kvp{key, val}
+}
 
 // Group constructs grouped k-v pair container, which can hold a set of normal attrs.
 //
@@ -335,8 +369,15 @@ func argsToAttrs(kvps *Attrs, args ...any) { //nolint:revive
 // NewLogLogger returns a new log.Logger such that each call to its Output method
 // dispatches a Record to the specified handler. The logger acts as a bridge from
 // the older log API to newer structured logging handlers.
+//
+// The prefix and flags of the returned log.Logger are honored: the
+// header produced by them (prefix, date, time and file:line) will be
+// parsed out of each line, so you can still call SetPrefix/SetFlags
+// on it freely. See also InstallAsDefault.
 func NewLogLogger(h Logger, lvl Level) *log.Logger {
-	return log.New(&handlerWriter{h, lvl, true, 0}, "", 0)
+	hw := &handlerWriter{h, lvl, true, 0, nil}
+	hw.std = log.New(hw, "", 0)
+	return hw.std
 }
 
 type handlerWriter struct {
@@ -344,22 +385,87 @@ type handlerWriter struct {
 	lvl         Level
 	capturePC   bool
 	extraFrames int
+	std         *log.Logger // the log.Logger which writes to us, for parsing its header
 }
 
 func (s *handlerWriter) Write(buf []byte) (n int, err error) {
-	if s.lvl >= s.l.Level() {
-		var pc uintptr
-		if s.capturePC {
-			// skip [runtime.Callers, s.Write, Logger.Output, log.Print]
-			pc = getpc(4, s.extraFrames)
+	ctx := context.Background()
+	if !s.l.EnabledContext(ctx, s.lvl) {
+		return len(buf), nil
+	}
+
+	var pc uintptr
+	if s.capturePC {
+		// skip [runtime.Callers, s.Write, Logger.Output, log.Print]
+		pc = getpc(4, s.extraFrames)
+	}
+
+	n = len(buf) // report that the entire buf was written
+	var prefix string
+	if s.std != nil {
+		prefix, buf = parseStdLogHeader(buf, s.std.Prefix(), s.std.Flags())
+	}
+
+	if h, ok := s.l.(LogSlogAware); ok && prefix != "" {
+		if len(buf) > 0 && buf[len(buf)-1] == '\n' {
+			buf = buf[:len(buf)-1]
+		}
+		h.WriteThru(ctx, s.lvl, time.Now(), pc, string(buf), Attrs{NewAttr("prefix", prefix)})
+		return
+	}
+	if h, ok := s.l.(LogLoggerAware); ok {
+		_, err = h.WriteInternal(ctx, s.lvl, pc, buf)
+	}
+	return
+}
+
+// parseStdLogHeader strips the header which was written by a
+// standard log.Logger with the given prefix and flags.
+//
+// The returned prefix has been trimmed, it is empty if no prefix
+// found.
+func parseStdLogHeader(buf []byte, prefix string, flag int) (pfx string, msg []byte) {
+	msg = buf
+	if prefix != "" && flag&log.Lmsgprefix == 0 {
+		if bytes.HasPrefix(msg, []byte(prefix)) {
+			msg, pfx = msg[len(prefix):], prefix
 		}
-		if h, ok := s.l.(LogLoggerAware); ok {
-			n, err = h.WriteInternal(context.Background(), s.lvl, pc, buf)
+	}
+
+	if flag&log.Ldate != 0 {
+		msg = skipStdLogField(msg, len("2009/01/23"))
+	}
+	if flag&(log.Ltime|log.Lmicroseconds) != 0 {
+		if flag&log.Lmicroseconds != 0 {
+			msg = skipStdLogField(msg, len("01:23:23.123123"))
+		} else {
+			msg = skipStdLogField(msg, len("01:23:23"))
 		}
 	}
+	if flag&(log.Lshortfile|log.Llongfile) != 0 {
+		// "file.go:23: "
+		if ix := bytes.Index(msg, []byte(": ")); ix > 0 && bytes.IndexByte(msg[:ix], ':') > 0 {
+			msg = msg[ix+2:]
+		}
+	}
+
+	if prefix != "" && flag&log.Lmsgprefix != 0 {
+		if bytes.HasPrefix(msg, []byte(prefix)) {
+			msg, pfx = msg[len(prefix):], prefix
+		}
+	}
+	pfx = strings.TrimSpace(pfx)
 	return
 }
 
+// skipStdLogField skips a fixed-width field and the following space.
+func skipStdLogField(buf []byte, width int) []byte {
+	if len(buf) > width && buf[width] == ' ' {
+		return buf[width+1:]
+	}
+	return buf
+}
+
 var errUnmatchedPair = errors.New("unmatched (key,value) pair")
 
 // var err // "args must be key and value pair, key should be a string"
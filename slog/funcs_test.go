@@ -123,7 +123,7 @@ func TestNewLogLogger(t *testing.T) {
 	l := NewLogLogger(New(), DebugLevel)
 	l.Print("debug")
 
-	lw := &handlerWriter{New(), DebugLevel, true, 0}
+	lw := &handlerWriter{New(), DebugLevel, true, 0, nil}
 	_, _ = lw.Write([]byte("string"))
 
 	raiseerror("e")
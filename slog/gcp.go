@@ -0,0 +1,248 @@
+package slog
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// GCPOptions configures the painter for Google Cloud Logging, see
+// NewGCPPainter.
+type GCPOptions struct {
+	// ProjectID is used to build the resource name of a trace,
+	// "projects/<ProjectID>/traces/<trace-id>". The bare trace id
+	// will be used if it is empty.
+	ProjectID string
+	// Trace extracts the trace id, the span id and the sampling
+	// decision from the context of a logging call, such as from an
+	// OpenTelemetry span. By default, the ones put by ContextWithTrace
+	// are used.
+	Trace func(ctx context.Context) (traceID, spanID string, sampled bool)
+	// Labels are added to every entry.
+	Labels map[string]string
+	// LabelKeys are the keys of the attributes which will be moved
+	// into the labels.
+	LabelKeys []string
+	// Severities overrides the mapping from a Level to a GCP
+	// severity, see GCPSeverity.
+	Severities map[Level]string
+}
+
+// The keys of the special fields recognized by Google Cloud Logging.
+const (
+	gcpSourceLocationKey = "logging.googleapis.com/sourceLocation"
+	gcpTraceKey          = "logging.googleapis.com/trace"
+	gcpSpanIDKey         = "logging.googleapis.com/spanId"
+	gcpTraceSampledKey   = "logging.googleapis.com/trace_sampled"
+	gcpLabelsKey         = "logging.googleapis.com/labels"
+)
+
+// ModeGCP prints the logging lines in the structured JSON format
+// of Google Cloud Logging, with the default GCPOptions. Use
+// NewGCPPainter and WithPainter to customize it.
+//
+// If LsmartJSONMode is set, ModeGCP will be selected automatically
+// for the loggers in ModeColorful or ModePlain while running on
+// Cloud Run or Cloud Functions.
+var ModeGCP, _ = RegisterMode("gcp", func() Painter { return NewGCPPainter(GCPOptions{}) }, ModeAutoSelect(onCloudRun))
+
+func onCloudRun() bool {
+	return os.Getenv("K_SERVICE") != "" || os.Getenv("FUNCTION_TARGET") != ""
+}
+
+// NewGCPPainter returns a [Painter] which prints the logging lines
+// in the structured JSON format of Google Cloud Logging: severity,
+// message, timestamp, logging.googleapis.com/sourceLocation (if
+// Lcaller is set), the trace and span from the context, labels and
+// the attributes.
+//
+// For example:
+//
+//	logger := slog.New(slog.WithPainter(slog.NewGCPPainter(slog.GCPOptions{
+//		ProjectID: "my-project",
+//		LabelKeys: []string{"tenant"},
+//	})))
+//	logger.InfoContext(slog.ContextWithTrace(ctx, traceID, spanID, true), "served", "tenant", "x")
+func NewGCPPainter(opts GCPOptions) Painter {
+	s := &gcpPainter{opts: opts}
+	for k, v := range opts.Labels {
+		s.labels = append(s.labels, [2]string{k, v})
+	}
+	slices.SortFunc(s.labels, func(a, b [2]string) int { return strings.Compare(a[0], b[0]) })
+	return s
+}
+
+type gcpPainter struct {
+	jsonPainter
+	opts   GCPOptions
+	labels [][2]string // sorted static labels
+}
+
+var _ LinePainter = (*gcpPainter)(nil)
+
+// GCPSeverity maps a Level to the severity of Google Cloud Logging.
+// A custom level is mapped as the level it is treated as (see
+// RegWithTreatedAsLevel), or DEFAULT.
+func GCPSeverity(lvl Level) string {
+	if l, ok := mLevelIsEnabledAs[lvl]; ok {
+		switch lvl {
+		case OKLevel, SuccessLevel:
+			return "NOTICE"
+		}
+		lvl = l
+	}
+	switch lvl {
+	case PanicLevel:
+		return "ALERT"
+	case FatalLevel:
+		return "CRITICAL"
+	case ErrorLevel:
+		return "ERROR"
+	case WarnLevel:
+		return "WARNING"
+	case InfoLevel:
+		return "INFO"
+	case DebugLevel, TraceLevel:
+		return "DEBUG"
+	}
+	return "DEFAULT"
+}
+
+func (s *gcpPainter) severity(lvl Level) string {
+	if str, ok := s.opts.Severities[lvl]; ok {
+		return str
+	}
+	return GCPSeverity(lvl)
+}
+
+// PaintLine implements [LinePainter].
+func (s *gcpPainter) PaintLine(pc *PrintCtx) (holdErrorValue error) {
+	pc.mode1 = ModeJSON
+
+	pc.AddString("severity", s.severity(pc.lvl))
+	pc.AddComma()
+	pc.AddString("message", pc.msg)
+	pc.AddComma()
+	pc.AppendStringKey("timestamp")
+	pc.AddColon()
+	s.AppendTime(pc, pc.now.UTC()) // the built-in field ignores Encoding
+
+	if pc.name != "" {
+		pc.AddComma()
+		pc.AddString(loggerFieldName, pc.name)
+	}
+
+	if src := pc.Source(); src != nil && IsAnyBitsSet(Lcaller) {
+		pc.AddComma()
+		pc.AppendStringKey(gcpSourceLocationKey)
+		pc.AddColon()
+		pc.Begin()
+		pc.AddString("file", src.File)
+		pc.AddComma()
+		pc.AddString("line", strconv.Itoa(src.Line)) // int64 is a string in the JSON of protobuf
+		pc.AddComma()
+		pc.AddString("function", src.Function)
+		pc.End(false)
+	}
+
+	s.paintTrace(pc)
+
+	attrs := pc.kvps
+	if len(s.labels) > 0 || len(s.opts.LabelKeys) > 0 {
+		attrs = s.paintLabels(pc, attrs)
+	}
+
+	holdErrorValue = serializeAttrs(pc, attrs)
+	return
+}
+
+func (s *gcpPainter) paintTrace(pc *PrintCtx) {
+	var traceID, spanID string
+	var sampled bool
+	if s.opts.Trace != nil {
+		traceID, spanID, sampled = s.opts.Trace(pc.Context())
+	} else if t, ok := pc.Context().Value(traceContextKey{}).(*traceContext); ok {
+		traceID, spanID, sampled = t.traceID, t.spanID, t.sampled
+	}
+	if traceID == "" {
+		return
+	}
+
+	if s.opts.ProjectID != "" {
+		traceID = "projects/" + s.opts.ProjectID + "/traces/" + traceID
+	}
+	pc.AddComma()
+	pc.AddString(gcpTraceKey, traceID)
+	if spanID != "" {
+		pc.AddComma()
+		pc.AddString(gcpSpanIDKey, spanID)
+	}
+	pc.AddComma()
+	pc.AddBool(gcpTraceSampledKey, sampled)
+}
+
+// paintLabels prints the static labels and the attributes in
+// LabelKeys, and returns the rest attributes.
+func (s *gcpPainter) paintLabels(pc *PrintCtx, attrs Attrs) (rest Attrs) {
+	rest = attrs
+	var picked Attrs
+	if len(s.opts.LabelKeys) > 0 {
+		rest = make(Attrs, 0, len(attrs))
+		for _, a := range attrs {
+			if a != nil && slices.Contains(s.opts.LabelKeys, a.Key()) {
+				picked = append(picked, a)
+			} else {
+				rest = append(rest, a)
+			}
+		}
+	}
+	if len(s.labels) == 0 && len(picked) == 0 {
+		return
+	}
+
+	pc.AddComma()
+	pc.AppendStringKey(gcpLabelsKey)
+	pc.AddColon()
+	pc.Begin()
+	for i, kv := range s.labels {
+		if i > 0 {
+			pc.AddComma()
+		}
+		pc.AddString(kv[0], kv[1])
+	}
+	for i, a := range picked {
+		if i > 0 || len(s.labels) > 0 {
+			pc.AddComma()
+		}
+		pc.AddString(a.Key(), fmt.Sprint(a.Value())) // the values of labels must be strings
+	}
+	pc.End(false)
+	return
+}
+
+type traceContextKey struct{}
+
+type traceContext struct {
+	traceID, spanID string
+	sampled         bool
+}
+
+// ContextWithTrace returns a copy of ctx carrying the trace id, the
+// span id and the sampling decision, which will be printed by the
+// painter made by NewGCPPainter.
+func ContextWithTrace(ctx context.Context, traceID, spanID string, sampled bool) context.Context {
+	return context.WithValue(ctx, traceContextKey{}, &traceContext{traceID, spanID, sampled})
+}
+
+// ParseCloudTraceContext parses the value of the HTTP header
+// X-Cloud-Trace-Context, "TRACE_ID/SPAN_ID;o=OPTIONS", which is
+// set by Google Cloud load balancers and Cloud Run.
+func ParseCloudTraceContext(header string) (traceID, spanID string, sampled bool) {
+	header, opts, _ := strings.Cut(header, ";")
+	traceID, spanID, _ = strings.Cut(header, "/")
+	sampled = opts == "o=1"
+	return
+}
@@ -0,0 +1,107 @@
+package slog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestGCPPainter(t *testing.T) {
+	defer SaveFlagsAndMod(Lcaller)()
+
+	var buf bytes.Buffer
+	p := NewGCPPainter(GCPOptions{
+		ProjectID: "proj",
+		Labels:    map[string]string{"env": "prod", "app": "svc"},
+		LabelKeys: []string{"tenant"},
+	})
+	l := New("gcp", WithWriter(&buf), WithErrorWriter(&buf), WithLevel(InfoLevel), WithPainter(p))
+
+	ctx := ContextWithTrace(context.Background(), "abc", "0001", true)
+	l.InfoContext(ctx, "hello", "tenant", 7, "a", 1, Group("g", "x", "y"))
+	line := buf.String()
+	t.Log(line)
+
+	var m map[string]any
+	if err := json.Unmarshal([]byte(line), &m); err != nil {
+		t.Fatalf("bad json %q: %v", line, err)
+	}
+	for k, v := range map[string]any{
+		"severity":                             "INFO",
+		"message":                              "hello",
+		"logger":                               "gcp",
+		"logging.googleapis.com/trace":         "projects/proj/traces/abc",
+		"logging.googleapis.com/spanId":        "0001",
+		"logging.googleapis.com/trace_sampled": true,
+		"a":                                    float64(1),
+	} {
+		if m[k] != v {
+			t.Fatalf("expect %q = %v, but got %v", k, v, m[k])
+		}
+	}
+	labels, _ := m["logging.googleapis.com/labels"].(map[string]any)
+	if labels["env"] != "prod" || labels["app"] != "svc" || labels["tenant"] != "7" {
+		t.Fatalf("bad labels: %v", labels)
+	}
+	if _, ok := m["tenant"]; ok {
+		t.Fatal("expect tenant moved into labels")
+	}
+	if g, _ := m["g"].(map[string]any); g["x"] != "y" {
+		t.Fatalf("bad group: %v", m["g"])
+	}
+	loc, _ := m["logging.googleapis.com/sourceLocation"].(map[string]any)
+	if !strings.HasSuffix(loc["file"].(string), "gcp_test.go") || loc["line"] == "" {
+		t.Fatalf("bad source location: %v", loc)
+	}
+
+	// levels
+	buf.Reset()
+	l.Warn("w")
+	l.OK("ok")
+	l.Fail("fail", "err", nil)
+	for i, sev := range []string{"WARNING", "NOTICE", "ERROR"} {
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		if len(lines) != 3 || !strings.HasPrefix(lines[i], `{"severity":"`+sev+`"`) {
+			t.Fatalf("expect severity %q at line %d, but got %q", sev, i, buf.String())
+		}
+	}
+}
+
+func TestGCPSeverity(t *testing.T) {
+	for lvl, sev := range map[Level]string{
+		PanicLevel: "ALERT", FatalLevel: "CRITICAL", ErrorLevel: "ERROR", WarnLevel: "WARNING",
+		InfoLevel: "INFO", DebugLevel: "DEBUG", TraceLevel: "DEBUG", AlwaysLevel: "DEFAULT",
+		OKLevel: "NOTICE", SuccessLevel: "NOTICE", FailLevel: "ERROR",
+	} {
+		if got := GCPSeverity(lvl); got != sev {
+			t.Fatalf("expect %v mapped to %q, but got %q", lvl, sev, got)
+		}
+	}
+
+	var buf bytes.Buffer
+	l := New(WithWriter(&buf), WithLevel(InfoLevel), WithPainter(NewGCPPainter(GCPOptions{
+		Severities: map[Level]string{InfoLevel: "NOTICE"},
+	})))
+	l.Info("x")
+	if !strings.HasPrefix(buf.String(), `{"severity":"NOTICE"`) {
+		t.Fatalf("expect the overridden severity, but got %q", buf.String())
+	}
+
+	buf.Reset()
+	New(WithWriter(&buf), WithLevel(InfoLevel), WithMode(ModeGCP)).Info("mode")
+	if !strings.Contains(buf.String(), `"message":"mode"`) {
+		t.Fatalf("expect ModeGCP works, but got %q", buf.String())
+	}
+}
+
+func TestParseCloudTraceContext(t *testing.T) {
+	traceID, spanID, sampled := ParseCloudTraceContext("105445aa7843bc8bf206b12000100000/1;o=1")
+	if traceID != "105445aa7843bc8bf206b12000100000" || spanID != "1" || !sampled {
+		t.Fatalf("bad result: %q, %q, %v", traceID, spanID, sampled)
+	}
+	if _, _, sampled = ParseCloudTraceContext("abc/2"); sampled {
+		t.Fatal("expect not sampled")
+	}
+}
@@ -0,0 +1,344 @@
+package slog
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GELFOptions configures the painter for GELF, see NewGELFPainter.
+type GELFOptions struct {
+	// Host is the name of the host, default is os.Hostname().
+	Host string
+	// Extra fields are added to every message, the keys will be
+	// prefixed with '_' if needed.
+	Extra map[string]any
+}
+
+// ModeGELF prints the logging lines as GELF 1.1 messages, with the
+// default GELFOptions. It is usually used with a [GELFWriter]:
+//
+//	w, err := slog.NewGELFWriter("udp", "graylog:12201", slog.WithGELFCompression(slog.GELFCompressGzip))
+//	logger := slog.New(slog.WithMode(slog.ModeGELF), slog.WithWriter(w), slog.WithErrorWriter(w))
+var ModeGELF, _ = RegisterMode("gelf", func() Painter { return NewGELFPainter(GELFOptions{}) })
+
+// NewGELFPainter returns a [Painter] which prints the logging lines
+// as GELF 1.1 messages.
+//
+// The first line of the message is the short_message, and the whole
+// message is the full_message if it has multiple lines. The level is
+// the numeric syslog severity (see SyslogSeverity). The logger name,
+// the caller info (if Lcaller is set) and the attributes are the
+// additional fields, the groups are flattened by '_', such as
+// "_group_key". The slices, the maps and the structs are encoded as
+// JSON text, since GELF allows only the strings and the numbers. The
+// duplicated keys are handled by the DedupeMode of the logger.
+func NewGELFPainter(opts GELFOptions) Painter {
+	if opts.Host == "" {
+		opts.Host, _ = os.Hostname()
+	}
+	s := &gelfPainter{opts: opts}
+	for k, v := range opts.Extra {
+		s.extra.add(DedupeLastWins, gelfKey("", k), v)
+	}
+	slices.SortFunc(s.extra, func(a, b gelfField) int { return strings.Compare(a.key, b.key) })
+	return s
+}
+
+type gelfPainter struct {
+	jsonPainter
+	opts  GELFOptions
+	extra gelfFields // sorted
+}
+
+var _ LinePainter = (*gelfPainter)(nil)
+
+// SyslogSeverity maps a Level to the numeric severity of syslog
+// (RFC 5424), which is used by GELF. A custom level is mapped as
+// the level it is treated as (see RegWithTreatedAsLevel), or 6
+// (informational).
+func SyslogSeverity(lvl Level) int {
+	if l, ok := mLevelIsEnabledAs[lvl]; ok {
+		switch lvl {
+		case OKLevel, SuccessLevel:
+			return 5 // notice
+		}
+		lvl = l
+	}
+	switch lvl {
+	case PanicLevel:
+		return 1 // alert
+	case FatalLevel:
+		return 2 // critical
+	case ErrorLevel:
+		return 3
+	case WarnLevel:
+		return 4
+	case DebugLevel, TraceLevel:
+		return 7
+	}
+	return 6
+}
+
+// PaintLine implements [LinePainter].
+func (s *gelfPainter) PaintLine(pc *PrintCtx) (holdErrorValue error) {
+	pc.mode1 = ModeJSON
+
+	firstLine, restLines, _ := ct.splitFirstAndRestLines(pc.msg)
+	if firstLine == "" {
+		firstLine = "-" // short_message is mandatory
+	}
+
+	pc.AddString("version", "1.1")
+	pc.AddComma()
+	pc.AddString("host", s.opts.Host)
+	pc.AddComma()
+	pc.AddString("short_message", firstLine)
+	if restLines != "" {
+		pc.AddComma()
+		pc.AddString("full_message", pc.msg)
+	}
+	pc.AddComma()
+	pc.AppendStringKey("timestamp")
+	pc.AddColon()
+	ns := pc.now.UnixNano()
+	pc.AppendString(strconv.FormatInt(ns/int64(time.Second), 10))
+	pc.AppendByte('.')
+	pc.AppendString(fmt.Sprintf("%06d", ns%int64(time.Second)/int64(time.Microsecond)))
+	pc.AddComma()
+	pc.AddInt("level", SyslogSeverity(pc.lvl))
+
+	fields := slices.Clone(s.extra)
+	if pc.name != "" {
+		fields.add(pc.dedupe, "_logger", pc.name)
+	}
+	if src := pc.Source(); src != nil && IsAnyBitsSet(Lcaller) {
+		fields.add(pc.dedupe, "_file", src.File)
+		fields.add(pc.dedupe, "_line", src.Line)
+		fields.add(pc.dedupe, "_function", src.Function)
+	}
+	holdErrorValue = fields.addAttrs(pc, "", pc.kvps)
+
+	for _, f := range fields {
+		pc.AddComma()
+		pc.AppendStringKey(f.key)
+		pc.AddColon()
+		appendGELFValue(pc, f.val)
+	}
+	return
+}
+
+type gelfField struct {
+	key  string
+	val  any
+	name string // the key before renamed by DedupeRename
+}
+
+// gelfFields is a list of the additional fields, the same keys are
+// handled by a DedupeMode, as the attributes.
+type gelfFields []gelfField
+
+// add adds a field. The extra fields, the logger name and the caller
+// come before the attributes, so they are overridden by the attributes
+// in DedupeLastWins, and kept in DedupeFirstWins.
+func (s *gelfFields) add(mode DedupeMode, key string, val any) {
+	if key == "_id" || key == "_" { // reserved by GELF
+		key += "_"
+	}
+	name := key
+	if i := s.index(key); i >= 0 {
+		switch mode {
+		case DedupeKeepAll:
+		case DedupeFirstWins:
+			return
+		case DedupeRename: // "key_2", as "key#2" in gelfKey
+			n := 1
+			for _, f := range *s {
+				if f.name == name {
+					n++
+				}
+			}
+			key += "_" + strconv.Itoa(n)
+		default:
+			(*s)[i].val = val
+			return
+		}
+	}
+	*s = append(*s, gelfField{key, val, name})
+}
+
+func (s gelfFields) index(key string) int {
+	for i := range s {
+		if s[i].key == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// addAttrs flattens the attributes with the [Limits] applied, and
+// returns the last error value. The rest attributes over the limits
+// are replaced by a "_more" field, such as "…(+3 attrs)".
+func (s *gelfFields) addAttrs(pc *PrintCtx, prefix string, attrs Attrs) (err error) {
+	pc.attrDepth++
+	defer func() { pc.attrDepth-- }()
+
+	dd := newAttrDeduper(pc.dedupe, attrs)
+	written := 0
+	for i, a := range attrs {
+		if a == nil {
+			continue
+		}
+		name, a, ok := dd.next(i)
+		if !ok {
+			continue // a duplicate
+		}
+		if pc.dedupe == DedupeRename {
+			name = a.Key() // renamed by add, with the extra fields
+		}
+		if more := pc.attrsOver(i, len(attrs), written); more > 0 {
+			s.add(pc.dedupe, gelfKey(prefix, "more"), moreMarker(more, "attrs"))
+			break
+		}
+		written++
+
+		key := gelfKey(prefix, name)
+		switch v := a.Value().(type) {
+		case Attrs:
+			if pc.groupTooDeep() {
+				s.add(pc.dedupe, key, moreMarker(len(v), "attrs"))
+			} else if e := s.addAttrs(pc, key, v); e != nil {
+				err = e
+			}
+		case error:
+			err = v
+			s.add(pc.dedupe, key, v)
+		default:
+			s.add(pc.dedupe, key, gelfValue(pc, v))
+		}
+	}
+	return
+}
+
+// gelfValue returns the value of an additional field with the
+// [Encoding] and the string limits applied. GELF allows only the
+// strings and the numbers, so the other values, such as the slices
+// and the structs, are encoded as JSON text, in which the slice and
+// depth limits are applied.
+func gelfValue(pc *PrintCtx, val any) any {
+	start := len(pc.buf)
+	switch v := val.(type) {
+	case nil, bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return v
+	case float32:
+		return gelfFloat(pc, float64(v))
+	case float64:
+		return gelfFloat(pc, v)
+	case string:
+		return pc.limitString(v)
+	case []byte:
+		if pc.encoding.Bytes == BytesRaw {
+			return string(pc.limitBytes(v))
+		}
+		pc.appendBytesValue(v)
+		return gelfEncoded(pc, start)
+	case time.Duration:
+		if pc.encoding.Duration == DurationSeconds {
+			return gelfFloat(pc, v.Seconds()) // a number, not a string
+		}
+		if pc.appendDurationValue(v) {
+			return gelfEncoded(pc, start)
+		}
+		return v.String()
+	case time.Time:
+		if pc.appendTimeValue(v) {
+			return gelfEncoded(pc, start)
+		}
+		return v
+	case fmt.Stringer:
+		return pc.limitString(v.String())
+	}
+
+	pc.appendValue(val)
+	return string(gelfEncoded(pc, start))
+}
+
+// gelfRaw is the JSON text of a value encoded by PrintCtx.
+type gelfRaw string
+
+// gelfEncoded takes the text encoded since start out of pc.
+func gelfEncoded(pc *PrintCtx, start int) gelfRaw {
+	raw := gelfRaw(pc.buf[start:])
+	pc.buf = pc.buf[:start]
+	return raw
+}
+
+// gelfFloat rounds v to the FloatPrecision of [Encoding].
+func gelfFloat(pc *PrintCtx, v float64) float64 {
+	if prec := pc.floatPrecision(); prec >= 0 {
+		return roundFloat(v, prec)
+	}
+	return v
+}
+
+// gelfKey builds the key of an additional field, the characters
+// not allowed by GELF, [^\w\.\-], are replaced with '_'.
+func gelfKey(prefix, key string) string {
+	b := make([]byte, 0, len(prefix)+len(key)+2)
+	if prefix == "" {
+		b = append(b, '_')
+	} else {
+		b = append(append(b, prefix...), '_')
+	}
+	if prefix == "" && len(key) > 0 && key[0] == '_' {
+		key = key[1:]
+	}
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		if c == '_' || c == '.' || c == '-' || (c >= '0' && c <= '9') || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') {
+			b = append(b, c)
+		} else {
+			b = append(b, '_')
+		}
+	}
+	return string(b)
+}
+
+// appendGELFValue prints a value of an additional field, which must
+// be a string or a number in GELF.
+func appendGELFValue(pc *PrintCtx, val any) {
+	switch v := val.(type) {
+	case int:
+		pc.AppendInt(v)
+	case int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		pc.AppendString(fmt.Sprint(v))
+	case float32:
+		appendGELFFloat(pc, float64(v), 32)
+	case float64:
+		appendGELFFloat(pc, v, 64)
+	case string:
+		pc.AppendQuotedString(v)
+	case gelfRaw:
+		pc.AppendString(string(v))
+	case error:
+		pc.AppendQuotedString(v.Error())
+	case time.Time:
+		pc.AppendQuotedString(v.Format(time.RFC3339Nano))
+	case fmt.Stringer:
+		pc.AppendQuotedString(v.String())
+	default:
+		pc.AppendQuotedString(fmt.Sprint(v))
+	}
+}
+
+func appendGELFFloat(pc *PrintCtx, v float64, bitSize int) {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		pc.AppendQuotedString(strconv.FormatFloat(v, 'g', -1, bitSize))
+		return
+	}
+	pc.AppendString(strconv.FormatFloat(v, 'g', -1, bitSize))
+}
@@ -0,0 +1,299 @@
+package slog
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGELFPainter(t *testing.T) {
+	defer SaveFlagsAndMod(Lcaller)()
+
+	var buf bytes.Buffer
+	p := NewGELFPainter(GELFOptions{Host: "h1", Extra: map[string]any{"env": "prod", "id": 3}})
+	l := New("gelf", WithWriter(&buf), WithErrorWriter(&buf), WithLevel(InfoLevel), WithPainter(p))
+	l.Info("first line\nsecond line", "a", 1, "b c", true, "f", 1.5, Group("g", "x", "y", Group("h", "z", 2)), "err", errors.New("boom"))
+	t.Log(buf.String())
+
+	var m map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &m); err != nil {
+		t.Fatalf("bad json %q: %v", buf.String(), err)
+	}
+	for k, v := range map[string]any{
+		"version":       "1.1",
+		"host":          "h1",
+		"short_message": "first line",
+		"full_message":  "first line\nsecond line",
+		"level":         float64(6),
+		"_logger":       "gelf",
+		"_env":          "prod",
+		"_id_":          float64(3),
+		"_a":            float64(1),
+		"_b_c":          "true",
+		"_f":            1.5,
+		"_g_x":          "y",
+		"_g_h_z":        float64(2),
+		"_err":          "boom",
+	} {
+		if m[k] != v {
+			t.Fatalf("expect %q = %v (%T), but got %v (%T)", k, v, v, m[k], m[k])
+		}
+	}
+	if ts, ok := m["timestamp"].(float64); !ok || time.Since(time.Unix(int64(ts), 0)) > time.Minute {
+		t.Fatalf("bad timestamp: %v", m["timestamp"])
+	}
+	if !strings.HasSuffix(m["_file"].(string), "gelf_test.go") {
+		t.Fatalf("bad _file: %v", m["_file"])
+	}
+
+	buf.Reset()
+	l.Warn("")
+	if err := json.Unmarshal(buf.Bytes(), &m); err != nil || m["short_message"] != "-" || m["level"] != float64(4) {
+		t.Fatalf("unexpected message %q: %v", buf.String(), err)
+	}
+}
+
+func TestGELFDedupe(t *testing.T) {
+	for _, c := range []struct {
+		mode DedupeMode
+		want []string
+	}{
+		{DedupeLastWins, []string{`"_k":"c2"`, `"_a":1`}},
+		{DedupeFirstWins, []string{`"_k":"extra"`, `"_a":1`}},
+		{DedupeKeepAll, []string{`"_k":"extra"`, `"_k":"c1"`, `"_k":"c2"`}},
+		{DedupeRename, []string{`"_k":"extra"`, `"_k_2":"c1"`, `"_k_3":"c2"`}},
+	} {
+		var buf bytes.Buffer
+		p := NewGELFPainter(GELFOptions{Host: "h1", Extra: map[string]any{"k": "extra"}})
+		l := New("gelf", WithWriter(&buf), WithLevel(InfoLevel), WithPainter(p), WithDedupe(c.mode))
+		l.Info("msg", "k", "c1", "a", 1, "k", "c2")
+		out := buf.String()
+		for _, want := range c.want {
+			if !strings.Contains(out, want) {
+				t.Fatalf("%v: expect %s, but got %s", c.mode, want, out)
+			}
+		}
+		if c.mode == DedupeLastWins || c.mode == DedupeFirstWins {
+			if n := strings.Count(out, `"_k"`); n != 1 {
+				t.Fatalf("%v: expect _k deduped, but got %s", c.mode, out)
+			}
+		}
+	}
+}
+
+func TestSyslogSeverity(t *testing.T) {
+	for lvl, sev := range map[Level]int{
+		PanicLevel: 1, FatalLevel: 2, ErrorLevel: 3, WarnLevel: 4, InfoLevel: 6,
+		DebugLevel: 7, TraceLevel: 7, OKLevel: 5, FailLevel: 3, AlwaysLevel: 6,
+	} {
+		if got := SyslogSeverity(lvl); got != sev {
+			t.Fatalf("expect %v mapped to %d, but got %d", lvl, sev, got)
+		}
+	}
+}
+
+func TestGELFWriterUDP(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Skip(err)
+	}
+	defer pc.Close()
+
+	for _, c := range []struct {
+		compression GELFCompression
+		decode      func(io.Reader) (io.Reader, error)
+	}{
+		{GELFCompressNone, func(r io.Reader) (io.Reader, error) { return r, nil }},
+		{GELFCompressGzip, func(r io.Reader) (io.Reader, error) { return gzip.NewReader(r) }},
+		{GELFCompressZlib, func(r io.Reader) (io.Reader, error) { return zlib.NewReader(r) }},
+	} {
+		w, err := NewGELFWriter("udp", pc.LocalAddr().String(), WithGELFCompression(c.compression), WithGELFChunkSize(100))
+		if err != nil {
+			t.Fatal(err)
+		}
+		l := New(WithMode(ModeGELF), WithWriter(w), WithLevel(InfoLevel))
+		big := strings.Repeat("0123456789", 100)
+		l.Info("hello", "big", big, "rnd", time.Now().UnixNano()) // too random to be compressed into one chunk
+		_ = w.Close()
+
+		data := readGELFUDP(t, pc)
+		r, err := c.decode(bytes.NewReader(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var m map[string]any
+		if err = json.NewDecoder(r).Decode(&m); err != nil {
+			t.Fatalf("bad message: %v", err)
+		}
+		if m["short_message"] != "hello" || m["_big"] != big {
+			t.Fatalf("unexpected message: %v", m)
+		}
+	}
+}
+
+// readGELFUDP reads a message, the chunks are reassembled.
+func readGELFUDP(t *testing.T, pc net.PacketConn) []byte {
+	_ = pc.SetReadDeadline(time.Now().Add(5 * time.Second))
+	var chunks [][]byte
+	buf := make([]byte, 65536)
+	for {
+		n, _, err := pc.ReadFrom(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data := append([]byte(nil), buf[:n]...)
+		if len(data) < 2 || data[0] != 0x1e || data[1] != 0x0f {
+			return data
+		}
+		if len(data) > 100 {
+			t.Fatalf("chunk too large: %d", len(data))
+		}
+		chunks = append(chunks, data)
+		if count := int(data[11]); len(chunks) == count {
+			sort.Slice(chunks, func(i, j int) bool { return chunks[i][10] < chunks[j][10] })
+			var msg []byte
+			for _, c := range chunks {
+				if !bytes.Equal(c[2:10], chunks[0][2:10]) {
+					t.Fatal("message id mismatched")
+				}
+				msg = append(msg, c[12:]...)
+			}
+			return msg
+		}
+	}
+}
+
+func TestGELFWriterTCP(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skip(err)
+	}
+	defer ln.Close()
+
+	received := make(chan []string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			received <- nil
+			return
+		}
+		defer conn.Close()
+		var msgs []string
+		r := bufio.NewReader(conn)
+		for {
+			msg, err := r.ReadString(0)
+			if err != nil {
+				break
+			}
+			msgs = append(msgs, strings.TrimSuffix(msg, "\x00"))
+		}
+		received <- msgs
+	}()
+
+	w, err := NewGELFWriter("tcp", ln.Addr().String(), WithGELFCompression(GELFCompressGzip))
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := New(WithMode(ModeGELF), WithWriter(w), WithLevel(InfoLevel))
+	l.Info("one")
+	l.Info("two", "k", "v")
+	_ = w.Close()
+
+	if _, err = w.Write([]byte("x")); !errors.Is(err, net.ErrClosed) {
+		t.Fatalf("expect ErrClosed, but got %v", err)
+	}
+
+	msgs := <-received
+	if len(msgs) != 2 {
+		t.Fatalf("expect 2 messages, but got %q", msgs)
+	}
+	for i, want := range []string{"one", "two"} {
+		var m map[string]any
+		if err = json.Unmarshal([]byte(msgs[i]), &m); err != nil || m["short_message"] != want {
+			t.Fatalf("unexpected message %q: %v", msgs[i], err)
+		}
+	}
+}
+
+func TestGELFWriterTCPRedial(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skip(err)
+	}
+	addr := ln.Addr().String()
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		conn, _ := ln.Accept()
+		accepted <- conn
+	}()
+
+	w, err := NewGELFWriter("tcp", addr, WithGELFDialTimeout(time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	// the server is gone, the writes fail and the reconnection too
+	_ = ln.Close()
+	if conn := <-accepted; conn != nil {
+		_ = conn.Close()
+	}
+	for i := 0; i < 100 && err == nil; i++ {
+		_, err = w.Write([]byte("lost"))
+		time.Sleep(time.Millisecond)
+	}
+	if err == nil {
+		t.Fatal("expect the writes failed")
+	}
+	if _, err = w.Write([]byte("lost")); err == nil || errors.Is(err, net.ErrClosed) {
+		t.Fatalf("expect redialing failed, but got %v", err)
+	}
+
+	// the server is back, the next write redials
+	if ln, err = net.Listen("tcp", addr); err != nil {
+		t.Skip(err)
+	}
+	defer ln.Close()
+	received := make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			received <- ""
+			return
+		}
+		defer conn.Close()
+		msg, _ := bufio.NewReader(conn).ReadString(0)
+		received <- strings.TrimSuffix(msg, "\x00")
+	}()
+	if _, err = w.Write([]byte("back\n")); err != nil {
+		t.Fatalf("expect the writer redialed, but got %v", err)
+	}
+	if msg := <-received; msg != "back" {
+		t.Fatalf("expect the message after redialing, but got %q", msg)
+	}
+
+	_ = w.Close()
+	if _, err = w.Write([]byte("x")); !errors.Is(err, net.ErrClosed) {
+		t.Fatalf("expect ErrClosed after Close, but got %v", err)
+	}
+}
+
+func TestNewGELFWriterErrors(t *testing.T) {
+	if _, err := NewGELFWriter("unix", "/tmp/x"); err == nil {
+		t.Fatal("expect an error for unsupported network")
+	}
+
+	w := &GELFWriter{network: "udp", chunkSize: 20}
+	if err := w.writeUDP(bytes.Repeat([]byte{'x'}, 8*129+1)); !errors.Is(err, ErrGELFTooLarge) {
+		t.Fatalf("expect ErrGELFTooLarge, but got %v", err)
+	}
+}
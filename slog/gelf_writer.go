@@ -0,0 +1,230 @@
+package slog
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"sync"
+	"time"
+)
+
+// GELFCompression is the compression of the GELF messages over UDP.
+type GELFCompression int
+
+const (
+	GELFCompressNone GELFCompression = iota // no compression
+	GELFCompressGzip                        // gzip
+	GELFCompressZlib                        // zlib
+)
+
+const (
+	// GELFChunkSize is the default max size of a UDP datagram,
+	// which fits the most networks.
+	GELFChunkSize = 1420
+
+	gelfMaxChunks      = 128
+	gelfChunkHeaderLen = 12
+)
+
+// ErrGELFTooLarge is returned if a message needs more than 128 chunks.
+var ErrGELFTooLarge = errors.New("gelf: message too large")
+
+// GELFWriterOpt is used by NewGELFWriter.
+type GELFWriterOpt func(s *GELFWriter)
+
+// WithGELFCompression sets the compression for UDP. It is ignored
+// by TCP, which does not support compression.
+func WithGELFCompression(c GELFCompression) GELFWriterOpt {
+	return func(s *GELFWriter) {
+		s.compression = c
+	}
+}
+
+// WithGELFChunkSize sets the max size of a UDP datagram, default is
+// GELFChunkSize.
+func WithGELFChunkSize(size int) GELFWriterOpt {
+	return func(s *GELFWriter) {
+		if size > gelfChunkHeaderLen {
+			s.chunkSize = size
+		}
+	}
+}
+
+// WithGELFDialTimeout sets the timeout of connecting, default is 5s.
+func WithGELFDialTimeout(d time.Duration) GELFWriterOpt {
+	return func(s *GELFWriter) {
+		s.timeout = d
+	}
+}
+
+// GELFWriter sends the GELF messages to a Graylog input over UDP
+// or TCP. Each Write sends one message, the trailing newline will
+// be trimmed. See ModeGELF.
+//
+// Over UDP, a message larger than the chunk size will be sent in
+// chunks. Over TCP, the messages are delimited by null bytes, and
+// the connection will be re-established once if a write failed. If
+// it cannot be re-established, the next Write tries again, until
+// the writer is closed.
+type GELFWriter struct {
+	network, addr string
+	compression   GELFCompression
+	chunkSize     int
+	timeout       time.Duration
+
+	mu     sync.Mutex
+	conn   net.Conn // nil if it's broken and not re-established yet
+	closed bool     // closed by Close
+	buf    bytes.Buffer
+}
+
+var _ io.WriteCloser = (*GELFWriter)(nil)
+
+// NewGELFWriter connects to a GELF input. The network is "udp" or
+// "tcp" (and their variants, such as "udp4").
+func NewGELFWriter(network, addr string, opts ...GELFWriterOpt) (*GELFWriter, error) {
+	s := &GELFWriter{network: network, addr: addr, chunkSize: GELFChunkSize, timeout: 5 * time.Second}
+	for _, opt := range opts {
+		opt(s)
+	}
+	switch network {
+	case "udp", "udp4", "udp6", "tcp", "tcp4", "tcp6":
+	default:
+		return nil, fmt.Errorf("gelf: unsupported network %q", network)
+	}
+	if err := s.redial(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *GELFWriter) udp() bool { return s.network[0] == 'u' }
+
+// redial connects again, the old connection is replaced only if the
+// new one is established.
+func (s *GELFWriter) redial() error {
+	conn, err := net.DialTimeout(s.network, s.addr, s.timeout)
+	if err != nil {
+		return err
+	}
+	if s.conn != nil {
+		_ = s.conn.Close()
+	}
+	s.conn = conn
+	return nil
+}
+
+// Write sends p as a GELF message.
+func (s *GELFWriter) Write(p []byte) (n int, err error) {
+	msg := bytes.TrimRight(p, "\n")
+	if len(msg) == 0 {
+		return len(p), nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return 0, net.ErrClosed
+	}
+	if s.conn == nil {
+		if err = s.redial(); err != nil {
+			return 0, err
+		}
+	}
+	if s.udp() {
+		err = s.writeUDP(msg)
+	} else {
+		err = s.writeTCP(msg)
+	}
+	if err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func (s *GELFWriter) writeTCP(msg []byte) (err error) {
+	s.buf.Reset()
+	s.buf.Write(msg)
+	s.buf.WriteByte(0)
+	if _, err = s.conn.Write(s.buf.Bytes()); err != nil {
+		// reconnect once
+		if err = s.redial(); err != nil {
+			_ = s.conn.Close() // it's broken, the next Write will redial
+			s.conn = nil
+			return
+		}
+		if _, err = s.conn.Write(s.buf.Bytes()); err != nil {
+			_ = s.conn.Close()
+			s.conn = nil
+		}
+	}
+	return
+}
+
+func (s *GELFWriter) writeUDP(msg []byte) (err error) {
+	if msg, err = s.compress(msg); err != nil {
+		return
+	}
+	if len(msg) <= s.chunkSize {
+		_, err = s.conn.Write(msg)
+		return
+	}
+
+	size := s.chunkSize - gelfChunkHeaderLen
+	count := (len(msg) + size - 1) / size
+	if count > gelfMaxChunks {
+		return ErrGELFTooLarge
+	}
+
+	var chunk = make([]byte, 0, s.chunkSize)
+	id := rand.Uint64()
+	for i := 0; i < count; i++ {
+		chunk = append(chunk[:0], 0x1e, 0x0f,
+			byte(id>>56), byte(id>>48), byte(id>>40), byte(id>>32),
+			byte(id>>24), byte(id>>16), byte(id>>8), byte(id),
+			byte(i), byte(count))
+		chunk = append(chunk, msg[i*size:min((i+1)*size, len(msg))]...)
+		if _, err = s.conn.Write(chunk); err != nil {
+			return
+		}
+	}
+	return
+}
+
+func (s *GELFWriter) compress(msg []byte) ([]byte, error) {
+	var w io.WriteCloser
+	s.buf.Reset()
+	switch s.compression {
+	case GELFCompressGzip:
+		w = gzip.NewWriter(&s.buf)
+	case GELFCompressZlib:
+		w = zlib.NewWriter(&s.buf)
+	default:
+		return msg, nil
+	}
+	if _, err := w.Write(msg); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return s.buf.Bytes(), nil
+}
+
+// Close closes the connection, the writer cannot be used after.
+func (s *GELFWriter) Close() (err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	if s.conn != nil {
+		err = s.conn.Close()
+		s.conn = nil
+	}
+	return
+}
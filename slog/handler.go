@@ -0,0 +1,140 @@
+package slog
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// Handler handles a [Record].
+//
+// The painters and writers of a logger are wrapped as the terminal
+// Handler, which encodes the record and writes it out. See
+// [Entry.Handler].
+type Handler interface {
+	Handle(ctx context.Context, rec *Record) error
+}
+
+// HandlerFunc is a function adapter for [Handler].
+type HandlerFunc func(ctx context.Context, rec *Record) error
+
+// Handle implements [Handler].
+func (f HandlerFunc) Handle(ctx context.Context, rec *Record) error { return f(ctx, rec) }
+
+// Middleware wraps a [Handler] and returns a new one.
+//
+// A middleware can enrich, filter, route or drop the records
+// before any painter runs:
+//
+//   - to enrich a record, modify it and call next.
+//   - to drop a record, return without calling next.
+//   - to route a record, change rec.Logger to another logger and
+//     call next, or call the other logger's [Entry.Handler] directly.
+//
+// For example:
+//
+//	logger := slog.New(slog.WithMiddleware(func(next slog.Handler) slog.Handler {
+//		return slog.HandlerFunc(func(ctx context.Context, rec *slog.Record) error {
+//			rec.Attrs = append(rec.Attrs, slog.String("host", hostname))
+//			return next.Handle(ctx, rec)
+//		})
+//	}))
+type Middleware func(next Handler) Handler
+
+// WithMiddleware adds middlewares to a logger.
+//
+// The middlewares are inherited by the child loggers. The ones
+// of a parent logger run before the ones of its children, and
+// the ones of a logger run in the order of being added.
+func WithMiddleware(mws ...Middleware) Opt {
+	return func(s *Entry) {
+		s.AddMiddleware(mws...)
+	}
+}
+
+// AddMiddleware adds middlewares to this logger. See also [WithMiddleware].
+func (s *Entry) AddMiddleware(mws ...Middleware) *Entry {
+	for _, mw := range mws {
+		if mw != nil {
+			s.middlewares = append(s.middlewares, mw)
+		}
+	}
+	pipelineGen.Add(1)
+	return s
+}
+
+// WithMiddleware makes a child logger with the given middlewares.
+func (s *Entry) WithMiddleware(mws ...Middleware) (newLogger *Entry) {
+	return s.newChildLogger(WithMiddleware(mws...))
+}
+
+// ResetMiddlewares removes all middlewares of this logger. The ones
+// of its parents are kept.
+func (s *Entry) ResetMiddlewares() *Entry {
+	s.middlewares = nil
+	pipelineGen.Add(1)
+	return s
+}
+
+// Handler returns the terminal [Handler] of this logger, which
+// paints a record with the settings of this logger and writes it
+// to the writers of this logger. The middlewares are not included.
+//
+// It is useful for routing the records from a middleware.
+func (s *Entry) Handler() Handler { return &entryHandler{s: s} }
+
+type entryHandler struct {
+	s        *Entry
+	routable bool // paint with rec.Logger rather than s
+}
+
+func (h *entryHandler) Handle(ctx context.Context, rec *Record) error {
+	l := h.s
+	if h.routable && rec.Logger != nil {
+		l = rec.Logger
+	}
+	l.handle(ctx, rec, rec.Level, rec.Time, rec.PC, rec.Msg, rec.Attrs)
+	return nil
+}
+
+// pipelineGen will be increased once the middlewares of any logger
+// changed, so the cached pipelines can be rebuilt.
+var pipelineGen atomic.Int64
+
+type pipeline struct {
+	gen int64
+	h   Handler
+}
+
+// pipeline returns the middlewares chain of this logger, or nil
+// if there are no middlewares.
+func (s *Entry) pipeline() Handler {
+	has := false
+	for p := s; p != nil && !has; p = p.owner {
+		has = len(p.middlewares) > 0
+	}
+	if !has {
+		return nil
+	}
+
+	gen := pipelineGen.Load()
+	if p := s.pipe.Load(); p != nil && p.gen == gen {
+		return p.h
+	}
+
+	var h Handler = &entryHandler{s: s, routable: true}
+	for p := s; p != nil; p = p.owner {
+		for i := len(p.middlewares) - 1; i >= 0; i-- {
+			h = p.middlewares[i](h)
+		}
+	}
+	s.pipe.Store(&pipeline{gen, h})
+	return h
+}
+
+func (s *Entry) dispatch(ctx context.Context, h Handler, lvl Level, timestamp time.Time, stackFrame uintptr, msg string, kvps Attrs) {
+	rec := newRecord(s, lvl, timestamp, stackFrame, msg, kvps)
+	if err := h.Handle(ctx, rec); err != nil && lvl != WarnLevel { // don't warn on warning to avoid infinite calls
+		s.Warn("slog handler failed", "error", err)
+	}
+}
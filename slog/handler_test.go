@@ -0,0 +1,108 @@
+package slog
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestMiddleware(t *testing.T) {
+	var buf, routed bytes.Buffer
+	var order []string
+
+	other := New("other", WithWriter(&routed), WithErrorWriter(&routed), WithColorMode(false), WithLevel(InfoLevel))
+
+	trace := func(name string) Middleware {
+		return func(next Handler) Handler {
+			return HandlerFunc(func(ctx context.Context, rec *Record) error {
+				order = append(order, name)
+				return next.Handle(ctx, rec)
+			})
+		}
+	}
+	enrich := func(next Handler) Handler {
+		return HandlerFunc(func(ctx context.Context, rec *Record) error {
+			rec.Attrs = append(rec.Attrs, String("host", "h1"))
+			return next.Handle(ctx, rec)
+		})
+	}
+	filter := func(next Handler) Handler {
+		return HandlerFunc(func(ctx context.Context, rec *Record) error {
+			if strings.HasPrefix(rec.Msg, "drop") {
+				return nil
+			}
+			if strings.HasPrefix(rec.Msg, "route") {
+				return other.Handler().Handle(ctx, rec)
+			}
+			return next.Handle(ctx, rec)
+		})
+	}
+
+	l := New("mw", WithWriter(&buf), WithColorMode(false), WithLevel(InfoLevel),
+		WithMiddleware(trace("p1"), trace("p2"), enrich))
+	child := l.New("child", WithMiddleware(trace("c1"), filter))
+
+	child.Info("hello")
+	if got := strings.Join(order, ","); got != "p1,p2,c1" {
+		t.Fatalf("expect middlewares run from parent to child, but got %q", got)
+	}
+	// child logger has no writer, so check it via a recorder
+	var recs []*Record
+	child.AddRecorder(func(ctx context.Context, rec *Record) { recs = append(recs, rec.Clone()) })
+
+	child.Info("drop me")
+	child.Info("keep me")
+	if len(recs) != 1 || recs[0].Msg != "keep me" {
+		t.Fatalf("expect dropped record not to reach the terminal, but got %v", recs)
+	}
+	if _, ok := findAttr(recs[0].Attrs, "host"); !ok {
+		t.Fatalf("expect the record enriched, but got %v", recs[0].Attrs)
+	}
+
+	child.Info("route me")
+	if out := routed.String(); !strings.Contains(out, "route me") || !strings.Contains(out, `host="h1"`) {
+		t.Fatalf("expect the record routed to other logger, but got %q", out)
+	}
+
+	l.Info("parent only")
+	if out := buf.String(); !strings.Contains(out, "parent only") || !strings.Contains(out, `host="h1"`) {
+		t.Fatalf("expect parent's middlewares applied, but got %q", out)
+	}
+
+	// the cached pipeline is rebuilt after middlewares changed
+	order = nil
+	l.ResetMiddlewares()
+	child.Info("again")
+	if got := strings.Join(order, ","); got != "c1" {
+		t.Fatalf("expect pipeline rebuilt, but got %q", got)
+	}
+}
+
+func TestMiddlewareError(t *testing.T) {
+	var buf bytes.Buffer
+	l := New("mwerr", WithWriter(&buf), WithErrorWriter(&buf), WithColorMode(false), WithLevel(InfoLevel),
+		WithMiddleware(func(next Handler) Handler {
+			return HandlerFunc(func(ctx context.Context, rec *Record) error {
+				if rec.Level == InfoLevel {
+					return errors.New("bad record")
+				}
+				return next.Handle(ctx, rec)
+			})
+		}))
+
+	l.Info("hello")
+	if out := buf.String(); !strings.Contains(out, "slog handler failed") || !strings.Contains(out, "bad record") {
+		t.Fatalf("expect handler error reported, but got %q", out)
+	}
+}
+
+func findAttr(as Attrs, key string) (Attr, bool) {
+	for _, a := range as {
+		if a.Key() == key {
+			return a, true
+		}
+	}
+	return nil, false
+}
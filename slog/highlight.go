@@ -0,0 +1,223 @@
+package slog
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ValueKind is the kind of an attribute value, for the colours of
+// the values in a [Theme].
+type ValueKind int
+
+const (
+	KindOther    ValueKind = iota // the values not listed below, printed in the colour of the level
+	KindNumber                    // the integers, the floats and the complex numbers
+	KindBool                      // true and false
+	KindNil                       // nil
+	KindDuration                  // time.Duration
+	KindTime                      // time.Time
+	KindString                    // the strings, the levels and the fmt.Stringer
+	KindError                     // the errors, default is the Error colour of the theme
+	KindGroup                     // the group names in the dotted keys, such as "group." in "group.key=value"
+)
+
+var valueKindNames = map[string]ValueKind{
+	"number":   KindNumber,
+	"bool":     KindBool,
+	"nil":      KindNil,
+	"duration": KindDuration,
+	"time":     KindTime,
+	"string":   KindString,
+	"error":    KindError,
+	"group":    KindGroup,
+}
+
+// kind returns the kind of a Value for the colours, without boxing
+// the common types.
+func (v Value) kind() ValueKind {
+	switch v.typ {
+	case TypeBool:
+		return KindBool
+	case TypeInt64, TypeUint64, TypeFloat32, TypeFloat64:
+		return KindNumber
+	case TypeString:
+		return KindString
+	case TypeDuration:
+		return KindDuration
+	case TypeTime:
+		return KindTime
+	}
+	return kindOf(v.any)
+}
+
+// kindOf returns the kind of a value.
+func kindOf(val any) ValueKind {
+	switch val.(type) {
+	case nil:
+		return KindNil
+	case bool:
+		return KindBool
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, uintptr,
+		float32, float64, complex64, complex128:
+		return KindNumber
+	case time.Duration:
+		return KindDuration
+	case time.Time:
+		return KindTime
+	case error:
+		return KindError
+	case string, Level, ToString, Stringer:
+		return KindString
+	}
+	return KindOther
+}
+
+// Highlight paints the values of a key in a style if they match
+// a condition. The highlights are checked before the colours of
+// the value kinds. See [Theme].
+type Highlight struct {
+	Key   string             // the key, or the dotted key in a group, such as "http.status"
+	Match func(val any) bool // nil matches any value
+	Style Style
+}
+
+// NewHighlight returns a Highlight of a key, whose condition is an
+// operator (one of ==, !=, >, >=, < and <=) followed by an operand,
+// for example:
+//
+//	hl, err := slog.NewHighlight("status", ">=500", slog.Style{Fg: color.FgRed})
+//
+// If the operand is a number, the numeric values are compared with
+// it, or else the text of the values is compared. The numbers are
+// compared as text for == and != too, if the value is not numeric.
+// An empty condition matches any value.
+func NewHighlight(key, cond string, style Style) (hl Highlight, err error) {
+	hl = Highlight{Key: key, Style: style}
+	cond = strings.TrimSpace(cond)
+	if cond == "" {
+		return
+	}
+
+	var op string
+	for _, o := range []string{"==", "!=", ">=", "<=", ">", "<"} {
+		if strings.HasPrefix(cond, o) {
+			op = o
+			break
+		}
+	}
+	if op == "" {
+		return hl, fmt.Errorf("slog: bad highlight condition %q", cond)
+	}
+	operand := strings.TrimSpace(cond[len(op):])
+	num, e := strconv.ParseFloat(operand, 64)
+	numeric := e == nil
+
+	hl.Match = func(val any) bool {
+		if numeric {
+			if f, ok := toFloat(val); ok {
+				return compare(op, f, num)
+			}
+		}
+		if op != "==" && op != "!=" && numeric {
+			return false
+		}
+		var text string
+		switch z := val.(type) {
+		case string:
+			text = z
+		case error:
+			text = z.Error()
+		case Stringer:
+			text = z.String()
+		default:
+			text = fmt.Sprint(val)
+		}
+		return compare(op, text, operand)
+	}
+	return
+}
+
+func compare[T float64 | string](op string, a, b T) bool {
+	switch op {
+	case "==":
+		return a == b
+	case "!=":
+		return a != b
+	case ">":
+		return a > b
+	case ">=":
+		return a >= b
+	case "<":
+		return a < b
+	}
+	return a <= b
+}
+
+func toFloat(val any) (f float64, ok bool) {
+	switch z := val.(type) {
+	case int:
+		return float64(z), true
+	case int8:
+		return float64(z), true
+	case int16:
+		return float64(z), true
+	case int32:
+		return float64(z), true
+	case int64:
+		return float64(z), true
+	case uint:
+		return float64(z), true
+	case uint8:
+		return float64(z), true
+	case uint16:
+		return float64(z), true
+	case uint32:
+		return float64(z), true
+	case uint64:
+		return float64(z), true
+	case float32:
+		return float64(z), true
+	case float64:
+		return z, true
+	}
+	return
+}
+
+// valueStyle returns the style of an attribute value: the first
+// matched highlight, or the colour of the value kind.
+func (s *Theme) valueStyle(key string, val any) (st Style, ok bool) {
+	return s.typedValueStyle(key, Value{any: val})
+}
+
+// typedValueStyle is valueStyle of a Value, the value is boxed only
+// for the Match of a Highlight.
+func (s *Theme) typedValueStyle(key string, val Value) (st Style, ok bool) {
+	for i := range s.Highlights {
+		hl := &s.Highlights[i]
+		if hl.Key == key && (hl.Match == nil || hl.Match(val.Any())) {
+			return hl.Style, true
+		}
+	}
+	st, ok = s.Values[val.kind()]
+	return
+}
+
+// echoValueStyle emits the colour of an attribute value, and
+// reports whether it is emitted.
+func (s *PrintCtx) echoValueStyle(key string, val Value) bool {
+	st, ok := s.Theme().typedValueStyle(key, val)
+	if ok {
+		ct.echoColorAndBg(s, st.Fg, st.Bg)
+	}
+	return ok
+}
+
+// errorStyle returns the style of the error values.
+func (s *Theme) errorStyle() Style {
+	if st, ok := s.Values[KindError]; ok {
+		return st
+	}
+	return s.Error
+}
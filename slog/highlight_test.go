@@ -0,0 +1,140 @@
+package slog
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hedzr/is/term/color"
+)
+
+func TestKindOf(t *testing.T) {
+	for val, want := range map[any]ValueKind{
+		nil:             KindNil,
+		true:            KindBool,
+		1:               KindNumber,
+		uint8(1):        KindNumber,
+		1.5:             KindNumber,
+		time.Second:     KindDuration,
+		time.Time{}:     KindTime,
+		"s":             KindString,
+		InfoLevel:       KindString,
+		errors.New("e"): KindError,
+		struct{}{}:      KindOther,
+	} {
+		if got := kindOf(val); got != want {
+			t.Fatalf("kindOf(%v): expect %v, but got %v", val, want, got)
+		}
+	}
+}
+
+func TestNewHighlight(t *testing.T) {
+	for _, c := range []struct {
+		cond string
+		val  any
+		want bool
+	}{
+		{"", "any", true},
+		{">=500", 500, true},
+		{">=500", uint16(503), true},
+		{">=500", 404, false},
+		{">= 500", 502.5, true},
+		{"<500", "600", false},
+		{"==200", "200", true},
+		{"!=GET", "GET", false},
+		{"==GET", "GET", true},
+		{"==boom", errors.New("boom"), true},
+		{">b", "c", true},
+	} {
+		hl, err := NewHighlight("k", c.cond, Style{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := hl.Match == nil || hl.Match(c.val); got != c.want {
+			t.Fatalf("%q on %v: expect %v, but got %v", c.cond, c.val, c.want, got)
+		}
+	}
+	if _, err := NewHighlight("k", "~500", Style{}); err == nil {
+		t.Fatal("expect an error for a bad condition")
+	}
+}
+
+func TestValueHighlighting(t *testing.T) {
+	theme := ThemeDark.clone()
+	theme.Values = map[ValueKind]Style{
+		KindNumber:   {color.NewColor256(1, false), clrNone},
+		KindBool:     {color.NewColor256(2, false), clrNone},
+		KindNil:      {color.NewColor256(3, false), clrNone},
+		KindDuration: {color.NewColor256(4, false), clrNone},
+		KindString:   {color.NewColor256(5, false), clrNone},
+		KindError:    {color.NewColor256(6, false), clrNone},
+		KindGroup:    {color.NewColor256(7, false), clrNone},
+	}
+	hl, _ := NewHighlight("status", ">=500", Style{color.NewColor256(9, false), clrNone})
+	theme.Highlights = []Highlight{hl, {Key: "err", Style: Style{color.NewColor256(10, false), clrNone}}}
+
+	var buf bytes.Buffer
+	l := New("hl", WithWriter(&buf), WithLevel(InfoLevel), WithColorMode(), WithTheme(theme))
+	l.Info("hello", "n", 1, "b", true, "z", nil, "d", time.Second, "s", "str",
+		"e", errors.New("e1"), "err", errors.New("e2"), "status", 503, "ok", 200,
+		Group("g", "x", 1))
+	out := buf.String()
+	t.Logf("%q", out)
+	for _, want := range []string{
+		"=\x1b[38;5;1m1\x1b[0m",
+		"=\x1b[38;5;2mtrue\x1b[0m",
+		"=\x1b[38;5;3m<nil>\x1b[0m",
+		"=\x1b[38;5;4m\"1s\"\x1b[0m",
+		"=\x1b[38;5;5m\"str\"\x1b[0m",
+		"=\x1b[38;5;6me1\x1b[0m",
+		"=\x1b[38;5;10me2\x1b[0m",
+		"=\x1b[38;5;9m503\x1b[0m",
+		"=\x1b[38;5;1m200\x1b[0m",
+		"\x1b[38;5;7mg.\x1b[0m",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expect %q in the output, but got %q", want, out)
+		}
+	}
+
+	// no colours in plain mode
+	buf.Reset()
+	l = New("hl", WithWriter(&buf), WithLevel(InfoLevel), WithMode(ModePlain), WithTheme(theme))
+	l.Info("hello", "n", 1, "err", errors.New("e2"), Group("g", "x", 1))
+	if strings.Contains(buf.String(), "\x1b[") {
+		t.Fatalf("expect no colours, but got %q", buf.String())
+	}
+}
+
+func TestLoadThemeValues(t *testing.T) {
+	theme, err := LoadTheme([]byte(`{
+		"values": {"number": "red", "Group": "#102030"},
+		"highlights": [{"key": "status", "when": ">=500", "style": {"fg": "red", "bg": "bold"}}, {"key": "error", "style": "lightred"}]
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if theme.Values[KindNumber].Fg != color.FgRed || theme.Values[KindBool] != ThemeDark.Values[KindBool] {
+		t.Fatalf("unexpected values: %v", theme.Values)
+	}
+	if len(theme.Highlights) != 2 || !theme.Highlights[0].Match(500) || theme.Highlights[0].Match(499) || theme.Highlights[1].Match != nil {
+		t.Fatalf("unexpected highlights: %v", theme.Highlights)
+	}
+	if st, ok := theme.valueStyle("status", 500); !ok || st.Bg != color.BgBoldOrBright {
+		t.Fatalf("unexpected style: %v", st)
+	}
+	if st, _ := theme.valueStyle("status", 200); st.Fg != color.FgRed {
+		t.Fatalf("unexpected style: %v", st)
+	}
+
+	for _, data := range []string{
+		`{"values": {"unknown": "red"}}`,
+		`{"highlights": [{"key": "k", "when": "=~x", "style": "red"}]}`,
+	} {
+		if _, err = LoadTheme([]byte(data)); err == nil {
+			t.Fatalf("expect an error for %s", data)
+		}
+	}
+}
@@ -0,0 +1,199 @@
+package slog
+
+import (
+	"context"
+	"errors"
+	"slices"
+)
+
+// HookFunc is a hook which will be invoked before a record is
+// encoded. See [Entry.AddHook].
+//
+// A hook can modify the record, such as adding, removing or
+// redacting the attributes. Returning [ErrDropRecord] vetoes
+// the record. Returning other errors will be reported as a
+// warning, and the record is kept.
+type HookFunc func(ctx context.Context, rec *Record) error
+
+// WrittenFunc will be invoked after a record was written out,
+// with the final bytes and the result of the writer. See
+// [Entry.OnWritten].
+//
+// The data is valid only during the call.
+type WrittenFunc func(ctx context.Context, rec *Record, data []byte, n int, err error)
+
+// ErrDropRecord can be returned by a [HookFunc] to veto a record.
+var ErrDropRecord = errors.New("drop record")
+
+type hook struct {
+	levels []Level // nil means all levels
+	fn     HookFunc
+}
+
+// hookSet holds the hooks and the written callbacks of a logger. It
+// is replaced as a whole while adding, so the logging goroutines can
+// read it without lock.
+type hookSet struct {
+	hooks      []*hook
+	writtenFns []WrittenFunc
+}
+
+var noHooks = &hookSet{}
+
+func (s *Entry) hooks() *hookSet {
+	if hs := s.hookset.Load(); hs != nil {
+		return hs
+	}
+	return noHooks
+}
+
+// updateHooks replaces the hookSet with a copy modified by fn.
+func (s *Entry) updateHooks(fn func(hs *hookSet)) {
+	for {
+		old := s.hookset.Load()
+		hs := &hookSet{}
+		if old != nil {
+			hs.hooks, hs.writtenFns = slices.Clip(old.hooks), slices.Clip(old.writtenFns)
+		}
+		fn(hs)
+		if s.hookset.CompareAndSwap(old, hs) {
+			return
+		}
+	}
+}
+
+func (h *hook) match(lvl Level) bool {
+	return len(h.levels) == 0 || slices.Contains(h.levels, lvl)
+}
+
+// AddHook adds a hook to Default() logger. See [Entry.AddHook].
+func AddHook(levels []Level, fn HookFunc) { defaultLog.AddHook(levels, fn) }
+
+// OnWritten adds a written callback to Default() logger. See [Entry.OnWritten].
+func OnWritten(fn WrittenFunc) { defaultLog.OnWritten(fn) }
+
+// WithHook adds a hook to a logger. See [Entry.AddHook].
+func WithHook(levels []Level, fn HookFunc) Opt {
+	return func(s *Entry) {
+		s.AddHook(levels, fn)
+	}
+}
+
+// WithOnWritten adds a written callback to a logger. See [Entry.OnWritten].
+func WithOnWritten(fn WrittenFunc) Opt {
+	return func(s *Entry) {
+		s.OnWritten(fn)
+	}
+}
+
+// AddHook adds a hook which will be invoked before a record in
+// the given levels is encoded. Empty levels means all levels.
+//
+// The hooks are inherited by the child loggers. The ones of a
+// parent logger run before the ones of its children, and the
+// ones of a logger run in the order of being added.
+//
+// The hooks run without any lock held. If a hook logs something,
+// it must pass the ctx it received, such as logger.InfoContext(ctx, ...),
+// so that the hooks will be skipped for the nested record. Logging
+// without it runs into the hooks again, endlessly.
+func (s *Entry) AddHook(levels []Level, fn HookFunc) *Entry {
+	if fn != nil {
+		s.updateHooks(func(hs *hookSet) { hs.hooks = append(hs.hooks, &hook{levels: levels, fn: fn}) })
+	}
+	return s
+}
+
+// WithHook makes a child logger with the given hook.
+func (s *Entry) WithHook(levels []Level, fn HookFunc) (newLogger *Entry) {
+	return s.newChildLogger(WithHook(levels, fn))
+}
+
+// OnWritten adds a callback which will be invoked after a record
+// was written out.
+//
+// Like hooks, the callbacks are inherited by the child loggers,
+// and run without any lock held.
+func (s *Entry) OnWritten(fn WrittenFunc) *Entry {
+	if fn != nil {
+		s.updateHooks(func(hs *hookSet) { hs.writtenFns = append(hs.writtenFns, fn) })
+	}
+	return s
+}
+
+// ResetHooks removes all hooks and written callbacks of this
+// logger. The ones of its parents are kept.
+func (s *Entry) ResetHooks() *Entry {
+	s.hookset.Store(nil)
+	return s
+}
+
+type hookingKey struct{}
+
+// inHooking reports whether ctx comes from a hook or a written
+// callback, so that a nested record logged with it won't run into
+// the hooks again.
+func inHooking(ctx context.Context) bool {
+	return ctx != nil && ctx.Value(hookingKey{}) != nil
+}
+
+func (s *Entry) hooked() bool {
+	for p := s; p != nil; p = p.owner {
+		if len(p.hooks().hooks) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// runHooks invokes the hooks from root to s, it returns false if
+// the record was vetoed.
+func (s *Entry) runHooks(ctx context.Context, rec *Record) (keep bool) {
+	var chain []*hookSet
+	for p := s; p != nil; p = p.owner {
+		if hs := p.hooks(); len(hs.hooks) > 0 {
+			chain = append(chain, hs)
+		}
+	}
+
+	ctx = context.WithValue(ctx, hookingKey{}, true)
+	for i := len(chain) - 1; i >= 0; i-- {
+		for _, h := range chain[i].hooks {
+			if !h.match(rec.Level) {
+				continue
+			}
+			if err := h.fn(ctx, rec); err != nil {
+				if errors.Is(err, ErrDropRecord) {
+					return false
+				}
+				if rec.Level != WarnLevel { // don't warn on warning to avoid infinite calls
+					s.logContext(ctx, WarnLevel, false, 0, "slog hook failed", "error", err)
+				}
+			}
+		}
+	}
+	return true
+}
+
+// written invokes the written callbacks from root to s.
+func (s *Entry) written(ctx context.Context, rec *Record, pc *PrintCtx, data []byte, n int, err error) {
+	var chain []*hookSet
+	for p := s; p != nil; p = p.owner {
+		if hs := p.hooks(); len(hs.writtenFns) > 0 {
+			chain = append(chain, hs)
+		}
+	}
+	if len(chain) == 0 {
+		return
+	}
+
+	if rec == nil {
+		rec = newRecord(s, pc.lvl, pc.now, pc.stackFrame, pc.msg, pc.kvps)
+	}
+	ctx = context.WithValue(ctx, hookingKey{}, true)
+	for i := len(chain) - 1; i >= 0; i-- {
+		for _, fn := range chain[i].writtenFns {
+			fn(ctx, rec, data, n, err)
+		}
+	}
+}
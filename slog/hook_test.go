@@ -0,0 +1,176 @@
+package slog
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestAddHook(t *testing.T) {
+	var buf bytes.Buffer
+	var order []string
+
+	l := New("hook", WithWriter(&buf), WithErrorWriter(&buf), WithColorMode(false), WithLevel(InfoLevel),
+		WithHook(nil, func(ctx context.Context, rec *Record) error {
+			order = append(order, "p1")
+			for _, a := range rec.Attrs {
+				if a.Key() == "password" {
+					a.SetValue("***")
+				}
+			}
+			return nil
+		}),
+		WithHook([]Level{WarnLevel}, func(ctx context.Context, rec *Record) error {
+			order = append(order, "p2")
+			if strings.HasPrefix(rec.Msg, "veto") {
+				return ErrDropRecord
+			}
+			return nil
+		}),
+	)
+	child := l.New("child", WithWriter(&buf), WithErrorWriter(&buf)).AddHook(nil, func(ctx context.Context, rec *Record) error {
+		order = append(order, "c1")
+		rec.Attrs = append(rec.Attrs, String("hooked", "yes"))
+		// logging inside a hook with its ctx won't run into the hooks again
+		l.InfoContext(ctx, "from hook")
+		return nil
+	})
+
+	child.Warn("hello", "password", "secret")
+	if got := strings.Join(order, ","); got != "p1,p2,c1" {
+		t.Fatalf("expect hooks run from parent to child in order, but got %q", got)
+	}
+	out := buf.String()
+	if strings.Contains(out, "secret") || !strings.Contains(out, `password="***"`) {
+		t.Fatalf("expect password redacted, but got %q", out)
+	}
+	if !strings.Contains(out, "from hook") {
+		t.Fatalf("expect the nested logging from hook, but got %q", out)
+	}
+
+	buf.Reset()
+	order = nil
+	child.Info("veto is for warning only")
+	child.Warn("veto me")
+	if out = buf.String(); strings.Contains(out, "veto me") || !strings.Contains(out, "veto is for warning only") {
+		t.Fatalf("expect the warning vetoed only, but got %q", out)
+	}
+
+	buf.Reset()
+	l.AddHook(nil, func(ctx context.Context, rec *Record) error { return errors.New("bad hook") })
+	l.Info("still logged")
+	if out = buf.String(); !strings.Contains(out, "still logged") || !strings.Contains(out, "slog hook failed") {
+		t.Fatalf("expect hook error reported and the record kept, but got %q", out)
+	}
+}
+
+func TestOnWritten(t *testing.T) {
+	var buf bytes.Buffer
+	var written []string
+	var total int
+
+	l := New("written", WithWriter(&buf), WithColorMode(false), WithLevel(InfoLevel),
+		WithOnWritten(func(ctx context.Context, rec *Record, data []byte, n int, err error) {
+			written = append(written, rec.Msg+"|"+string(data))
+			total += n
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			// logging inside a written callback with its ctx won't run into the callbacks again
+			rec.Logger.InfoContext(ctx, "nested")
+		}))
+
+	l.Info("hello")
+	if len(written) != 1 || !strings.HasPrefix(written[0], "hello|") || !strings.Contains(written[0], "hello") {
+		t.Fatalf("expect written callback invoked once with final bytes, but got %v", written)
+	}
+	if total != len(strings.SplitAfter(buf.String(), "\n")[0]) {
+		t.Fatalf("expect the written bytes count %d, but got %d", len(buf.String()), total)
+	}
+}
+
+func TestAddHookOnDefault(t *testing.T) {
+	defer Default().ResetHooks()
+	defer Default().SetLevel(Default().Level())
+	Default().SetLevel(InfoLevel)
+
+	hooked := 0
+	AddHook([]Level{InfoLevel}, func(ctx context.Context, rec *Record) error {
+		hooked++
+		return nil
+	})
+	var written int
+	OnWritten(func(ctx context.Context, rec *Record, data []byte, n int, err error) { written++ })
+
+	Info("hello")
+	if hooked != 1 || written != 1 {
+		t.Fatalf("expect hooks on Default() invoked, but got %d, %d", hooked, written)
+	}
+}
+
+func TestHookLoggingWithCtx(t *testing.T) {
+	var buf bytes.Buffer
+	hooked := 0
+	var l Logger
+	l = New("reentry", WithWriter(&buf), WithErrorWriter(&buf), WithColorMode(false), WithLevel(InfoLevel),
+		WithHook(nil, func(ctx context.Context, rec *Record) error {
+			hooked++
+			l.InfoContext(ctx, "from hook") // won't run into the hooks again
+			return nil
+		}),
+		WithOnWritten(func(ctx context.Context, rec *Record, data []byte, n int, err error) {
+			l.WarnContext(ctx, "from written callback")
+		}))
+
+	l.Info("hello")
+	out := buf.String()
+	if hooked != 1 || !strings.Contains(out, "from hook") || strings.Count(out, "from written callback") != 1 {
+		t.Fatalf("expect the nested records logged once without the hooks, but got %d, %q", hooked, out)
+	}
+
+	// the other goroutines are hooked while a hook is running
+	buf.Reset()
+	hooked = 0
+	done := make(chan struct{})
+	l2 := New("other", WithWriter(io.Discard), WithLevel(InfoLevel), WithHook(nil, func(ctx context.Context, rec *Record) error {
+		if rec.Msg == "outer" {
+			go func() {
+				l.Info("concurrent")
+				close(done)
+			}()
+			<-done
+		}
+		return nil
+	}))
+	l2.Info("outer")
+	if hooked != 1 {
+		t.Fatalf("expect the record of another goroutine hooked, but got %d", hooked)
+	}
+}
+
+func TestAddHookConcurrently(t *testing.T) {
+	l := New("concurrent", WithWriter(io.Discard), WithLevel(InfoLevel))
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				l.Info("hello")
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			l.AddHook(nil, func(ctx context.Context, rec *Record) error { return nil })
+			l.OnWritten(func(ctx context.Context, rec *Record, data []byte, n int, err error) {})
+		}()
+	}
+	wg.Wait()
+	if hs := l.(*logimp).hooks(); len(hs.hooks) != 4 || len(hs.writtenFns) != 4 {
+		t.Fatalf("expect all hooks added, but got %d, %d", len(hs.hooks), len(hs.writtenFns))
+	}
+}
@@ -0,0 +1,211 @@
+package slog
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/hedzr/is"
+)
+
+// HyperlinkMode tells if the caller info and the URL values are
+// printed as the terminal hyperlinks (OSC 8) in colorful mode.
+//
+// The displayed text is not changed, so the privacy-shortened path
+// like "~/work/a.go:12" is still shown, but it can be clicked to
+// open the real file.
+type HyperlinkMode int
+
+const (
+	HyperlinkAuto   HyperlinkMode = iota // print hyperlinks if the terminal supports it, see HyperlinkSupported
+	HyperlinkNever                       // never print hyperlinks
+	HyperlinkAlways                      // always print hyperlinks in colorful mode
+)
+
+// The URL formats for SetHyperlinkFormat.
+const (
+	HyperlinkFormatFile   = "file://{host}{path}#{line}"          // the default format
+	HyperlinkFormatVSCode = "vscode://file{path}:{line}"          // open the file in VSCode
+	HyperlinkFormatCursor = "cursor://file{path}:{line}"          // open the file in Cursor
+	HyperlinkFormatIDEA   = "idea://open?file={path}&line={line}" // open the file in a JetBrains IDE
+)
+
+var (
+	hyperlinkMode    atomic.Int32 // HyperlinkMode
+	hyperlinkCache   atomic.Int32 // the result of HyperlinkSupported plus 1, zero means not detected yet
+	hyperlinkURLs    atomic.Bool
+	hyperlinkFormat  atomic.Pointer[linkFormat]
+	hyperlinkHost, _ = os.Hostname() // the host in the file:// URL
+)
+
+// SetHyperlinkMode sets whether the hyperlinks are printed. Default
+// is HyperlinkAuto, which detects the terminal at the first colorful
+// line, and again after each call of SetHyperlinkMode.
+//
+// The hyperlinks point to the real files, so you may keep the
+// privacy path regexps in debug mode by AddFlags(Lprivacypathregexp),
+// the shortened paths are still clickable.
+func SetHyperlinkMode(mode HyperlinkMode) {
+	hyperlinkMode.Store(int32(mode))
+	hyperlinkCache.Store(0)
+}
+
+// SetHyperlinkFormat sets the URL format of the caller info. The
+// placeholders {host}, {path} and {line} are replaced with the
+// hostname, the absolute path of the source file and the line
+// number. Default is HyperlinkFormatFile.
+//
+// The editor schemes, such as HyperlinkFormatVSCode, open the file
+// at the line directly.
+func SetHyperlinkFormat(format string) {
+	if format == "" {
+		format = HyperlinkFormatFile
+	}
+	f := parseLinkFormat(format)
+	hyperlinkFormat.Store(&f)
+}
+
+// SetHyperlinkURLs enables or disables the hyperlinks for the URL
+// values, the url.URL and *url.URL attributes. Default is disabled.
+func SetHyperlinkURLs(enabled bool) { hyperlinkURLs.Store(enabled) }
+
+// HyperlinkSupported reports whether the current terminal supports
+// the hyperlinks (OSC 8), by the environment variables.
+//
+// FORCE_HYPERLINK=1 or FORCE_HYPERLINK=0 overrides the detection.
+func HyperlinkSupported() bool {
+	if force, ok := os.LookupEnv("FORCE_HYPERLINK"); ok {
+		return force != "0" && force != "false"
+	}
+	if os.Getenv("CI") != "" || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	switch os.Getenv("TERM_PROGRAM") {
+	case "iTerm.app", "WezTerm", "vscode", "Hyper", "ghostty", "rio":
+		return true
+	}
+	if v, err := strconv.Atoi(os.Getenv("VTE_VERSION")); err == nil && v >= 5000 {
+		return true // GNOME Terminal, Tilix, ...
+	}
+	for _, name := range []string{"WT_SESSION", "KITTY_WINDOW_ID", "KONSOLE_VERSION", "DOMTERM", "ALACRITTY_WINDOW_ID"} {
+		if os.Getenv(name) != "" {
+			return true
+		}
+	}
+	switch os.Getenv("TERM") {
+	case "xterm-kitty", "xterm-ghostty", "foot", "foot-extra", "alacritty", "wezterm":
+		return true
+	}
+	return false
+}
+
+// hyperlinkEnabled reports whether the hyperlinks should be printed.
+func hyperlinkEnabled() bool {
+	switch HyperlinkMode(hyperlinkMode.Load()) {
+	case HyperlinkNever:
+		return false
+	case HyperlinkAlways:
+		return true
+	}
+	cached := hyperlinkCache.Load()
+	if cached == 0 {
+		cached = 1
+		if HyperlinkSupported() && is.Tty(os.Stdout) {
+			cached = 2
+		}
+		hyperlinkCache.Store(cached)
+	}
+	return cached == 2
+}
+
+// linkFormat is a parsed hyperlink format, the literal texts and
+// the placeholders alternately.
+type linkFormat []string
+
+var defaultLinkFormat = parseLinkFormat(HyperlinkFormatFile)
+
+func parseLinkFormat(format string) (f linkFormat) {
+	for {
+		pos, ph := -1, ""
+		for _, p := range []string{"{host}", "{path}", "{line}"} {
+			if i := strings.Index(format, p); i >= 0 && (pos < 0 || i < pos) {
+				pos, ph = i, p
+			}
+		}
+		if pos < 0 {
+			return append(f, format)
+		}
+		f = append(f, format[:pos], ph)
+		format = format[pos+len(ph):]
+	}
+}
+
+// fileURL returns the URL of a source file by the hyperlink format.
+func fileURL(file string, line int) string {
+	f := &defaultLinkFormat
+	if p := hyperlinkFormat.Load(); p != nil {
+		f = p
+	}
+	if !filepath.IsAbs(file) {
+		file = filepath.Join(currDir, file)
+	}
+	file = filepath.ToSlash(file)
+	if !strings.HasPrefix(file, "/") {
+		file = "/" + file // C:/a.go -> /C:/a.go
+	}
+
+	var sb strings.Builder
+	for i, part := range *f {
+		switch {
+		case i%2 == 0:
+			sb.WriteString(part)
+		case part == "{host}":
+			sb.WriteString(hyperlinkHost)
+		case part == "{path}":
+			sb.WriteString((&url.URL{Path: file}).EscapedPath())
+		default:
+			sb.WriteString(strconv.Itoa(line))
+		}
+	}
+	return sb.String()
+}
+
+// appendHyperlink writes text as a hyperlink to target.
+func (s *PrintCtx) appendHyperlink(target, text string) {
+	s.AppendString("\x1b]8;;")
+	s.AppendString(target)
+	s.AppendString("\x1b\\")
+	s.AppendString(text)
+	s.AppendString("\x1b]8;;\x1b\\")
+}
+
+// appendSourceLink writes "file:line" of the caller info src, as a
+// hyperlink if it is enabled.
+func (s *PrintCtx) appendSourceLink(src *Source, file string) {
+	link := src.abs != "" && s.IsColorfulStyle() && hyperlinkEnabled()
+	if link {
+		s.AppendString("\x1b]8;;")
+		s.AppendString(fileURL(src.abs, src.Line))
+		s.AppendString("\x1b\\")
+	}
+	s.AppendString(file)
+	s.AppendByte(':')
+	s.AppendInt(src.Line)
+	if link {
+		s.AppendString("\x1b]8;;\x1b\\")
+	}
+}
+
+// appendURLValue writes a URL value as a hyperlink, and reports
+// whether it is written.
+func (s *PrintCtx) appendURLValue(u *url.URL) bool {
+	if u == nil || !hyperlinkURLs.Load() || !s.IsColorfulStyle() || !hyperlinkEnabled() {
+		return false
+	}
+	str := u.String()
+	s.appendHyperlink(str, str)
+	return true
+}
@@ -0,0 +1,117 @@
+package slog
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHyperlink(t *testing.T) {
+	defer SaveFlagsAndMod(Lcaller)()
+	SetHyperlinkMode(HyperlinkAlways)
+	SetHyperlinkFormat(HyperlinkFormatVSCode)
+	SetHyperlinkURLs(true)
+	defer func() {
+		SetHyperlinkMode(HyperlinkAuto)
+		SetHyperlinkFormat("")
+		SetHyperlinkURLs(false)
+	}()
+
+	u, _ := url.Parse("https://example.com/a?b=1")
+	wd, _ := os.Getwd()
+
+	var buf bytes.Buffer
+	l := New("hl", WithWriter(&buf), WithLevel(InfoLevel), WithColorMode())
+	l.Info("hello", "u", u)
+	line := buf.String()
+	t.Logf("%q", line)
+	for _, want := range []string{
+		"\x1b]8;;vscode://file" + wd + "/hyperlink_test.go:29\x1b\\",
+		"hyperlink_test.go:29\x1b]8;;\x1b\\",
+		"\x1b]8;;https://example.com/a?b=1\x1b\\https://example.com/a?b=1\x1b]8;;\x1b\\",
+	} {
+		if !strings.Contains(line, want) {
+			t.Fatalf("expect %q in the output, but got %q", want, line)
+		}
+	}
+
+	for _, opt := range []Opt{WithMode(ModePlain), WithJSONMode()} {
+		buf.Reset()
+		l = New("hl", WithWriter(&buf), WithLevel(InfoLevel), opt)
+		l.Info("hello", "u", u)
+		if strings.Contains(buf.String(), "\x1b]8;;") {
+			t.Fatalf("expect no hyperlinks, but got %q", buf.String())
+		}
+	}
+
+	SetHyperlinkMode(HyperlinkNever)
+	buf.Reset()
+	l = New("hl", WithWriter(&buf), WithLevel(InfoLevel), WithColorMode())
+	l.Info("hello", "u", u)
+	if strings.Contains(buf.String(), "\x1b]8;;") {
+		t.Fatalf("expect no hyperlinks, but got %q", buf.String())
+	}
+}
+
+func TestFileURL(t *testing.T) {
+	defer SetHyperlinkFormat("")
+
+	if got, want := fileURL("/a b/c.go", 3), "file://"+hyperlinkHost+"/a%20b/c.go#3"; got != want {
+		t.Fatalf("expect %q, but got %q", want, got)
+	}
+	SetHyperlinkFormat(HyperlinkFormatIDEA)
+	if got, want := fileURL("/a/c.go", 3), "idea://open?file=/a/c.go&line=3"; got != want {
+		t.Fatalf("expect %q, but got %q", want, got)
+	}
+}
+
+func TestHyperlinkSupported(t *testing.T) {
+	for _, name := range []string{"FORCE_HYPERLINK", "CI", "TERM", "TERM_PROGRAM", "VTE_VERSION",
+		"WT_SESSION", "KITTY_WINDOW_ID", "KONSOLE_VERSION", "DOMTERM", "ALACRITTY_WINDOW_ID"} {
+		t.Setenv(name, "")
+		os.Unsetenv(name)
+	}
+	if HyperlinkSupported() {
+		t.Fatal("expect no hyperlinks without a known terminal")
+	}
+	for _, c := range []struct {
+		name, value string
+		want        bool
+	}{
+		{"TERM_PROGRAM", "iTerm.app", true},
+		{"VTE_VERSION", "4600", false},
+		{"VTE_VERSION", "6003", true},
+		{"WT_SESSION", "1", true},
+		{"TERM", "xterm-kitty", true},
+		{"FORCE_HYPERLINK", "1", true},
+		{"FORCE_HYPERLINK", "0", false},
+	} {
+		t.Setenv(c.name, c.value)
+		if got := HyperlinkSupported(); got != c.want {
+			t.Fatalf("%s=%s: expect %v, but got %v", c.name, c.value, c.want, got)
+		}
+		os.Unsetenv(c.name)
+	}
+}
+
+func TestParseLinkFormat(t *testing.T) {
+	for _, c := range []struct {
+		format string
+		want   string
+	}{
+		{HyperlinkFormatFile, `["file://" "{host}" "" "{path}" "#" "{line}" ""]`},
+		{"{line}:{path}{x}", `["" "{line}" ":" "{path}" "{x}"]`},
+		{"", `[""]`},
+	} {
+		if got := fmt.Sprintf("%q", parseLinkFormat(c.format)); got != c.want {
+			t.Fatalf("%q: expect %s, but got %s", c.format, c.want, got)
+		}
+	}
+	if got, want := fileURL("c.go", 3), "file://"+hyperlinkHost+filepath.ToSlash(filepath.Join(currDir, "c.go"))+"#3"; got != want {
+		t.Fatalf("expect %q, but got %q", want, got)
+	}
+}
@@ -74,6 +74,10 @@ type (
 
 		Level() Level // logging level associated with this logger
 
+		// Handler returns the terminal handler of this logger, which
+		// paints the records and writes them out.
+		Handler() Handler
+
 		// writeInternal(ctx context.Context, lvl Level, pc uintptr, buf []byte) (n int, err error)
 		// logContext(ctx context.Context, lvl Level, pc uintptr, msg string, args ...any)
 	}
@@ -130,8 +134,40 @@ type (
 		ResetLevelWriter(lvl Level) *Entry               // reset the writers in a level
 		ResetLevelWriters() *Entry                       // reset all leveled writers
 
+		AddModeWriter(mode Mode, w io.Writer) *Entry // add a writer which receives the lines in the given mode
+		ResetModeWriters() *Entry                    // remove all mode writers
+
 		SetValueStringer(vs ValueStringer) *Entry  //
 		WithValueStringer(vs ValueStringer) *Entry //
+
+		AddMiddleware(mws ...Middleware) *Entry      // append middlewares to the logging pipeline
+		WithMiddleware(mws ...Middleware) *Entry     // make a child logger with more middlewares
+		ResetMiddlewares() *Entry                    // remove the middlewares of this logger
+		AddRecorder(r Recorder) *Entry               // observe the records before encoding
+		WithRecorder(r Recorder) *Entry              //
+		AddHook(levels []Level, fn HookFunc) *Entry  // run fn before encoding a record
+		WithHook(levels []Level, fn HookFunc) *Entry // make a child logger with a hook
+		OnWritten(fn WrittenFunc) *Entry             // run fn after a record written out
+		ResetHooks() *Entry                          // remove the hooks and written callbacks of this logger
+
+		SetFieldNames(names FieldNames) *Entry  // set the names and the order of built-in fields
+		WithFieldNames(names FieldNames) *Entry //
+
+		SetTheme(theme *Theme) *Entry  // set the colours of colorful mode
+		WithTheme(theme *Theme) *Entry //
+
+		AddRedaction(rules ...RedactRule) *Entry  // redact the sensitive data before encoding
+		WithRedaction(rules ...RedactRule) *Entry //
+		ResetRedaction() *Entry                   // remove the redaction rules of this logger
+
+		SetLimits(limits Limits) *Entry  // set the size limits of the encoded records
+		WithLimits(limits Limits) *Entry //
+
+		SetEncoding(enc Encoding) *Entry  // set the encodings of []byte, durations, times and floats
+		WithEncoding(enc Encoding) *Entry //
+
+		SetDedupe(mode DedupeMode) *Entry  // set the strategy of the duplicated attributes
+		WithDedupe(mode DedupeMode) *Entry //
 	}
 
 	// Entries collects many Entry objects as a map
@@ -148,6 +184,12 @@ type (
 		LogAttrs(ctx context.Context, level Level, msg string, args ...any) // Attr, Attrs in args will be recognized as is
 		Logit(ctx context.Context, level Level, msg string, args ...any)    // Attr, Attrs in args will be recognized as is
 
+		EventPrinter
+
+		// Check tests level and captures the caller, it returns nil if
+		// level is disabled. See CheckedEntry.
+		Check(ctx context.Context, level Level, msg string) *CheckedEntry
+
 		// SetSkip is very similar with WithSkip but no child logger
 		// created, it modifies THIS logger.
 		//
@@ -160,6 +202,16 @@ type (
 		ColorMode() bool // return the mode
 	}
 
+	// EventPrinter starts the fluent logging records, see Event.
+	EventPrinter interface {
+		Event(ctx context.Context, lvl Level) *Event // nil if lvl is disabled
+		TraceEvent() *Event
+		DebugEvent() *Event
+		InfoEvent() *Event
+		WarnEvent() *Event
+		ErrorEvent() *Event
+	}
+
 	// LogLoggerAware for external adapters
 	LogLoggerAware interface {
 		WriteInternal(ctx context.Context, lvl Level, pc uintptr, buf []byte) (n int, err error)
@@ -145,6 +145,53 @@ func shortDurFormat(buf *[32]byte, d time.Duration, useFrac bool) int { //nolint
 	return w
 }
 
+// AppendDuration appends the text of time.Duration.String to buf,
+// without allocation.
+func AppendDuration(buf []byte, d time.Duration) []byte {
+	var arr [32]byte
+	w := len(arr)
+
+	u := uint64(d)
+	neg := d < 0
+	if neg {
+		u = -u
+	}
+
+	if u < uint64(time.Second) {
+		w = fmtSeconds(arr[:w], u, w)
+	} else {
+		w--
+		arr[w] = 's'
+
+		w, u = fmtFrac(arr[:w], u, 9)
+
+		// u is now integer seconds
+		w = fmtInt(arr[:w], u%60)
+		u /= 60
+
+		// u is now integer minutes
+		if u > 0 {
+			w--
+			arr[w] = 'm'
+			w = fmtInt(arr[:w], u%60)
+			u /= 60
+
+			// u is now integer hours
+			if u > 0 {
+				w--
+				arr[w] = 'h'
+				w = fmtInt(arr[:w], u)
+			}
+		}
+	}
+
+	if neg {
+		w--
+		arr[w] = '-'
+	}
+	return append(buf, arr[w:]...)
+}
+
 func fmtSeconds(buf []byte, u uint64, w int) (nw int) {
 	// Special case: if duration is smaller than a second,
 	// use smaller units, like 1.2ms
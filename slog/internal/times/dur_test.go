@@ -30,6 +30,18 @@ func TestSmartDurationString(t *testing.T) {
 	t.Logf("%v", SmartDurationString(d))
 }
 
+func TestAppendDuration(t *testing.T) {
+	for _, d := range []time.Duration{
+		0, 1, -15, 999, time.Microsecond + 500, 12 * time.Millisecond, time.Second,
+		-90 * time.Minute, 10*time.Hour + 11*time.Second + 13*time.Microsecond,
+		1<<63 - 1, -1 << 63,
+	} {
+		if got, want := string(AppendDuration(nil, d)), d.String(); got != want {
+			t.Fatalf("expect %q, but got %q", want, got)
+		}
+	}
+}
+
 func TestParseDuration(t *testing.T) {
 	data := []string{
 		"10h0m11.000013s",
@@ -0,0 +1,527 @@
+package slog
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/hedzr/is"
+	"github.com/hedzr/is/term/color"
+)
+
+// LayoutPainter is a [Painter] which prints the text logging lines
+// in a user-supplied layout. The layout is compiled once by
+// NewLayoutPainter.
+//
+// A layout is a text with directives in braces:
+//
+//	"{time:15:04:05.000} {level:4} [{logger}] {msg:<40} {attrs} {caller:short}"
+//
+// The fields are:
+//
+//	{time}            the timestamp in according to the flags, or {time:<go layout>}
+//	{level}           the short tag of the level, {level:N} for a N-chars tag (1-5),
+//	                  {level:name} or {level:NAME} for the full name
+//	{logger}          the logger name
+//	{msg}             the first line of the message, the rest lines are
+//	                  printed after the logging line
+//	{attrs}           the attributes
+//	{caller}          the caller file and line, {caller:short} for the base
+//	                  name of the file, {caller:func} for the function name
+//
+// A field can be aligned in a minimal width by "<N" (left), ">N"
+// (right) or "^N" (center), such as {msg:<40}. For the fields
+// having an argument, the alignment follows a '|', such as
+// {time:15:04:05|>14}.
+//
+// The colour directives, {color:red}, {color:bold}, {color:level}
+// (the colour of the level), {color:reset}, etc., are emitted only
+// if the painter is colorful. The fields are painted in their
+// default colours unless a colour directive is in effect.
+//
+// The conditional sections, {?logger}[{logger}] {/}, are printed
+// only if the field is not empty. {!field}...{/} is the negation.
+//
+// Use {{ and }} for the literal braces.
+//
+// For example:
+//
+//	logger := slog.New(slog.WithPainter(slog.MustLayoutPainter("{time:15:04:05} {level:4} {msg} {attrs}")))
+type LayoutPainter struct {
+	colorfulPainter
+	layout string
+	nodes  []layoutNode
+}
+
+var _ Painter = (*LayoutPainter)(nil)
+var _ LinePainter = (*LayoutPainter)(nil)
+
+// LayoutOpt is used by NewLayoutPainter.
+type LayoutOpt func(s *LayoutPainter)
+
+// LayoutColorful sets whether the colour directives and the default
+// colours of the fields are emitted. By default, they are emitted
+// unless is.NoColorMode() is true.
+func LayoutColorful(colorful bool) LayoutOpt {
+	return func(s *LayoutPainter) {
+		s.colorful = colorful
+	}
+}
+
+// NewLayoutPainter compiles the layout and returns a [LayoutPainter].
+func NewLayoutPainter(layout string, opts ...LayoutOpt) (*LayoutPainter, error) {
+	s := &LayoutPainter{colorfulPainter: colorfulPainter{!is.NoColorMode()}, layout: layout}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	nodes, err := compileLayout(layout)
+	if err != nil {
+		return nil, err
+	}
+	s.nodes = nodes
+	return s, nil
+}
+
+// MustLayoutPainter is like NewLayoutPainter but panics if the
+// layout cannot be compiled.
+func MustLayoutPainter(layout string, opts ...LayoutOpt) *LayoutPainter {
+	s, err := NewLayoutPainter(layout, opts...)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// Layout returns the source of the layout.
+func (s *LayoutPainter) Layout() string { return s.layout }
+
+// PaintLine implements [LinePainter].
+func (s *LayoutPainter) PaintLine(pc *PrintCtx) (holdErrorValue error) {
+	// a layout is always a text layout even if the logger is in JSON
+	// mode, so the attributes are serialized in the text style.
+	pc.mode1 = ModeColorful
+	pc.restLines, pc.eol = "", false
+
+	holdErrorValue = s.paint(pc, s.nodes)
+
+	if s.isColorful(pc) {
+		ct.echoResetColor(pc)
+	}
+	s.AddMsgFieldRestLines(pc, pc.restLines, pc.eol)
+	return
+}
+
+func (s *LayoutPainter) paint(pc *PrintCtx, nodes []layoutNode) (holdErrorValue error) {
+	for i := range nodes {
+		n := &nodes[i]
+		switch n.kind {
+		case layoutLiteral:
+			pc.AppendString(n.text)
+		case layoutColor:
+			if s.isColorful(pc) {
+				switch {
+				case n.reset:
+					ct.echoResetColor(pc)
+				case n.levelColor:
+					ct.echoColorAndBg(pc, pc.clr, pc.bg)
+				default:
+					ct.echoColor(pc, n.clr)
+				}
+			}
+		case layoutSection:
+			if s.present(pc, n.field) != n.negative {
+				if err := s.paint(pc, n.children); err != nil {
+					holdErrorValue = err
+				}
+			}
+		case layoutField:
+			start := len(pc.buf)
+			if err := s.paintField(pc, n); err != nil {
+				holdErrorValue = err
+			}
+			if n.width > 0 {
+				pc.padFrom(start, n.align, n.width)
+			}
+		}
+	}
+	return
+}
+
+func (s *LayoutPainter) present(pc *PrintCtx, f layoutFieldType) bool {
+	switch f {
+	case layoutTime:
+		return !pc.now.IsZero()
+	case layoutLogger:
+		return pc.name != ""
+	case layoutMsg:
+		return pc.msg != ""
+	case layoutAttrs:
+		return len(pc.kvps) > 0 || pc.event != nil && len(pc.event.fields) > 0
+	case layoutCaller:
+		return pc.stackFrame != 0
+	}
+	return true
+}
+
+func (s *LayoutPainter) paintField(pc *PrintCtx, n *layoutNode) (holdErrorValue error) {
+	colorful := s.isColorful(pc) && n.auto
+	switch n.field {
+	case layoutTime:
+		tm, layout := pc.timestamp(pc.now)
+		if n.arg != "" {
+			layout = n.arg
+		}
+		if colorful {
+			ct.echoColorAndBg(pc, pc.Theme().Timestamp.Fg, pc.Theme().Timestamp.Bg)
+		}
+		pc.buf = tm.AppendFormat(pc.buf, layout)
+		if colorful {
+			ct.echoResetColor(pc)
+		}
+
+	case layoutLevel:
+		var tag string
+		switch n.arg {
+		case "":
+			tag = pc.lvl.ShortTag(levelOutputWidth)
+		case "name":
+			tag = pc.lvl.String()
+		case "NAME":
+			tag = strings.ToUpper(pc.lvl.String())
+		default:
+			tag = pc.lvl.ShortTag(n.num)
+		}
+		s.appendColored(pc, colorful, pc.clr, pc.bg, tag)
+
+	case layoutLogger:
+		s.appendColored(pc, colorful, pc.Theme().Logger.Fg, pc.Theme().Logger.Bg, pc.name)
+
+	case layoutMsg:
+		var firstLine string
+		firstLine, pc.restLines, pc.eol = ct.splitFirstAndRestLines(pc.msg)
+		if s.isColorful(pc) {
+			firstLine = ct.translate(firstLine)
+		}
+		s.appendColored(pc, colorful, pc.clr, pc.bg, firstLine)
+
+	case layoutAttrs:
+		start := len(pc.buf)
+		holdErrorValue = serializeAttrs(pc, pc.kvps)
+		if len(pc.buf) > start && pc.buf[start] == ' ' {
+			pc.buf = append(pc.buf[:start], pc.buf[start+1:]...) // the leading space is up to the layout
+		}
+
+	case layoutCaller:
+		src := pc.Source()
+		if src == nil || src.File == "" {
+			break
+		}
+		if colorful {
+			ct.echoColorAndBg(pc, pc.Theme().Caller.Fg, pc.Theme().Caller.Bg)
+		}
+		switch n.arg {
+		case "func":
+			pc.AppendString(checkedfuncname(src.Function))
+		case "short":
+			pc.appendSourceLink(src, filepath.Base(src.File))
+		default:
+			pc.appendSourceLink(src, src.File)
+		}
+		if colorful {
+			ct.echoResetColor(pc)
+		}
+	}
+	return
+}
+
+func (s *LayoutPainter) appendColored(pc *PrintCtx, colorful bool, clr, bg color.Color, text string) {
+	if colorful {
+		ct.wrapColorAndBgTo(pc, clr, bg, text)
+	} else {
+		pc.AppendString(text)
+	}
+}
+
+// padFrom pads the text from start to the minimal width with the
+// given alignment, the ansi escape sequences are not counted.
+func (s *PrintCtx) padFrom(start int, align byte, width int) {
+	d := width - visibleWidth(s.buf[start:])
+	if d <= 0 {
+		return
+	}
+
+	var left int
+	switch align {
+	case '>':
+		left = d
+	case '^':
+		left = d / 2
+	}
+	if left > 0 {
+		s.buf = append(s.buf, make([]byte, left)...)
+		copy(s.buf[start+left:], s.buf[start:len(s.buf)-left])
+		for i := start; i < start+left; i++ {
+			s.buf[i] = ' '
+		}
+	}
+	s.AppendRuneTimes(' ', d-left)
+}
+
+// visibleWidth returns the count of the runes in b, excluding the
+// ansi escape sequences, the colors (CSI) and the hyperlinks (OSC).
+func visibleWidth(b []byte) (width int) {
+	for i := 0; i < len(b); {
+		if b[i] == '\x1b' && i+1 < len(b) && b[i+1] == '[' {
+			i += 2
+			for i < len(b) && (b[i] < 0x40 || b[i] > 0x7e) {
+				i++
+			}
+			i++ // the final byte
+			continue
+		}
+		if b[i] == '\x1b' && i+1 < len(b) && b[i+1] == ']' {
+			// an OSC sequence, such as a hyperlink, ends with ST (ESC \) or BEL
+			for i += 2; i < len(b); i++ {
+				if b[i] == '\a' {
+					i++
+					break
+				}
+				if b[i] == '\x1b' && i+1 < len(b) && b[i+1] == '\\' {
+					i += 2
+					break
+				}
+			}
+			continue
+		}
+		_, size := utf8.DecodeRune(b[i:])
+		i += size
+		width++
+	}
+	return
+}
+
+//
+//
+//
+
+type layoutNodeKind int
+
+const (
+	layoutLiteral layoutNodeKind = iota
+	layoutField
+	layoutColor
+	layoutSection
+)
+
+type layoutFieldType int
+
+const (
+	layoutTime layoutFieldType = iota
+	layoutLevel
+	layoutLogger
+	layoutMsg
+	layoutAttrs
+	layoutCaller
+)
+
+var layoutFieldNames = map[string]layoutFieldType{
+	"time":   layoutTime,
+	"level":  layoutLevel,
+	"logger": layoutLogger,
+	"msg":    layoutMsg,
+	"attrs":  layoutAttrs,
+	"caller": layoutCaller,
+}
+
+var layoutColors = map[string]color.Color{
+	"black":        color.FgBlack,
+	"red":          color.FgRed,
+	"green":        color.FgGreen,
+	"yellow":       color.FgYellow,
+	"blue":         color.FgBlue,
+	"magenta":      color.FgMagenta,
+	"cyan":         color.FgCyan,
+	"gray":         color.FgLightGray,
+	"lightgray":    color.FgLightGray,
+	"darkgray":     color.FgDarkGray,
+	"lightred":     color.FgLightRed,
+	"lightgreen":   color.FgLightGreen,
+	"lightyellow":  color.FgLightYellow,
+	"lightblue":    color.FgLightBlue,
+	"lightmagenta": color.FgLightMagenta,
+	"lightcyan":    color.FgLightCyan,
+	"white":        color.FgWhite,
+	"default":      color.FgDefault,
+	"bold":         color.BgBoldOrBright,
+	"dim":          color.BgDim,
+}
+
+type layoutNode struct {
+	kind layoutNodeKind
+	text string // literal
+
+	field layoutFieldType // field, section
+	arg   string          // field argument, such as the time layout
+	num   int             // the length of level tag
+	align byte            // '<', '>' or '^'
+	width int
+	auto  bool // paint in the default colour
+
+	clr        color.Color // color
+	levelColor bool
+	reset      bool
+
+	negative bool // section
+	children []layoutNode
+}
+
+// compileLayout parses a layout into nodes, see [LayoutPainter].
+func compileLayout(layout string) (nodes []layoutNode, err error) {
+	type frame struct {
+		node  layoutNode
+		outer []layoutNode
+	}
+	var stack []frame
+	var lit strings.Builder
+	colored := false // a colour directive is in effect
+
+	flush := func() {
+		if lit.Len() > 0 {
+			nodes = append(nodes, layoutNode{kind: layoutLiteral, text: lit.String()})
+			lit.Reset()
+		}
+	}
+
+	for i := 0; i < len(layout); i++ {
+		c := layout[i]
+		switch {
+		case c == '{' && i+1 < len(layout) && layout[i+1] == '{',
+			c == '}' && i+1 < len(layout) && layout[i+1] == '}':
+			lit.WriteByte(c)
+			i++
+			continue
+		case c == '}':
+			return nil, fmt.Errorf("layout: unexpected '}' at %d", i)
+		case c != '{':
+			lit.WriteByte(c)
+			continue
+		}
+
+		end := strings.IndexByte(layout[i:], '}')
+		if end < 0 {
+			return nil, fmt.Errorf("layout: unclosed '{' at %d", i)
+		}
+		directive := layout[i+1 : i+end]
+		pos := i
+		i += end
+		flush()
+
+		switch {
+		case directive == "/":
+			if len(stack) == 0 {
+				return nil, fmt.Errorf("layout: unexpected {/} at %d", pos)
+			}
+			top := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			top.node.children = nodes
+			nodes = append(top.outer, top.node)
+
+		case strings.HasPrefix(directive, "?"), strings.HasPrefix(directive, "!"):
+			f, ok := layoutFieldNames[directive[1:]]
+			if !ok {
+				return nil, fmt.Errorf("layout: unknown field %q at %d", directive[1:], pos)
+			}
+			stack = append(stack, frame{layoutNode{kind: layoutSection, field: f, negative: directive[0] == '!'}, nodes})
+			nodes = nil
+
+		case strings.HasPrefix(directive, "color:"):
+			n := layoutNode{kind: layoutColor}
+			switch name := directive[len("color:"):]; name {
+			case "reset":
+				n.reset = true
+			case "level":
+				n.levelColor = true
+			default:
+				var ok bool
+				if n.clr, ok = layoutColors[name]; !ok {
+					return nil, fmt.Errorf("layout: unknown color %q at %d", name, pos)
+				}
+			}
+			colored = !n.reset
+			nodes = append(nodes, n)
+
+		default:
+			n, e := compileLayoutField(directive)
+			if e != nil {
+				return nil, fmt.Errorf("layout: %w at %d", e, pos)
+			}
+			n.auto = !colored
+			nodes = append(nodes, n)
+		}
+	}
+	flush()
+
+	if len(stack) > 0 {
+		return nil, errors.New("layout: unclosed section, {/} is expected")
+	}
+	return
+}
+
+// compileLayoutField parses a field directive, such as "msg:<40".
+func compileLayoutField(directive string) (n layoutNode, err error) {
+	name, spec, _ := strings.Cut(directive, ":")
+	f, ok := layoutFieldNames[name]
+	if !ok {
+		return n, fmt.Errorf("unknown field %q", name)
+	}
+	n.kind, n.field = layoutField, f
+
+	if n.align, n.width, ok = parseLayoutAlign(spec); ok {
+		spec = ""
+	} else if pos := strings.LastIndexByte(spec, '|'); pos >= 0 {
+		if n.align, n.width, ok = parseLayoutAlign(spec[pos+1:]); !ok {
+			return n, fmt.Errorf("bad alignment %q", spec[pos+1:])
+		}
+		spec = spec[:pos]
+	}
+	n.arg = spec
+
+	switch f {
+	case layoutTime:
+	case layoutLevel:
+		switch spec {
+		case "", "name", "NAME":
+		default:
+			if n.num, err = strconv.Atoi(spec); err != nil || n.num <= 0 || n.num >= MaxLengthShortTag {
+				return n, fmt.Errorf("bad level tag length %q, the valid range: 1-%d", spec, MaxLengthShortTag-1)
+			}
+		}
+	case layoutCaller:
+		switch spec {
+		case "", "short", "long", "func":
+		default:
+			return n, fmt.Errorf("bad caller style %q", spec)
+		}
+	default:
+		if spec != "" {
+			return n, fmt.Errorf("unexpected argument %q for field %q", spec, name)
+		}
+	}
+	return n, nil
+}
+
+// parseLayoutAlign parses "<N", ">N" or "^N".
+func parseLayoutAlign(spec string) (align byte, width int, ok bool) {
+	if len(spec) < 2 || strings.IndexByte("<>^", spec[0]) < 0 {
+		return
+	}
+	width, err := strconv.Atoi(spec[1:])
+	if err != nil || width < 0 {
+		return 0, 0, false
+	}
+	return spec[0], width, true
+}
@@ -0,0 +1,119 @@
+package slog
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestLayoutPainter(t *testing.T) {
+	defer SaveFlagsAndMod(Lcaller)()
+
+	var buf bytes.Buffer
+	p := MustLayoutPainter("{time:15:04:05.000} {level:4} {?logger}[{logger}] {/}{msg:<12}|{?attrs} {attrs}{/} {caller:short}", LayoutColorful(false))
+	l := New("lay", WithWriter(&buf), WithJSONMode(), WithLevel(InfoLevel), WithPainter(p))
+
+	l.Info("hello", "a", 1, "b", "x")
+	line := buf.String()
+	t.Log(line)
+	re := regexp.MustCompile(`^\d\d:\d\d:\d\d\.\d{3} INFO \[lay\] hello       \| a=1 b="x" layout_test\.go:\d+\n$`)
+	if !re.MatchString(line) {
+		t.Fatalf("unexpected line %q", line)
+	}
+
+	// the sections are skipped for the empty fields
+	buf.Reset()
+	l2 := New(WithWriter(&buf), WithLevel(InfoLevel), WithPainter(p))
+	l2.Info("hi")
+	if line = buf.String(); !regexp.MustCompile(`^[\d:.]+ INFO hi          \| layout_test\.go:\d+\n$`).MatchString(line) {
+		t.Fatalf("unexpected line %q", line)
+	}
+
+	// the rest lines of the message follow the line
+	buf.Reset()
+	l2.Info("first\nsecond")
+	if line = buf.String(); !strings.Contains(line, "first") || !strings.Contains(line, "\n    second") {
+		t.Fatalf("unexpected line %q", line)
+	}
+}
+
+func TestLayoutPainterAlignAndColor(t *testing.T) {
+	var buf bytes.Buffer
+	p := MustLayoutPainter("{level:name|>6}|{msg:^9}|{!logger}-{/}{{x}}", LayoutColorful(false))
+	l := New(WithWriter(&buf), WithLevel(InfoLevel), WithPainter(p))
+	l.Info("mid")
+	if line := buf.String(); line != "  info|   mid   |-{x}\n" {
+		t.Fatalf("unexpected line %q", line)
+	}
+
+	buf.Reset()
+	p = MustLayoutPainter("{color:red}{msg:<5}{color:reset}|{logger}", LayoutColorful(true))
+	l = New("c", WithWriter(&buf), WithLevel(InfoLevel), WithPainter(p))
+	l.Info("hi")
+	line := buf.String()
+	if !strings.HasPrefix(line, "\x1b[31mhi   \x1b[0m|") {
+		t.Fatalf("expect the explicit colour and the padding ignoring escapes, but got %q", line)
+	}
+	if visibleWidth([]byte(line)) != len("hi   |c\n") {
+		t.Fatalf("expect the logger name painted in default colour, but got %q", line)
+	}
+}
+
+func TestCompileLayoutErrors(t *testing.T) {
+	for _, layout := range []string{
+		"{msg",
+		"msg}",
+		"{nope}",
+		"{?nope}{/}",
+		"{?msg}unclosed",
+		"{/}",
+		"{color:nope}",
+		"{level:9}",
+		"{caller:wide}",
+		"{logger:x}",
+		"{time:15:04|?3}",
+	} {
+		if _, err := NewLayoutPainter(layout); err == nil {
+			t.Fatalf("expect error for layout %q", layout)
+		} else {
+			t.Log(err)
+		}
+	}
+
+	if _, err := NewLayoutPainter("{time} {level} {msg} {attrs} {caller} {caller:func} {level:NAME}"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLayoutPainterHyperlinks(t *testing.T) {
+	defer SaveFlagsAndMod(Lcaller)()
+	SetHyperlinkMode(HyperlinkAlways)
+	defer SetHyperlinkMode(HyperlinkAuto)
+
+	var buf bytes.Buffer
+	p := MustLayoutPainter("{caller:short|<40}|{msg}", LayoutColorful(true))
+	l := New(WithWriter(&buf), WithLevel(InfoLevel), WithPainter(p))
+	l.Info("hi")
+	line := buf.String()
+	if !strings.Contains(line, "\x1b]8;;") {
+		t.Fatalf("expect a hyperlink of the caller, but got %q", line)
+	}
+	caller := line[:strings.Index(line, "|")]
+	if w := visibleWidth([]byte(caller)); w != 40 {
+		t.Fatalf("expect the caller padded to 40 columns, but got %d in %q", w, line)
+	}
+
+	for _, c := range []struct {
+		in   string
+		want int
+	}{
+		{"\x1b]8;;file:///a.go\x1b\\a.go:1\x1b]8;;\x1b\\", 6},
+		{"\x1b]8;;file:///a.go\aa.go:1\x1b]8;;\a", 6},
+		{"\x1b[31m\x1b]8;;u\x1b\\x\x1b]8;;\x1b\\\x1b[0m", 1},
+	} {
+		if w := visibleWidth([]byte(c.in)); w != c.want {
+			t.Fatalf("expect width %d of %q, but got %d", c.want, c.in, w)
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package slog
+
+import (
+	"strconv"
+	"unicode/utf8"
+)
+
+// Limits restricts the sizes of the encoded records, so a huge value,
+// such as a request body, cannot flood the outputs. The zero value of
+// a field means unlimited.
+//
+// The limits are enforced by [PrintCtx] while the values are encoded.
+// The truncated parts are replaced by the markers, such as
+// "…(+12345 bytes)", "…(+12 items)" and "…(+3 attrs)".
+type Limits struct {
+	MaxStringLen int // the bytes of a string or []byte value of the attributes, and of the message
+	MaxSliceLen  int // the elements of a slice, an array or a map
+	MaxDepth     int // the nesting depth of the groups and the composite values
+	MaxAttrs     int // the top-level attributes of a record
+	MaxLineBytes int // the bytes of an encoded record, roughly
+}
+
+// WithLimits sets the size [Limits] of a logger.
+//
+//	logger := slog.New(slog.WithLimits(slog.Limits{
+//		MaxStringLen: 4096,
+//		MaxSliceLen:  100,
+//		MaxLineBytes: 64 << 10,
+//	}))
+func WithLimits(limits Limits) Opt {
+	return func(s *Entry) {
+		s.SetLimits(limits)
+	}
+}
+
+// SetLimits sets the size [Limits] of this logger, they are inherited
+// by the child loggers.
+func (s *Entry) SetLimits(limits Limits) *Entry {
+	s.limits = &limits
+	return s
+}
+
+// WithLimits makes a child logger with the given [Limits].
+func (s *Entry) WithLimits(limits Limits) (newLogger *Entry) {
+	return s.newChildLogger(WithLimits(limits))
+}
+
+// Limits returns the size [Limits] of this logger or its parents.
+func (s *Entry) Limits() Limits {
+	for p := s; p != nil; p = p.owner {
+		if p.limits != nil {
+			return *p.limits
+		}
+	}
+	return Limits{}
+}
+
+// moreMarker returns the marker of n truncated units, such as
+// "…(+12 items)".
+func moreMarker(n int, unit string) string {
+	return "…(+" + strconv.Itoa(n) + " " + unit + ")"
+}
+
+// limitMarker is a marker value, which is not truncated.
+type limitMarker string
+
+// truncateText cuts str at a rune boundary to limit bytes at most, and
+// appends the marker of the truncated bytes.
+func truncateText(str string, limit int) string {
+	if len(str) <= limit {
+		return str
+	}
+	i := limit
+	for i > 0 && !utf8.RuneStart(str[i]) {
+		i--
+	}
+	return str[:i] + moreMarker(len(str)-i, "bytes")
+}
+
+// stringLimit returns the max bytes of the next string value, it is
+// the smaller one of MaxStringLen and the rest of the line budget.
+// It returns -1 if unlimited.
+func (s *PrintCtx) stringLimit() int {
+	limit := -1
+	if s.limits.MaxStringLen > 0 {
+		limit = s.limits.MaxStringLen
+	}
+	if s.limits.MaxLineBytes > 0 {
+		rest := max(s.limits.MaxLineBytes-s.Len(), 0)
+		if limit < 0 || rest < limit {
+			limit = rest
+		}
+	}
+	return limit
+}
+
+// limitString truncates a string value of the attributes to the
+// limits. The built-in fields, such as caller, are kept.
+func (s *PrintCtx) limitString(str string) string {
+	if s.attrDepth == 0 {
+		return str
+	}
+	if limit := s.stringLimit(); limit >= 0 {
+		return truncateText(str, limit)
+	}
+	return str
+}
+
+// limitBytes truncates a []byte value of the attributes to the
+// limits.
+func (s *PrintCtx) limitBytes(b []byte) []byte {
+	if s.attrDepth == 0 {
+		return b
+	}
+	if limit := s.stringLimit(); limit >= 0 && len(b) > limit {
+		i := limit
+		for i > 0 && !utf8.RuneStart(b[i]) {
+			i--
+		}
+		return append(b[:i:i], moreMarker(len(b)-i, "bytes")...)
+	}
+	return b
+}
+
+// SliceLimit returns the number of the elements to be encoded of a
+// slice of n elements, and the number of the truncated ones, in
+// according to MaxSliceLen of [Limits]. A marshaller of slice can
+// use it with AddMoreItems:
+//
+//	keep, more := enc.SliceLimit(len(v))
+//	enc.BeginArray()
+//	for i := range v[:keep] {
+//		...
+//	}
+//	enc.AddMoreItems(keep, more)
+//	enc.EndArray(false)
+func (s *PrintCtx) SliceLimit(n int) (keep, more int) {
+	if m := s.limits.MaxSliceLen; m > 0 && n > m {
+		return m, n - m
+	}
+	return n, 0
+}
+
+// AddMoreItems writes the marker of the truncated elements of an
+// array began by BeginArray, keep elements have been written. It
+// does nothing if more is zero. See SliceLimit.
+func (s *PrintCtx) AddMoreItems(keep, more int) {
+	if more <= 0 {
+		return
+	}
+	if keep > 0 {
+		s.AddComma()
+	}
+	s.AppendQuotedString(moreMarker(more, "items"))
+}
+
+// appendMoreItems writes the marker of the truncated elements into a
+// slice of the text formats, before its closing bracket.
+func (s *PrintCtx) appendMoreItems(notFirst bool, more int) {
+	if more <= 0 {
+		return
+	}
+	if notFirst {
+		s.buf = append(s.buf, ',')
+	}
+	s.AppendQuotedString(moreMarker(more, "items"))
+}
+
+// closeMoreItems inserts the marker of the truncated elements into a
+// slice which has been closed by a painter.
+func (s *PrintCtx) closeMoreItems(notFirst bool, more int) {
+	if n := len(s.buf); more > 0 && n > s.off && s.buf[n-1] == ']' {
+		s.buf = s.buf[:n-1]
+		s.appendMoreItems(notFirst, more)
+		s.buf = append(s.buf, ']')
+	}
+}
+
+// groupTooDeep reports whether a group in the attributes being
+// serialized is nested too deep.
+func (s *PrintCtx) groupTooDeep() bool {
+	return s.limits.MaxDepth > 0 && s.attrDepth > s.limits.MaxDepth
+}
+
+// attrsOver checks the limits before serializing the attribute i of
+// n, written attributes have been serialized at this level. It
+// returns the number of the rest attributes if a limit is reached.
+func (s *PrintCtx) attrsOver(i, n, written int) int {
+	if m := s.limits.MaxAttrs; m > 0 && s.attrDepth == 1 && written >= m {
+		return n - i
+	}
+	if m := s.limits.MaxLineBytes; m > 0 && s.Len() >= m {
+		s.lineFull = true
+		return n - i
+	}
+	return 0
+}
+
+// reflectDepth returns the max depth of the values encoded by
+// reflection.
+func (s *PrintCtx) reflectDepth() int {
+	if m := s.limits.MaxDepth; m > 0 && m < reflectMaxDepth {
+		return m
+	}
+	return reflectMaxDepth
+}
@@ -0,0 +1,153 @@
+package slog
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTruncateText(t *testing.T) {
+	for i, c := range []struct {
+		in    string
+		limit int
+		want  string
+	}{
+		{"hello", 5, "hello"},
+		{"hello", 3, "hel…(+2 bytes)"},
+		{"hello", 0, "…(+5 bytes)"},
+		{"中文", 4, "中…(+3 bytes)"}, // cut at a rune boundary
+	} {
+		if got := truncateText(c.in, c.limit); got != c.want {
+			t.Fatalf("%d. expect %q, but got %q", i, c.want, got)
+		}
+	}
+}
+
+func TestLimitsPainters(t *testing.T) {
+	body := strings.Repeat("x", 100)
+	for _, opt := range []Opt{
+		WithJSONMode(),
+		WithMode(ModeLogFmt),
+		WithMode(ModePlain),
+		WithColorMode(),
+		WithMode(ModeGELF),
+	} {
+		var buf bytes.Buffer
+		l := New("limits", WithWriter(&buf), WithLevel(InfoLevel), opt,
+			WithLimits(Limits{MaxStringLen: 8, MaxSliceLen: 2, MaxDepth: 1, MaxAttrs: 6}))
+		l.Info("a long message", "body", body, "raw", []byte(body),
+			"ints", []int{1, 2, 3, 4}, "durs", []time.Duration{1, 2, 3},
+			"m", map[string]int{"a": 1, "b": 2, "c": 3},
+			Group("g", "k", 1, Group("deep", "x", 1, "y", 2)),
+			"z1", 1, "z2", 2)
+		out := buf.String()
+		for _, want := range []string{
+			"a long m…(+6 bytes)",
+			"xxxxxxxx…(+92 bytes)",
+			"…(+2 items)",
+			"…(+1 items)",
+			"…(+6 attrs)", // the group deep, which is flattened to 3 attrs
+			"…(+2 attrs)", // z1 and z2
+		} {
+			if !strings.Contains(out, want) {
+				t.Fatalf("expect %q in output, but got %q", want, out)
+			}
+		}
+		if strings.Contains(out, "xxxxxxxxx") || strings.Contains(out, "z1") {
+			t.Fatalf("expect truncated output, but got %q", out)
+		}
+	}
+}
+
+func TestLimitsLine(t *testing.T) {
+	var buf bytes.Buffer
+	l := New("limits", WithWriter(&buf), WithLevel(InfoLevel), WithJSONMode(),
+		WithLimits(Limits{MaxLineBytes: 200}))
+	l.Info("msg", "a", "short", "b", strings.Repeat("z", 1000), "c", 1, Group("d", "e", 2))
+	out := buf.String()
+	if len(out) > 400 || !strings.Contains(out, `"…":"…(+2 attrs)"`) ||
+		!strings.Contains(out, `"a":"short"`) || !strings.Contains(out, "bytes)") {
+		t.Fatalf("unexpected output: %q", out)
+	}
+	var m map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &m); err != nil {
+		t.Fatalf("bad json %q: %v", out, err)
+	}
+
+	// the message cannot exceed the line
+	buf.Reset()
+	l.Info(strings.Repeat("m", 1000))
+	if out = buf.String(); len(out) > 400 || !strings.Contains(out, "…(+800 bytes)") {
+		t.Fatalf("unexpected output: %q", out)
+	}
+}
+
+func TestLimitsBinary(t *testing.T) {
+	for _, c := range []struct {
+		mode   Mode
+		toJSON func(w io.Writer, r io.Reader) error
+	}{
+		{ModeCBOR, CBORToJSON},
+		{ModeMsgPack, MsgPackToJSON},
+	} {
+		var buf bytes.Buffer
+		l := New("limits", WithWriter(&buf), WithLevel(InfoLevel), WithMode(c.mode),
+			WithLimits(Limits{MaxStringLen: 4, MaxSliceLen: 1, MaxAttrs: 4}))
+		l.Info("msg", "s", "abcdefg", "strs", []string{"a", "b"}, "times", []time.Time{{}, {}},
+			"durs", []time.Duration{1, 2, 3}, "zz", 1)
+
+		var out bytes.Buffer
+		if err := c.toJSON(&out, &buf); err != nil {
+			t.Fatalf("%v: decode failed: %v, got %q", c.mode, err, out.String())
+		}
+		var m map[string]any
+		if err := json.Unmarshal(out.Bytes(), &m); err != nil {
+			t.Fatalf("%v: bad json %q: %v", c.mode, out.String(), err)
+		}
+		if m["s"] != "abcd…(+3 bytes)" || m["…"] != "…(+1 attrs)" {
+			t.Fatalf("%v: unexpected output: %q", c.mode, out.String())
+		}
+		for _, k := range []string{"strs", "times", "durs"} {
+			if a, ok := m[k].([]any); !ok || len(a) != 2 || !strings.HasPrefix(a[1].(string), "…(+") {
+				t.Fatalf("%v: unexpected %s: %q", c.mode, k, out.String())
+			}
+		}
+	}
+}
+
+func TestLimitsReflect(t *testing.T) {
+	type node struct {
+		Name string
+		Next *node
+		Tags []string
+	}
+	n := &node{Name: "a", Next: &node{Name: "b", Next: &node{Name: "c"}}, Tags: []string{"x", "y", "z"}}
+
+	var buf bytes.Buffer
+	l := New("limits", WithWriter(&buf), WithLevel(InfoLevel), WithJSONMode(),
+		WithLimits(Limits{MaxSliceLen: 2, MaxDepth: 2}))
+	l.Info("msg", "node", n)
+	out := buf.String()
+	if !strings.Contains(out, `"Tags":["x","y","…(+1 items)"]`) ||
+		!strings.Contains(out, `"Next":"<max depth>"`) || strings.Contains(out, `"c"`) {
+		t.Fatalf("unexpected output: %q", out)
+	}
+}
+
+func TestLimitsInherit(t *testing.T) {
+	parent := New("limits", WithLimits(Limits{MaxStringLen: 10})).(*logimp)
+	child := parent.New("child")
+	if got := child.Limits(); got.MaxStringLen != 10 {
+		t.Fatalf("expect the limits of parent, but got %+v", got)
+	}
+	child.SetLimits(Limits{MaxSliceLen: 3})
+	if got := child.Limits(); got.MaxStringLen != 0 || got.MaxSliceLen != 3 {
+		t.Fatalf("expect the limits of child, but got %+v", got)
+	}
+	if got := parent.Limits(); got.MaxSliceLen != 0 {
+		t.Fatalf("expect the limits of parent kept, but got %+v", got)
+	}
+}
@@ -0,0 +1,87 @@
+package slog
+
+import (
+	"unicode/utf8"
+)
+
+// The logfmt painter writes strict logfmt: the pairs are separated
+// by spaces, the keys are always valid, and a value is either a bare
+// token or a quoted string with JSON escapes. The groups are
+// flattened to the dotted keys, such as "group.key=value".
+//
+// A key is valid if it is not empty, and it has no spaces, control
+// characters, '=' or '"'. A bare value has no spaces, control
+// characters, '=' or '"' too.
+
+// logfmtKey replaces the characters not allowed in a key with '_'.
+func logfmtKey(key string) string {
+	if validLogfmtKey(key) {
+		return key
+	}
+	if key == "" {
+		return "_"
+	}
+	b := make([]byte, 0, len(key))
+	for _, r := range key {
+		if logfmtBareRune(r) {
+			b = utf8.AppendRune(b, r)
+		} else {
+			b = append(b, '_')
+		}
+	}
+	return string(b)
+}
+
+func validLogfmtKey(key string) bool {
+	if key == "" || !utf8.ValidString(key) {
+		return false
+	}
+	for _, r := range key {
+		if !logfmtBareRune(r) {
+			return false
+		}
+	}
+	return true
+}
+
+func logfmtBareRune(r rune) bool {
+	return r > ' ' && r != '=' && r != '"' && r != 0x7f && r != utf8.RuneError
+}
+
+// validLogfmtValue reports whether v is a bare token, or a quoted
+// string.
+func validLogfmtValue(v []byte) bool {
+	if len(v) > 0 && v[0] == '"' {
+		for i := 1; i < len(v); i++ {
+			switch c := v[i]; {
+			case c == '\\':
+				i++
+			case c == '"':
+				return i == len(v)-1
+			case c < ' ':
+				return false
+			}
+		}
+		return false // not closed
+	}
+	if !utf8.Valid(v) {
+		return false
+	}
+	for _, r := range string(v) {
+		if !logfmtBareRune(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// ensureLogfmtValue quotes the value written from start if it is
+// not a valid logfmt value, such as a slice of strings, or the
+// output of a [ObjectMarshaller].
+func (s *PrintCtx) ensureLogfmtValue(start int) {
+	if v := s.buf[start:]; !validLogfmtValue(v) {
+		str := string(v)
+		s.buf = s.buf[:start]
+		s.AppendQuotedString(str)
+	}
+}
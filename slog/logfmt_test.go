@@ -0,0 +1,67 @@
+package slog
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestLogfmtKey(t *testing.T) {
+	for key, want := range map[string]string{
+		"a.b":      "a.b",
+		"":         "_",
+		"a b":      "a_b",
+		"a=b":      "a_b",
+		"\"q\"":    "_q_",
+		"tab\tx":   "tab_x",
+		"中文":       "中文",
+		"bad\xffx": "bad_x",
+	} {
+		if got := logfmtKey(key); got != want {
+			t.Fatalf("logfmtKey(%q): expect %q, but got %q", key, want, got)
+		}
+	}
+}
+
+func TestValidLogfmtValue(t *testing.T) {
+	for v, want := range map[string]bool{
+		`1`:         true,
+		`[1,2]`:     true,
+		`"a b"`:     true,
+		`"a\"b"`:    true,
+		`""`:        true,
+		``:          true,
+		`a b`:       false,
+		`["a","b"]`: false,
+		`"a"b"`:     false,
+		`"open`:     false,
+		"\"a\tb\"":  false,
+	} {
+		if got := validLogfmtValue([]byte(v)); got != want {
+			t.Fatalf("validLogfmtValue(%q): expect %v, but got %v", v, want, got)
+		}
+	}
+}
+
+func TestLogfmtStrict(t *testing.T) {
+	defer SaveFlagsAndMod(Lcaller)()
+
+	var buf bytes.Buffer
+	l := New("lf", WithWriter(&buf), WithLevel(InfoLevel), WithMode(ModeLogFmt))
+	l.Info("hello \"w\"\nsecond", "a b", 1, "s", "x=y", "strs", []string{"a", "b"},
+		Group("g", "x", "y", Group("h", "z", 2)), "after", true, "err", errors.New("boom"))
+
+	line := buf.String()
+	t.Log(line)
+	for _, want := range []string{
+		` msg="hello \"w\"\nsecond" `,
+		` s="x=y" `,
+		` strs="[\"a\",\"b\"]" `,
+		` a_b=1 s="x=y" strs="[\"a\",\"b\"]" g.x="y" g.h.z=2 after=true err="boom" `,
+	} {
+		if !strings.Contains(line, want) {
+			t.Fatalf("expect %q in the output, but got %q", want, line)
+		}
+	}
+}
@@ -0,0 +1,290 @@
+// Package logtest provides a logger for testing.
+//
+// The logger made by [New] writes the logging lines to the output
+// of testing.T, so they are shown with the owner test only, each
+// with the position of its logging call. And, it keeps every record
+// in structured form for the assertions.
+//
+//	func TestSomething(t *testing.T) {
+//		logger := logtest.New(t)
+//		doSomething(logger)
+//		logger.AssertLogged(t, slog.InfoLevel, "done", "count", 3)
+//		logger.NoErrors(t)
+//	}
+//
+// Fatal and Panic lines are intercepted and reported as test
+// failures.
+package logtest
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/hedzr/logg/slog"
+)
+
+// Logger is a [slog.Logger] which captures its records.
+type Logger struct {
+	slog.Logger
+
+	t       testing.TB
+	done    *atomic.Bool
+	mu      sync.Mutex
+	records []*slog.Record
+}
+
+// New makes a new [Logger] bound to t.
+//
+// The args are passed to [slog.New], so you can give it a name
+// or the options. The default format is plain text (logfmt
+// without colors). The caller of each line is computed by slog
+// itself, so you can find out the real position even if the
+// logging line is emitted from a helper.
+//
+// The logger stops writing to t after t finished, but its
+// records are still captured.
+//
+// The records of the child loggers (made by logger.New(...)) are
+// captured too. But the child loggers have their own writers.
+func New(t testing.TB, args ...any) *Logger {
+	t.Helper()
+
+	s := &Logger{t: t, done: new(atomic.Bool)}
+	t.Cleanup(func() { s.done.Store(true) })
+
+	w := &tWriter{t: t, done: s.done}
+	opts := []any{
+		slog.WithColorMode(false),
+		slog.WithWriter(w),
+		slog.WithErrorWriter(w),
+		slog.WithRecorder(s.record),
+	}
+	if len(args) > 0 {
+		if _, ok := args[0].(string); ok {
+			opts = append([]any{args[0]}, opts...)
+			args = args[1:]
+		}
+	}
+	s.Logger = slog.New(append(opts, args...)...)
+	return s
+}
+
+func (s *Logger) record(_ context.Context, rec *slog.Record) {
+	s.mu.Lock()
+	s.records = append(s.records, rec.Clone())
+	s.mu.Unlock()
+
+	if rec.Level == slog.FatalLevel || rec.Level == slog.PanicLevel {
+		if !s.done.Load() {
+			s.t.Errorf("logtest: %s logged: %s%s", rec.Level, rec.Msg, sourceOf(rec))
+		}
+	}
+}
+
+// Records returns a copy of the captured records.
+func (s *Logger) Records() []*slog.Record {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*slog.Record(nil), s.records...)
+}
+
+// Reset clears the captured records.
+func (s *Logger) Reset() {
+	s.mu.Lock()
+	s.records = nil
+	s.mu.Unlock()
+}
+
+// Count returns how many records were captured at the given level.
+func (s *Logger) Count(lvl slog.Level) (n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, rec := range s.records {
+		if rec.Level == lvl {
+			n++
+		}
+	}
+	return
+}
+
+// NoErrors reports a test failure if any record at ErrorLevel,
+// FailLevel, FatalLevel or PanicLevel was captured.
+func (s *Logger) NoErrors(t testing.TB) bool {
+	t.Helper()
+	var found []*slog.Record
+	for _, rec := range s.Records() {
+		switch rec.Level {
+		case slog.ErrorLevel, slog.FailLevel, slog.FatalLevel, slog.PanicLevel:
+			found = append(found, rec)
+		}
+	}
+	if len(found) == 0 {
+		return true
+	}
+	t.Errorf("logtest: expect no errors logged, but got %d:\n%s", len(found), dump(found))
+	return false
+}
+
+// AssertLogged reports a test failure if there is no record
+// which matches the given level, message and attributes.
+//
+// The kv are key-value pairs. A key of the grouped attribute
+// can be written as a dotted path, such as "group.key". The
+// values are compared by reflect.DeepEqual, and the numbers are
+// compared by their values, so int(3) matches int64(3).
+func (s *Logger) AssertLogged(t testing.TB, lvl slog.Level, msg string, kv ...any) bool {
+	t.Helper()
+	if len(kv)%2 != 0 {
+		t.Fatalf("logtest: AssertLogged wants key-value pairs, but got %d args", len(kv))
+	}
+	records := s.Records()
+	for _, rec := range records {
+		if rec.Level == lvl && rec.Msg == msg && matchAttrs(rec.Attrs, kv) {
+			return true
+		}
+	}
+	t.Errorf("logtest: no record matched (level=%s, msg=%q, attrs=%v), the captured records are:\n%s",
+		lvl, msg, kv, dump(records))
+	return false
+}
+
+func matchAttrs(attrs slog.Attrs, kv []any) bool {
+	for i := 0; i+1 < len(kv); i += 2 {
+		key, ok := kv[i].(string)
+		if !ok {
+			key = fmt.Sprint(kv[i])
+		}
+		val, found := Lookup(attrs, key)
+		if !found || !equal(val, kv[i+1]) {
+			return false
+		}
+	}
+	return true
+}
+
+// Lookup finds the value of an attribute by key. The key of a
+// grouped attribute can be a dotted path, such as "group.key".
+//
+// If the key is duplicated, the last one wins.
+func Lookup(attrs slog.Attrs, key string) (val any, found bool) {
+	for i := len(attrs) - 1; i >= 0; i-- {
+		if a := attrs[i]; a != nil && a.Key() == key {
+			return a.Value(), true
+		}
+	}
+	for i := len(attrs) - 1; i >= 0; i-- {
+		a := attrs[i]
+		if a == nil {
+			continue
+		}
+		if g, ok := a.Value().(slog.Attrs); ok && strings.HasPrefix(key, a.Key()+".") {
+			if val, found = Lookup(g, key[len(a.Key())+1:]); found {
+				return
+			}
+		}
+	}
+	return
+}
+
+func equal(a, b any) bool {
+	if reflect.DeepEqual(a, b) {
+		return true
+	}
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	if !va.IsValid() || !vb.IsValid() {
+		return false
+	}
+	switch {
+	case isInt(va) && isInt(vb):
+		return va.Int() == vb.Int()
+	case isUint(va) && isUint(vb):
+		return va.Uint() == vb.Uint()
+	case isInt(va) && isUint(vb):
+		return va.Int() >= 0 && uint64(va.Int()) == vb.Uint()
+	case isUint(va) && isInt(vb):
+		return vb.Int() >= 0 && uint64(vb.Int()) == va.Uint()
+	case isNumber(va) && isNumber(vb):
+		return toFloat(va) == toFloat(vb)
+	}
+	return false
+}
+
+func isInt(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return true
+	}
+	return false
+}
+
+func isUint(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true
+	}
+	return false
+}
+
+func isNumber(v reflect.Value) bool {
+	return isInt(v) || isUint(v) || v.Kind() == reflect.Float32 || v.Kind() == reflect.Float64
+}
+
+func toFloat(v reflect.Value) float64 {
+	switch {
+	case isInt(v):
+		return float64(v.Int())
+	case isUint(v):
+		return float64(v.Uint())
+	}
+	return v.Float()
+}
+
+func dump(records []*slog.Record) string {
+	var sb strings.Builder
+	for _, rec := range records {
+		sb.WriteString("  ")
+		sb.WriteString(rec.Level.String())
+		sb.WriteString(" ")
+		sb.WriteString(fmt.Sprintf("%q", rec.Msg))
+		for _, a := range rec.Attrs {
+			if a != nil {
+				sb.WriteString(fmt.Sprintf(" %s=%v", a.Key(), a.Value()))
+			}
+		}
+		sb.WriteString(sourceOf(rec))
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+func sourceOf(rec *slog.Record) string {
+	if src := rec.Source(); src != nil {
+		return fmt.Sprintf(" (%s:%d)", src.File, src.Line)
+	}
+	return ""
+}
+
+// tWriter writes the logging lines to the output of testing.T.
+//
+// It doesn't use t.Log with t.Helper: a line is written from deep
+// inside slog, and t.Helper marks only its direct caller, so t.Log
+// would attribute every line to this Write. The lines are written by
+// t.Output (Go 1.25, as go.mod requires) instead, which is indented
+// under the test like t.Log, and the real caller is printed by slog
+// itself at the end of each line.
+type tWriter struct {
+	t    testing.TB
+	done *atomic.Bool
+}
+
+func (w *tWriter) Write(p []byte) (n int, err error) {
+	if w.done.Load() {
+		return len(p), nil
+	}
+	return w.t.Output().Write(p)
+}
@@ -0,0 +1,96 @@
+package logtest
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hedzr/logg/slog"
+)
+
+func TestNew(t *testing.T) {
+	logger := New(t, "logtest", slog.WithLevel(slog.InfoLevel))
+
+	logger.Info("hello", "count", 3, slog.Group("grp", "k", "v", "u", uint8(7)))
+	logger.Warn("warned", "f", 1.5)
+	logger.Debug("should not be captured")
+
+	logger.AssertLogged(t, slog.InfoLevel, "hello")
+	logger.AssertLogged(t, slog.InfoLevel, "hello", "count", int64(3), "grp.k", "v", "grp.u", 7)
+	logger.AssertLogged(t, slog.WarnLevel, "warned", "f", 1.5)
+	logger.NoErrors(t)
+
+	if n := logger.Count(slog.InfoLevel); n != 1 {
+		t.Fatalf("expect 1 info record, but got %d", n)
+	}
+	if n := logger.Count(slog.DebugLevel); n != 0 {
+		t.Fatalf("expect no debug records, but got %d", n)
+	}
+
+	recs := logger.Records()
+	if len(recs) != 2 {
+		t.Fatalf("expect 2 records, but got %d", len(recs))
+	}
+	if name := recs[0].LoggerName(); name != "logtest" {
+		t.Fatalf("expect logger name 'logtest', but got %q", name)
+	}
+	if src := recs[0].Source(); src == nil || src.Line == 0 {
+		t.Fatalf("expect the source of the record, but got %v", src)
+	}
+
+	child := logger.New("child")
+	child.Info("from child")
+	logger.AssertLogged(t, slog.InfoLevel, "from child")
+
+	logger.Reset()
+	if n := len(logger.Records()); n != 0 {
+		t.Fatalf("expect no records after Reset, but got %d", n)
+	}
+}
+
+// fakeT records the failures instead of failing the real test.
+type fakeT struct {
+	testing.TB
+	errors []string
+}
+
+func (f *fakeT) Helper() {}
+func (f *fakeT) Errorf(format string, args ...any) {
+	f.errors = append(f.errors, fmt.Sprintf(format, args...))
+}
+
+func TestAssertLoggedFailed(t *testing.T) {
+	logger := New(t, slog.WithLevel(slog.InfoLevel))
+	logger.Info("hello", "count", 3)
+
+	ft := &fakeT{TB: t}
+	if logger.AssertLogged(ft, slog.InfoLevel, "hello", "count", 4) {
+		t.Fatal("expect AssertLogged failed for a mismatched value")
+	}
+	if logger.AssertLogged(ft, slog.InfoLevel, "hello", "missing", 3) {
+		t.Fatal("expect AssertLogged failed for a missing key")
+	}
+	if logger.AssertLogged(ft, slog.WarnLevel, "hello") {
+		t.Fatal("expect AssertLogged failed for a mismatched level")
+	}
+	if len(ft.errors) != 3 {
+		t.Fatalf("expect 3 failures reported, but got %d", len(ft.errors))
+	}
+
+	logger.Error("bad thing")
+	if logger.NoErrors(ft) {
+		t.Fatal("expect NoErrors failed")
+	}
+}
+
+func TestFatalIntercepted(t *testing.T) {
+	ft := &fakeT{TB: t}
+	logger := New(ft, slog.WithLevel(slog.InfoLevel))
+
+	logger.Fatal("fatal msg")
+	logger.Panic("panic msg")
+
+	if len(ft.errors) != 2 {
+		t.Fatalf("expect Fatal and Panic reported as failures, but got %v", ft.errors)
+	}
+	t.Log(ft.errors)
+}
@@ -1,10 +1,18 @@
-//go:generate stringer --type=Mode --trimprefix=Mode --linecomment
-
 package slog
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"os"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/hedzr/is"
 )
 
 type Mode int
@@ -15,78 +23,245 @@ const (
 	ModeColorful                    // color
 	ModePlain                       // plain
 	ModeUndefined = Mode(99)        // undefined
+
+	modeUserDefined = Mode(100) // the first user-defined mode, see RegisterMode
 )
 
-// func (a Mode) String() string {
-// 	t, _ := a.MarshalText()
-// 	return string(t)
-// }
+var builtinModeNames = map[Mode]string{
+	ModeJSON:      "json",
+	ModeLogFmt:    "logfmt",
+	ModeColorful:  "color",
+	ModePlain:     "plain",
+	ModeUndefined: "undefined",
+}
+
+func (a Mode) String() string {
+	if name, ok := builtinModeNames[a]; ok {
+		return name
+	}
+	if m := lookupMode(a); m != nil {
+		return m.name
+	}
+	return "Mode(" + strconv.FormatInt(int64(a), 10) + ")"
+}
 
 func (a Mode) MarshalText() (text []byte, err error) {
 	text, err = []byte(a.String()), nil
 	return
 }
 
+// UnmarshalText parses the name of a built-in or registered Mode.
 func (a *Mode) UnmarshalText(text []byte) error {
 	str := string(text)
-	for _, piece := range []struct {
-		start int
-		name  string
-		index []uint8
-	}{
-		{0, _Mode_name_0, _Mode_index_0[:]},
-	} {
-		if idx := strings.Index(piece.name, str); idx >= 0 {
-			for i, ix := range piece.index {
-				if int(ix) == idx && len(str)+idx == int(piece.index[i+1]) {
-					*a = Mode(i + piece.start)
-					return nil
-				}
+	for _, s := range []string{str, strings.ToLower(str)} {
+		for mode, name := range builtinModeNames {
+			if name == s {
+				*a = mode
+				return nil
 			}
 		}
+
+		modes.RLock()
+		mode, ok := modes.byName[s]
+		modes.RUnlock()
+		if ok {
+			*a = mode
+			return nil
+		}
+	}
+	return fmt.Errorf("Unknown Mode text %q", string(text))
+}
+
+// PainterFactory makes a [Painter] for a registered Mode.
+type PainterFactory func() Painter
+
+// ModeOpt is used by RegisterMode.
+type ModeOpt func(m *modeEntry)
+
+// ModeAutoSelect gives a detector to a registered Mode. If
+// LsmartJSONMode is set, the first registered Mode whose detector
+// returns true will be used for the loggers in ModeColorful or
+// ModePlain.
+func ModeAutoSelect(detect func() bool) ModeOpt {
+	return func(m *modeEntry) {
+		m.detect = detect
+	}
+}
+
+type modeEntry struct {
+	mode    Mode
+	name    string
+	factory PainterFactory
+	detect  func() bool
+
+	once    sync.Once
+	painter Painter
+}
+
+// getPainter returns the shared painter instance of this mode.
+func (m *modeEntry) getPainter() Painter {
+	m.once.Do(func() { m.painter = m.factory() })
+	return m.painter
+}
+
+var modes struct {
+	sync.RWMutex
+	byMode map[Mode]*modeEntry
+	byName map[string]Mode
+	order  []*modeEntry
+	next   Mode
+}
+
+// RegisterMode registers a user-defined format and returns its Mode
+// value, which can be used with SetMode, WithMode, Mode.UnmarshalText
+// (such as loading from config files) and AddModeWriter.
+//
+// The painter made by factory will be shared by all loggers in
+// this mode, so it must be safe for concurrent use. The states
+// of a logging line should be kept in [PrintCtx].
+//
+// The lines in a registered mode are built in the structured way
+// like ModeJSON and ModeLogFmt: the built-in fields and the
+// attributes are printed in the order of [FieldNames].
+//
+// For example:
+//
+//	var ModeMine, _ = slog.RegisterMode("mine", func() slog.Painter { return &myPainter{} })
+//	logger := slog.New(slog.WithMode(ModeMine))
+func RegisterMode(name string, factory PainterFactory, opts ...ModeOpt) (Mode, error) {
+	if name == "" || factory == nil {
+		return ModeUndefined, errors.New("RegisterMode: name and factory are required")
+	}
+
+	modes.Lock()
+	defer modes.Unlock()
+
+	if _, ok := modes.byName[name]; ok {
+		return ModeUndefined, fmt.Errorf("RegisterMode: mode %q exists already", name)
+	}
+	for _, n := range builtinModeNames {
+		if n == name {
+			return ModeUndefined, fmt.Errorf("RegisterMode: mode %q exists already", name)
+		}
 	}
 
-	lastPiece := _Mode_name_1
-	lastMode := ModeUndefined
-	if str == lastPiece {
-		*a = lastMode
+	if modes.byMode == nil {
+		modes.byMode, modes.byName, modes.next = make(map[Mode]*modeEntry), make(map[string]Mode), modeUserDefined
+	}
+	e := &modeEntry{mode: modes.next, name: name, factory: factory}
+	for _, opt := range opts {
+		opt(e)
+	}
+	modes.byMode[e.mode], modes.byName[name] = e, e.mode
+	modes.order = append(modes.order, e)
+	modes.next++
+
+	smartModeCache.Store(0) // detect again
+	return e.mode, nil
+}
+
+func lookupMode(mode Mode) *modeEntry {
+	if mode < modeUserDefined {
 		return nil
 	}
+	modes.RLock()
+	defer modes.RUnlock()
+	return modes.byMode[mode]
+}
 
-	return fmt.Errorf("Unknown Mode text %q", string(text))
+// smartModeCache holds the auto-selected mode plus 1, zero means
+// not detected yet.
+var smartModeCache atomic.Int64
+
+// smartMode returns the auto-selected mode for a logger in
+// ModeColorful or ModePlain if LsmartJSONMode is set.
+//
+// The registered modes with a detector are tried first (see
+// ModeAutoSelect), and ModeJSON will be used if the standard
+// output is not a terminal.
+func smartMode(mode Mode) Mode {
+	if (mode != ModeColorful && mode != ModePlain) || !IsAnyBitsSet(LsmartJSONMode) {
+		return mode
+	}
+
+	cached := Mode(smartModeCache.Load() - 1)
+	if cached < 0 {
+		cached = detectMode()
+		smartModeCache.Store(int64(cached) + 1)
+	}
+	if cached == ModeUndefined {
+		return mode
+	}
+	return cached
+}
+
+func detectMode() Mode {
+	modes.RLock()
+	order := modes.order
+	modes.RUnlock()
+	for _, m := range order {
+		if m.detect != nil && m.detect() {
+			return m.mode
+		}
+	}
+	if !is.Tty(os.Stdout) {
+		return ModeJSON
+	}
+	return ModeUndefined
 }
 
-// func (a Mode) MarshalText() (text []byte, err error) {
-// 	var str string
-// 	switch a {
-// 	case ModeJSON:
-// 		str = "json"
-// 	case ModeLogFmt:
-// 		str = "logfmt"
-// 	case ModeColorful:
-// 		str = "color"
-// 	case ModePlain:
-// 		str = "plain"
-// 	case ModeUndefined:
-// 		str = "undefined"
-// 	default:
-// 		str = fmt.Sprintf("Mode(%d)", int(a))
-// 	}
-// 	return []byte(str), nil
-// }
-
-// func (a *Mode) UnmarshalText(text []byte) error {
-// 	switch strings.ToLower(string(text)) {
-// 	case "json":
-// 		*a = ModeJSON
-// 	case "logfmt":
-// 		*a = ModeLogFmt
-// 	case "text", "plain":
-// 		*a = ModePlain
-// 	case "color":
-// 		*a = ModeColorful
-// 	case "undefined", "unk", "unknown":
-// 		*a = ModeUndefined
-// 	}
-// 	return fmt.Errorf("Unknown Mode text %q", string(text))
-// }
+// modeWriter is a writer which wants the logging lines in its own mode.
+type modeWriter struct {
+	mode Mode
+	w    io.Writer
+}
+
+// AddModeWriter adds a writer which receives the logging lines in
+// the given mode, regardless of the mode of the logger.
+//
+// For example, printing colorful lines to console and JSON lines
+// to a file:
+//
+//	logger := slog.New(slog.WithColorMode(), slog.AddModeWriter(slog.ModeJSON, file))
+func AddModeWriter(mode Mode, w io.Writer) Opt {
+	return func(s *Entry) {
+		s.AddModeWriter(mode, w)
+	}
+}
+
+// AddModeWriter adds a writer which receives the logging lines in
+// the given mode. See also [AddModeWriter].
+//
+// Like the other writers, the mode writers are not inherited by
+// the child loggers.
+func (s *Entry) AddModeWriter(mode Mode, w io.Writer) *Entry {
+	if w != nil {
+		s.modeWriters = append(s.modeWriters, &modeWriter{mode, w})
+	}
+	return s
+}
+
+// ResetModeWriters removes all mode writers of this logger.
+func (s *Entry) ResetModeWriters() *Entry {
+	s.modeWriters = nil
+	return s
+}
+
+// printModeWriters renders the logging line again for each mode
+// writer.
+func (s *Entry) printModeWriters(ctx context.Context, lvl Level, timestamp time.Time, stackFrame uintptr, msg string, kvps Attrs) {
+	for _, mw := range s.modeWriters {
+		pc := poolPrintCtx.Get().(*PrintCtx)
+		pc.set(s, lvl, timestamp, stackFrame, msg, kvps)
+		pc.SetMode(mw.mode)
+
+		data := s.render(ctx, pc)
+		n, err := mw.w.Write(data)
+		collectWrittenBytes(n)
+		pc.putBack()
+
+		if err != nil && lvl != WarnLevel { // don't warn on warning to avoid infinite calls
+			s.Warn("slog print log failed", "error", err)
+		}
+	}
+}
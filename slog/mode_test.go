@@ -1,6 +1,12 @@
 package slog
 
-import "testing"
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
 
 func TestMode(t *testing.T) {
 	var strctr []string
@@ -22,3 +28,108 @@ func TestMode(t *testing.T) {
 
 	t.Logf("\n")
 }
+
+// upperPainter is a JSON painter which prints the message in upper case.
+type upperPainter struct{ jsonPainter }
+
+func (s *upperPainter) AddMsgField(pc *PrintCtx, msg string) {
+	pc.AddString(pc.FieldNames().message(), strings.ToUpper(pc.msg))
+}
+
+// registered once, so the tests can run with -count=N
+var (
+	modeUpper, errModeUpper = RegisterMode("upper", func() Painter { return &upperPainter{} })
+
+	smartDetected           atomic.Bool
+	modeSmart, errModeSmart = RegisterMode("smart", func() Painter { return &upperPainter{} }, ModeAutoSelect(func() bool {
+		smartDetected.Store(true)
+		return true
+	}))
+)
+
+func TestRegisterMode(t *testing.T) {
+	mode, err := modeUpper, errModeUpper
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode < modeUserDefined || mode.String() != "upper" {
+		t.Fatalf("bad registered mode: %d, %q", int(mode), mode)
+	}
+	if _, err = RegisterMode("upper", func() Painter { return &upperPainter{} }); err == nil {
+		t.Fatal("expect an error for duplicated mode")
+	}
+	if _, err = RegisterMode("json", func() Painter { return &upperPainter{} }); err == nil {
+		t.Fatal("expect an error for built-in mode")
+	}
+
+	var m Mode
+	if err = m.UnmarshalText([]byte("upper")); err != nil || m != mode {
+		t.Fatalf("UnmarshalText failed: %v, %v", m, err)
+	}
+	if err = m.UnmarshalText([]byte("JSON")); err != nil || m != ModeJSON {
+		t.Fatalf("UnmarshalText failed: %v, %v", m, err)
+	}
+	if err = m.UnmarshalText([]byte("not-registered")); err == nil {
+		t.Fatal("expect an error for unknown mode")
+	}
+
+	var buf bytes.Buffer
+	New("upper", WithWriter(&buf), WithMode(mode), WithLevel(InfoLevel)).Info("hello")
+	if line := buf.String(); !strings.Contains(line, `"msg":"HELLO"`) {
+		t.Fatalf("expect the registered painter used, but got %q", line)
+	}
+}
+
+func TestAddModeWriter(t *testing.T) {
+	var plain, js bytes.Buffer
+	l := New("mw", WithWriter(&plain), WithColorMode(false), WithLevel(InfoLevel), AddModeWriter(ModeJSON, &js))
+	l.Info("hello", "a", 1)
+
+	if line := plain.String(); !strings.Contains(line, "hello") || strings.Contains(line, `"msg"`) {
+		t.Fatalf("expect a plain line, but got %q", line)
+	}
+	line := js.String()
+	if !json.Valid([]byte(line)) || !strings.Contains(line, `"msg":"hello"`) || !strings.Contains(line, `"a":1`) {
+		t.Fatalf("expect a JSON line, but got %q", line)
+	}
+
+	l.ResetModeWriters()
+	js.Reset()
+	l.Info("again")
+	if js.Len() != 0 {
+		t.Fatalf("expect no mode writers, but got %q", js.String())
+	}
+}
+
+// clearCIEnv hides the environment variables of CI systems, so that
+// the registered modes for them are not selected.
+func clearCIEnv(t *testing.T) {
+	for _, k := range []string{"GITHUB_ACTIONS", "GITLAB_CI", "TEAMCITY_VERSION", "K_SERVICE", "FUNCTION_TARGET"} {
+		t.Setenv(k, "")
+	}
+	smartModeCache.Store(0)
+	t.Cleanup(func() { smartModeCache.Store(0) })
+}
+
+func TestSmartMode(t *testing.T) {
+	defer SaveFlagsAndMod(LsmartJSONMode)()
+	clearCIEnv(t)
+
+	mode, err := modeSmart, errModeSmart
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	New("smart", WithWriter(&buf), WithColorMode(false), WithLevel(InfoLevel)).Info("hello")
+	if !smartDetected.Load() || !strings.Contains(buf.String(), `"msg":"HELLO"`) {
+		t.Fatalf("expect mode %q auto selected, but got %q", mode, buf.String())
+	}
+
+	// the explicit modes are kept
+	buf.Reset()
+	New("smart", WithWriter(&buf), WithMode(ModeLogFmt), WithLevel(InfoLevel)).Info("hello")
+	if !strings.Contains(buf.String(), `msg="hello"`) {
+		t.Fatalf("expect logfmt mode kept, but got %q", buf.String())
+	}
+}
@@ -0,0 +1,169 @@
+package slog
+
+import (
+	"encoding/binary"
+	"math"
+	"time"
+)
+
+// ModeMsgPack prints each logging line as a MessagePack map, with
+// the same logical structure as ModeJSON. The integers and the
+// floats are typed, and the times are the timestamp extension
+// type (-1).
+//
+// The lines are concatenated objects without delimiters, use
+// MsgPackToJSON to read them.
+var ModeMsgPack, _ = RegisterMode("msgpack", func() Painter { return NewMsgPackPainter() })
+
+// NewMsgPackPainter returns the painter of ModeMsgPack.
+func NewMsgPackPainter() Painter {
+	return &binaryPainter{f: msgpackFormat{}}
+}
+
+// The formats of MessagePack.
+const (
+	mpNil      = 0xc0
+	mpFalse    = 0xc2
+	mpTrue     = 0xc3
+	mpBin8     = 0xc4
+	mpBin16    = 0xc5
+	mpBin32    = 0xc6
+	mpExt8     = 0xc7
+	mpExt16    = 0xc8
+	mpExt32    = 0xc9
+	mpFloat32  = 0xca
+	mpFloat64  = 0xcb
+	mpUint8    = 0xcc
+	mpUint16   = 0xcd
+	mpUint32   = 0xce
+	mpUint64   = 0xcf
+	mpInt8     = 0xd0
+	mpInt16    = 0xd1
+	mpInt32    = 0xd2
+	mpInt64    = 0xd3
+	mpFixExt1  = 0xd4
+	mpFixExt2  = 0xd5
+	mpFixExt4  = 0xd6
+	mpFixExt8  = 0xd7
+	mpFixExt16 = 0xd8
+	mpStr8     = 0xd9
+	mpStr16    = 0xda
+	mpStr32    = 0xdb
+	mpArray16  = 0xdc
+	mpArray32  = 0xdd
+	mpMap16    = 0xde
+	mpMap32    = 0xdf
+
+	mpFixMap   = 0x80
+	mpFixArray = 0x90
+	mpFixStr   = 0xa0
+
+	mpExtTimestamp = 0xff // -1
+)
+
+type msgpackFormat struct{}
+
+func (f msgpackFormat) appendNil(b []byte) []byte { return append(b, mpNil) }
+
+func (f msgpackFormat) appendBool(b []byte, v bool) []byte {
+	if v {
+		return append(b, mpTrue)
+	}
+	return append(b, mpFalse)
+}
+
+func (f msgpackFormat) appendInt(b []byte, v int64) []byte {
+	switch {
+	case v >= 0:
+		return f.appendUint(b, uint64(v))
+	case v >= -32:
+		return append(b, byte(v)) // negative fixint
+	case v >= math.MinInt8:
+		return append(b, mpInt8, byte(v))
+	case v >= math.MinInt16:
+		return binary.BigEndian.AppendUint16(append(b, mpInt16), uint16(v))
+	case v >= math.MinInt32:
+		return binary.BigEndian.AppendUint32(append(b, mpInt32), uint32(v))
+	}
+	return binary.BigEndian.AppendUint64(append(b, mpInt64), uint64(v))
+}
+
+func (f msgpackFormat) appendUint(b []byte, v uint64) []byte {
+	switch {
+	case v <= math.MaxInt8:
+		return append(b, byte(v)) // positive fixint
+	case v <= math.MaxUint8:
+		return append(b, mpUint8, byte(v))
+	case v <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, mpUint16), uint16(v))
+	case v <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(b, mpUint32), uint32(v))
+	}
+	return binary.BigEndian.AppendUint64(append(b, mpUint64), v)
+}
+
+func (f msgpackFormat) appendFloat(b []byte, v float64, bitSize int) []byte {
+	if bitSize == 32 {
+		return binary.BigEndian.AppendUint32(append(b, mpFloat32), math.Float32bits(float32(v)))
+	}
+	return binary.BigEndian.AppendUint64(append(b, mpFloat64), math.Float64bits(v))
+}
+
+func (f msgpackFormat) appendString(b []byte, v string) []byte {
+	switch n := len(v); {
+	case n < 32:
+		b = append(b, mpFixStr|byte(n))
+	case n <= math.MaxUint8:
+		b = append(b, mpStr8, byte(n))
+	case n <= math.MaxUint16:
+		b = binary.BigEndian.AppendUint16(append(b, mpStr16), uint16(n))
+	default:
+		b = binary.BigEndian.AppendUint32(append(b, mpStr32), uint32(n))
+	}
+	return append(b, v...)
+}
+
+func (f msgpackFormat) appendBytes(b []byte, v []byte) []byte {
+	switch n := len(v); {
+	case n <= math.MaxUint8:
+		b = append(b, mpBin8, byte(n))
+	case n <= math.MaxUint16:
+		b = binary.BigEndian.AppendUint16(append(b, mpBin16), uint16(n))
+	default:
+		b = binary.BigEndian.AppendUint32(append(b, mpBin32), uint32(n))
+	}
+	return append(b, v...)
+}
+
+// appendTime writes the timestamp extension type in the shortest
+// form: timestamp 32, 64 or 96.
+func (f msgpackFormat) appendTime(b []byte, v time.Time) []byte {
+	sec, ns := v.Unix(), uint64(v.Nanosecond())
+	if uint64(sec)>>34 == 0 {
+		if ns == 0 && sec <= math.MaxUint32 {
+			return binary.BigEndian.AppendUint32(append(b, mpFixExt4, mpExtTimestamp), uint32(sec))
+		}
+		return binary.BigEndian.AppendUint64(append(b, mpFixExt8, mpExtTimestamp), ns<<34|uint64(sec))
+	}
+	b = binary.BigEndian.AppendUint32(append(b, mpExt8, 12, mpExtTimestamp), uint32(ns))
+	return binary.BigEndian.AppendUint64(b, uint64(sec))
+}
+
+func (f msgpackFormat) appendHeader(b []byte, array bool, n int) []byte {
+	if array {
+		switch {
+		case n < 16:
+			return append(b, mpFixArray|byte(n))
+		case n <= math.MaxUint16:
+			return binary.BigEndian.AppendUint16(append(b, mpArray16), uint16(n))
+		}
+		return binary.BigEndian.AppendUint32(append(b, mpArray32), uint32(n))
+	}
+	switch {
+	case n < 16:
+		return append(b, mpFixMap|byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, mpMap16), uint16(n))
+	}
+	return binary.BigEndian.AppendUint32(append(b, mpMap32), uint32(n))
+}
@@ -45,6 +45,16 @@ type Painter interface {
 	MarshalValue(pc *PrintCtx, val any) (handled bool, err error)
 }
 
+// LinePainter can be implemented by a [Painter] to paint the whole
+// logging line by itself, instead of the built-in layouts. The
+// returned error value will be dumped after the line, just like
+// the error values in the attributes.
+//
+// See [LayoutPainter].
+type LinePainter interface {
+	PaintLine(pc *PrintCtx) (holdErrorValue error)
+}
+
 var _ Painter = (*colorfulPainter)(nil)
 var _ Painter = (*logfmtPainter)(nil)
 var _ Painter = (*jsonPainter)(nil)
@@ -53,29 +63,36 @@ type colorfulPainter struct{ colorful bool }
 
 func (s *colorfulPainter) Colorful() bool { return s.colorful }
 
+// isColorful reports whether the colours are emitted, a Plain theme
+// turns them off.
+func (s *colorfulPainter) isColorful(pc *PrintCtx) bool { return s.colorful && !pc.Theme().Plain }
+
 func (s *colorfulPainter) AddMsgField(pc *PrintCtx, msg string) {
-	pc.AddString(messageFieldName, ct.translate(pc.msg))
+	pc.AddString(pc.FieldNames().message(), ct.translate(pc.msg))
 }
 
 func (s *logfmtPainter) AddMsgField(pc *PrintCtx, msg string) {
-	pc.AddString(messageFieldName, pc.msg)
+	pc.AddString(pc.FieldNames().message(), pc.msg)
 }
 
 func (s *jsonPainter) AddMsgField(pc *PrintCtx, msg string) {
-	pc.AddString(messageFieldName, pc.msg)
+	pc.AddString(pc.FieldNames().message(), pc.msg)
 }
 
 func (s *colorfulPainter) AddMsgFieldFirstLine(pc *PrintCtx, firstLine string) {
 	if minimalMessageWidth > 0 {
-		str := ct.rightPad(firstLine, " ", minimalMessageWidth)
-		if s.colorful {
-			str = ct.translate(str)
+		if s.isColorful(pc) {
+			str := ct.translate(ct.rightPad(firstLine, " ", minimalMessageWidth))
 			_, _ = pc.WriteString(ct.wrapColorAndBg(str, pc.clr, pc.bg))
 		} else {
-			_, _ = pc.WriteString(str)
+			// pad in place, without building the padded string
+			_, _ = pc.WriteString(firstLine)
+			for i := len(firstLine); i < minimalMessageWidth; i++ {
+				pc.buf = append(pc.buf, ' ')
+			}
 		}
 	} else {
-		if s.colorful {
+		if s.isColorful(pc) {
 			str := ct.translate(firstLine)
 			_, _ = pc.WriteString(ct.wrapColorAndBg(str, pc.clr, pc.bg))
 		} else {
@@ -88,7 +105,7 @@ func (s *colorfulPainter) AddMsgFieldRestLines(pc *PrintCtx, restLines string, e
 	if restLines != "" {
 		pc.AppendByte('\n')
 		pc.AppendString(ct.padFunc(pc.restLines, " ", 4, func(i int, line string) string {
-			if s.colorful {
+			if s.isColorful(pc) {
 				return ct.wrapColorAndBg(line, pc.clr, pc.bg)
 			} else {
 				return line
@@ -213,7 +230,7 @@ func (s *colorfulPainter) AppendDuration(pc *PrintCtx, z time.Duration) {
 	// s.pcAppendByte('"')
 	// s.appendEscapedJSONString(z.String())
 	// s.pcAppendByte('"')
-	pc.AppendQuotedString(z.String())
+	pc.appendDurationString(z)
 }
 
 func (s *colorfulPainter) AppendDurationSlice(pc *PrintCtx, z []time.Duration) {
@@ -299,7 +316,16 @@ func (s *colorfulPainter) AppendStringKey(pc *PrintCtx, str string) {
 
 func (s *colorfulPainter) AppendKey(pc *PrintCtx, key string, clr, bg color.Color) {
 	if pc.IsColorfulStyle() {
-		ct.echoColorAndBg(pc, clrAttrKey, clrAttrKeyBg)
+		theme := pc.Theme()
+		if st, ok := theme.Values[KindGroup]; ok && pc.prefix != "" && len(key) > len(pc.prefix) &&
+			key[len(pc.prefix)] == '.' && key[:len(pc.prefix)] == pc.prefix {
+			// the group names of a dotted key
+			ct.echoColorAndBg(pc, st.Fg, st.Bg)
+			s.AppendStringKey(pc, key[:len(pc.prefix)+1])
+			ct.echoResetColor(pc)
+			key = key[len(pc.prefix)+1:]
+		}
+		ct.echoColorAndBg(pc, theme.AttrKey.Fg, theme.AttrKey.Bg)
 		s.AppendStringKey(pc, key)
 		ct.echoColorAndBg(pc, clr, bg)
 	} else {
@@ -309,7 +335,14 @@ func (s *colorfulPainter) AppendKey(pc *PrintCtx, key string, clr, bg color.Colo
 }
 
 func (s *colorfulPainter) AppendError(pc *PrintCtx, err error) {
-	ct.echoColor(pc, clrError)
+	if !pc.IsColorfulStyle() {
+		s.TryQuoteValue(pc, err.Error())
+		return
+	}
+	if !pc.valueStyled { // or a highlight is in effect
+		st := pc.Theme().errorStyle()
+		ct.echoColorAndBg(pc, st.Fg, st.Bg)
+	}
 	s.TryQuoteValue(pc, err.Error())
 	ct.echoResetColor(pc)
 }
@@ -326,7 +359,7 @@ func (s *colorfulPainter) AppendErrorAfterPrinted(pc *PrintCtx, err error) {
 			pc.cachedSource.Extract(uintptr(frame))
 			s.AppendStringKey(pc, "       error: ")
 			if pc.IsColorfulStyle() {
-				ct.wrapColorAndBgTo(pc, clrError, clrNone, f.Error())
+				ct.wrapColorAndBgTo(pc, pc.Theme().Error.Fg, pc.Theme().Error.Bg, f.Error())
 			} else {
 				pc.AppendString(f.Error())
 			}
@@ -338,7 +371,7 @@ func (s *colorfulPainter) AppendErrorAfterPrinted(pc *PrintCtx, err error) {
 			pc.AppendByte('\n')
 			s.AppendStringKey(pc, "    function: ")
 			if pc.IsColorfulStyle() {
-				ct.wrapColorAndBgTo(pc, clrFuncName, clrNone, pc.cachedSource.Function)
+				ct.wrapColorAndBgTo(pc, pc.Theme().Caller.Fg, pc.Theme().Caller.Bg, pc.cachedSource.Function)
 			} else {
 				pc.AppendString(pc.cachedSource.Function)
 			}
@@ -359,7 +392,7 @@ func (s *colorfulPainter) AppendErrorAfterPrinted(pc *PrintCtx, err error) {
 		pc.AppendByte('\n')
 		txt := ct.pad(stackInfo, "    ", 1)
 		if pc.IsColorfulStyle() {
-			ct.wrapColorAndBgTo(pc, clrError, clrLoggerNameBg, txt)
+			ct.wrapColorAndBgTo(pc, pc.Theme().Error.Fg, pc.Theme().Error.Bg, txt)
 		} else {
 			pc.AppendString(txt)
 		}
@@ -368,7 +401,7 @@ func (s *colorfulPainter) AppendErrorAfterPrinted(pc *PrintCtx, err error) {
 
 func (s *colorfulPainter) AddTimestampField(pc *PrintCtx, tm time.Time) {
 	if pc.IsColorfulStyle() {
-		ct.echoColor(pc, clrTimestamp)
+		ct.echoColorAndBg(pc, pc.Theme().Timestamp.Fg, pc.Theme().Timestamp.Bg)
 	}
 	pc.AppendTimestamp(pc.now)
 	pc.AppendByte(' ')
@@ -380,8 +413,8 @@ func (s *colorfulPainter) AddLoggerNameField(pc *PrintCtx, name string) {
 		pc.AppendRuneTimes(' ', r)
 	}
 
-	if s.colorful {
-		ct.wrapColorAndBgTo(pc, clrLoggerName, clrLoggerNameBg, name)
+	if s.isColorful(pc) {
+		ct.wrapColorAndBgTo(pc, pc.Theme().Logger.Fg, pc.Theme().Logger.Bg, name)
 	} else {
 		pc.AppendString(name)
 	}
@@ -390,7 +423,7 @@ func (s *colorfulPainter) AddLoggerNameField(pc *PrintCtx, name string) {
 }
 
 func (s *colorfulPainter) AddSeverity(pc *PrintCtx, lvl Level) {
-	if s.colorful {
+	if s.isColorful(pc) {
 		ct.wrapColorAndBgTo(pc, pc.clr, pc.bg, ct.wrapRune(lvl.ShortTag(levelOutputWidth), '[', ']'))
 	} else {
 		pc.AppendString(ct.wrapRune(lvl.ShortTag(levelOutputWidth), '[', ']'))
@@ -401,14 +434,12 @@ func (s *colorfulPainter) AddSeverity(pc *PrintCtx, lvl Level) {
 func (s *colorfulPainter) AddPCField(pc *PrintCtx, source *Source) {
 	pc.AppendByte(' ')
 	// pc.appendRune('(')
-	pc.AppendString(source.File)
-	pc.AppendByte(':')
-	pc.AppendInt(source.Line)
+	pc.appendSourceLink(source, source.File)
 	// pc.appendRune(')')
 	pc.AppendByte(' ')
 	// ct.wrapDimColorTo(pc.SB, source.checkedfuncname()) // clion p-term in run panel cannot support dim color.
-	if s.colorful {
-		ct.wrapColorTo(pc, clrFuncName, checkedfuncname(source.Function))
+	if s.isColorful(pc) {
+		ct.wrapColorAndBgTo(pc, pc.Theme().Caller.Fg, pc.Theme().Caller.Bg, checkedfuncname(source.Function))
 		ct.echoResetColor(pc)
 	} else {
 		pc.AppendString(checkedfuncname(source.Function))
@@ -477,7 +508,7 @@ func (s *logfmtPainter) AppendColon(pc *PrintCtx) {
 }
 
 func (s *logfmtPainter) AppendComma(pc *PrintCtx) {
-	pc.AppendByte(',')
+	pc.AppendByte(' ')
 }
 
 func (s *logfmtPainter) AppendTime(pc *PrintCtx, z time.Time) {
@@ -514,7 +545,7 @@ func (s *logfmtPainter) AppendDuration(pc *PrintCtx, z time.Duration) {
 	// s.pcAppendByte('"')
 	// s.appendEscapedJSONString(z.String())
 	// s.pcAppendByte('"')
-	pc.AppendQuotedString(z.String())
+	pc.appendDurationString(z)
 }
 
 func (s *logfmtPainter) AppendDurationSlice(pc *PrintCtx, z []time.Duration) {
@@ -535,18 +566,18 @@ func (s *logfmtPainter) Append(pc *PrintCtx, data []byte) {
 }
 
 func (s *logfmtPainter) AppendStringKeyPrefixed(pc *PrintCtx, str, prefix string) {
-	_, _ = pc.WriteString(prefix)
+	_, _ = pc.WriteString(logfmtKey(prefix))
 	_ = pc.WriteByte('.')
-	_, _ = pc.WriteString(str)
+	_, _ = pc.WriteString(logfmtKey(str))
 }
 
 func (s *logfmtPainter) AppendStringKey(pc *PrintCtx, str string) {
-	_, _ = pc.WriteString(str)
+	_, _ = pc.WriteString(logfmtKey(str))
 }
 
 func (s *logfmtPainter) AppendKey(pc *PrintCtx, key string, clr, bg color.Color) {
 	if pc.IsColorfulStyle() {
-		ct.echoColorAndBg(pc, clrAttrKey, clrAttrKeyBg)
+		ct.echoColorAndBg(pc, pc.Theme().AttrKey.Fg, pc.Theme().AttrKey.Bg)
 		s.AppendStringKey(pc, key)
 		ct.echoColorAndBg(pc, clr, bg)
 	} else {
@@ -563,37 +594,29 @@ func (s *logfmtPainter) AppendErrorAfterPrinted(pc *PrintCtx, err error) {
 }
 
 func (s *logfmtPainter) AddTimestampField(pc *PrintCtx, tm time.Time) {
-	pc.AppendStringKey(timestampFieldName)
+	pc.AppendStringKey(pc.FieldNames().time())
 	pc.AddColon()
 	// pc.pcAppendByte('"')
 	pc.AppendTimestamp(pc.now)
-	pc.AddComma()
 }
 
 func (s *logfmtPainter) AddLoggerNameField(pc *PrintCtx, name string) {
-	pc.AddString("logger", name)
-	pc.AddComma()
+	pc.AddString(pc.FieldNames().logger(), name)
 }
 
 func (s *logfmtPainter) AddSeverity(pc *PrintCtx, lvl Level) {
-	pc.AppendString(ct.wrapRune(lvl.ShortTag(levelOutputWidth), '[', ']'))
-	pc.AppendByte(' ')
+	pc.AddString(pc.FieldNames().level(), lvl.String())
 }
 
+// AddPCField writes the caller info as a group, such as
+// caller.file="main.go" caller.line=12 caller.function="main.main".
 func (s *logfmtPainter) AddPCField(pc *PrintCtx, source *Source) {
+	caller := pc.FieldNames().caller()
+	pc.AddPrefixedString(caller, "file", source.File)
 	pc.AddComma()
-
-	pc.AppendStringKey(callerFieldName)
-	pc.AddColon()
-	pc.AppendByte('{')
-
-	pc.AddString("file", source.File)
-	pc.AddComma()
-	pc.AddInt("line", source.Line)
+	pc.AddPrefixedInt(caller, "line", source.Line)
 	pc.AddComma()
-	pc.AddString("function", source.Function)
-
-	pc.AppendByte('}')
+	pc.AddPrefixedString(caller, "function", source.Function)
 }
 
 func (s *logfmtPainter) AddPrefixedString(pc *PrintCtx, prefix, name string, value string) {
@@ -690,7 +713,7 @@ func (s *jsonPainter) AppendDuration(pc *PrintCtx, z time.Duration) {
 	// s.pcAppendByte('"')
 	// s.appendEscapedJSONString(z.String())
 	// s.pcAppendByte('"')
-	pc.AppendQuotedString(z.String())
+	pc.appendDurationString(z)
 }
 
 func (s *jsonPainter) AppendDurationSlice(pc *PrintCtx, z []time.Duration) {
@@ -768,36 +791,31 @@ func (s *jsonPainter) AppendErrorAfterPrinted(pc *PrintCtx, err error) {
 }
 
 func (s *jsonPainter) AddTimestampField(pc *PrintCtx, tm time.Time) {
-	pc.AppendStringKey(timestampFieldName)
+	pc.AppendStringKey(pc.FieldNames().time())
 	pc.AddColon()
 	// pc.pcAppendByte('"')
 	pc.AppendTimestamp(pc.now)
-	pc.AddComma()
 }
 
 func (s *jsonPainter) AddLoggerNameField(pc *PrintCtx, name string) {
-	pc.AppendStringKey("logger")
+	pc.AppendStringKey(pc.FieldNames().logger())
 	pc.AddColon()
 	pc.AppendByte('"')
 	pc.AppendStringValue(name)
 	pc.AppendByte('"')
-	pc.AddComma()
 }
 
 func (s *jsonPainter) AddSeverity(pc *PrintCtx, lvl Level) {
-	pc.AddString(levelFieldName, lvl.String())
+	pc.AddString(pc.FieldNames().level(), lvl.String())
 	// pc.pcAppendStringKey(levelFieldName)
 	// pc.pcAppendColon()
 	// pc.pcAppendByte('"')
 	// pc.pcAppendStringValue(pc.lvl.String())
 	// pc.pcAppendByte('"')
-	pc.AddComma()
 }
 
 func (s *jsonPainter) AddPCField(pc *PrintCtx, source *Source) {
-	pc.AddComma()
-
-	pc.AppendStringKey(callerFieldName)
+	pc.AppendStringKey(pc.FieldNames().caller())
 	pc.AddColon()
 	pc.AppendByte('{')
 
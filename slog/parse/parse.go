@@ -0,0 +1,529 @@
+// Package parse decodes the JSON and logfmt output of logg/slog
+// back into structured records, so the logging lines can be
+// verified by tests, or be processed by the log tools.
+//
+//	dec := parse.NewDecoder(os.Stdin)
+//	for {
+//		rec, err := dec.Decode()
+//		if err == io.EOF {
+//			break
+//		}
+//		if err != nil {
+//			log.Fatal(err)
+//		}
+//		fmt.Println(rec.Level, rec.Msg, rec.Attrs)
+//	}
+//
+// The built-in fields are recognized by their names (see
+// slog.FieldNames). The nested objects in JSON and the dotted keys
+// in logfmt, such as "group.key=value", are decoded as the groups.
+// Note that a key which has dots itself is decoded as groups too.
+//
+// The indented lines following a logging line, such as the rest
+// lines of a multi-line message printed in the plain mode, are
+// appended to the message of it.
+package parse
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hedzr/logg/slog"
+)
+
+// Record is a logging line decoded from the output of logg/slog.
+//
+// It embeds slog.Record, so a decoded line can be compared with the
+// records captured by logtest or a slog.Recorder. The Time is zero
+// if it is absent or unrecognized, the Level is slog.InfoLevel if
+// it is absent or unrecognized, and the groups in Attrs have
+// slog.Attrs values.
+//
+// A text line has no program counter nor *slog.Entry, so the caller
+// and the logger name are kept in Caller and Name instead of PC and
+// Logger. Use Source and LoggerName to read them from either type.
+type Record struct {
+	slog.Record
+	Name   string       // the logger name
+	Caller *slog.Source // the caller info, nil if it is absent
+}
+
+// Source returns the decoded caller info, nil if it is absent.
+func (r *Record) Source() *slog.Source { return r.Caller }
+
+// LoggerName returns the decoded logger name.
+func (r *Record) LoggerName() string { return r.Name }
+
+// Format is the format of the logging lines.
+type Format int
+
+const (
+	FormatAuto   Format = iota // detect by each line: JSON if it begins with '{', or logfmt
+	FormatJSON                 // JSON, an object may span multiple lines
+	FormatLogfmt               // logfmt
+)
+
+// ErrSyntax is wrapped by the errors of the malformed lines.
+var ErrSyntax = errors.New("parse: syntax error")
+
+// Opt is used by NewDecoder and Parse.
+type Opt func(s *Decoder)
+
+// WithFormat sets the format of the input, default is FormatAuto.
+func WithFormat(format Format) Opt {
+	return func(s *Decoder) {
+		s.format = format
+	}
+}
+
+// WithFieldNames sets the names of the built-in fields, which should
+// be the same as the logger's. Default is slog.FieldNamesDefault.
+func WithFieldNames(names slog.FieldNames) Opt {
+	return func(s *Decoder) {
+		s.names = names
+	}
+}
+
+// WithTimeLayout sets the layout of the timestamps, which is tried
+// before the layouts used by logg/slog.
+func WithTimeLayout(layout string) Opt {
+	return func(s *Decoder) {
+		s.layouts = append([]string{layout}, s.layouts...)
+	}
+}
+
+// Decoder reads the logging lines from an input.
+type Decoder struct {
+	r       *bufio.Reader
+	format  Format
+	names   slog.FieldNames
+	layouts []string
+
+	line    int    // the line number of the last read line
+	peek    string // the line read ahead
+	hasPeek bool
+}
+
+// NewDecoder returns a Decoder reading from r.
+func NewDecoder(r io.Reader, opts ...Opt) *Decoder {
+	s := &Decoder{
+		r:     bufio.NewReader(r),
+		names: slog.FieldNamesDefault,
+		layouts: []string{
+			time.RFC3339Nano, slog.RFC3339Nano, slog.DateTime,
+			slog.TimeNano, slog.TimeNoNano, time.DateTime, time.DateOnly,
+		},
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Parse decodes all logging lines from r.
+func Parse(r io.Reader, opts ...Opt) (records []*Record, err error) {
+	dec := NewDecoder(r, opts...)
+	for {
+		var rec *Record
+		if rec, err = dec.Decode(); err != nil {
+			if err == io.EOF {
+				err = nil
+			}
+			return
+		}
+		records = append(records, rec)
+	}
+}
+
+// Decode returns the next logging line, or io.EOF if there are no
+// more lines. The blank lines, and the indented lines before the
+// first logging line, are skipped.
+func (s *Decoder) Decode() (rec *Record, err error) {
+	var line string
+	for {
+		if line, err = s.readLine(); err != nil {
+			return
+		}
+		if strings.TrimSpace(line) != "" && !continuation(line) {
+			break
+		}
+	}
+
+	start := s.line
+	format := s.format
+	if format == FormatAuto {
+		format = FormatLogfmt
+		if line[0] == '{' {
+			format = FormatJSON
+		}
+	}
+
+	if format == FormatJSON {
+		for !jsonComplete(line) {
+			var more string
+			if more, err = s.readLine(); err != nil {
+				if err == io.EOF {
+					err = fmt.Errorf("%w: line %d: unexpected EOF", ErrSyntax, start)
+				}
+				return
+			}
+			line += "\n" + more
+		}
+		rec, err = s.parseJSON(line)
+	} else {
+		rec, err = s.parseLogfmt(line)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: line %d: %v", ErrSyntax, start, err)
+	}
+
+	for {
+		next, e := s.readLine()
+		if e != nil {
+			break
+		}
+		if !continuation(next) {
+			s.peek, s.hasPeek = next, true
+			s.line--
+			break
+		}
+		rec.Msg += "\n" + strings.TrimPrefix(next, "    ") // the indent of logg
+	}
+	return
+}
+
+// continuation reports whether line is indented.
+func continuation(line string) bool {
+	return line != "" && (line[0] == ' ' || line[0] == '\t')
+}
+
+func (s *Decoder) readLine() (line string, err error) {
+	s.line++
+	if s.hasPeek {
+		s.hasPeek = false
+		return s.peek, nil
+	}
+	line, err = s.r.ReadString('\n')
+	if err == io.EOF && line != "" {
+		err = nil
+	}
+	return strings.TrimRight(line, "\r\n"), err
+}
+
+// jsonComplete reports whether the braces of a JSON object are
+// balanced.
+func jsonComplete(data string) bool {
+	depth, inString := 0, false
+	for i := 0; i < len(data); i++ {
+		switch c := data[i]; {
+		case inString && c == '\\':
+			i++
+		case c == '"':
+			inString = !inString
+		case inString:
+		case c == '{' || c == '[':
+			depth++
+		case c == '}' || c == ']':
+			depth--
+		}
+	}
+	return depth <= 0 && !inString
+}
+
+//
+//
+//
+
+func (s *Decoder) parseJSON(line string) (rec *Record, err error) {
+	dec := json.NewDecoder(strings.NewReader(line))
+	dec.UseNumber()
+	var v any
+	if v, err = jsonValue(dec); err != nil {
+		return
+	}
+	attrs, ok := v.(slog.Attrs)
+	if !ok {
+		return nil, errors.New("not a JSON object")
+	}
+	if dec.More() {
+		return nil, errors.New("unexpected data after the JSON object")
+	}
+
+	rec = &Record{Record: slog.Record{Level: slog.InfoLevel}}
+	for _, a := range attrs {
+		if !s.builtin(rec, a.Key(), a.Value()) {
+			rec.Attrs = append(rec.Attrs, a)
+		}
+	}
+	return
+}
+
+// jsonValue decodes a JSON value, the objects are decoded as
+// slog.Attrs in order.
+func jsonValue(dec *json.Decoder) (v any, err error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return
+	}
+	switch t := tok.(type) {
+	case json.Delim:
+		switch t {
+		case '{':
+			attrs := slog.Attrs{}
+			for dec.More() {
+				var key any
+				if key, err = dec.Token(); err != nil {
+					return
+				}
+				var val any
+				if val, err = jsonValue(dec); err != nil {
+					return
+				}
+				attrs = append(attrs, attr(key.(string), val))
+			}
+			_, err = dec.Token() // '}'
+			return attrs, err
+		case '[':
+			list := []any{}
+			for dec.More() {
+				var val any
+				if val, err = jsonValue(dec); err != nil {
+					return
+				}
+				list = append(list, val)
+			}
+			_, err = dec.Token() // ']'
+			return list, err
+		}
+		return nil, fmt.Errorf("unexpected %v", t)
+	case json.Number:
+		return number(string(t)), nil
+	}
+	return tok, nil // string, bool or nil
+}
+
+func attr(key string, val any) slog.Attr {
+	if g, ok := val.(slog.Attrs); ok {
+		return slog.NewGroupedAttr(key, g...)
+	}
+	return slog.NewAttr(key, val)
+}
+
+// number decodes a number as int64, uint64 or float64.
+func number(str string) any {
+	if i, err := strconv.ParseInt(str, 10, 64); err == nil {
+		return i
+	}
+	if u, err := strconv.ParseUint(str, 10, 64); err == nil {
+		return u
+	}
+	if f, err := strconv.ParseFloat(str, 64); err == nil {
+		return f
+	}
+	return str
+}
+
+//
+//
+//
+
+func (s *Decoder) parseLogfmt(line string) (rec *Record, err error) {
+	rec = &Record{Record: slog.Record{Level: slog.InfoLevel}}
+	var src slog.Source
+	var hasSource bool
+	callerPrefix := orDefault(s.names.Caller, slog.FieldNamesDefault.Caller) + "."
+
+	for i := 0; i < len(line); {
+		if line[i] == ' ' {
+			i++
+			continue
+		}
+
+		start := i
+		for i < len(line) && line[i] > ' ' && line[i] != '=' && line[i] != '"' {
+			i++
+		}
+		key := line[start:i]
+		if key == "" {
+			return nil, fmt.Errorf("bad key at column %d", start+1)
+		}
+
+		var val any = true // a key without value is a flag
+		if i < len(line) && line[i] == '=' {
+			i++
+			if i < len(line) && line[i] == '"' {
+				var str string
+				if str, i, err = unquote(line, i); err != nil {
+					return
+				}
+				val = str
+			} else {
+				start = i
+				for i < len(line) && line[i] != ' ' {
+					if line[i] == '"' || line[i] == '=' {
+						return nil, fmt.Errorf("unexpected %q at column %d", line[i], i+1)
+					}
+					i++
+				}
+				val = bare(line[start:i])
+			}
+		}
+
+		if field, ok := strings.CutPrefix(key, callerPrefix); ok && setSource(&src, field, val) {
+			hasSource = true
+			continue
+		}
+		if s.builtin(rec, key, val) {
+			continue
+		}
+		rec.Attrs = insert(rec.Attrs, strings.Split(key, "."), val)
+	}
+	if hasSource {
+		rec.Caller = &src
+	}
+	return
+}
+
+// unquote decodes the quoted string at line[i:], and returns the
+// position after it.
+func unquote(line string, i int) (str string, end int, err error) {
+	for end = i + 1; end < len(line); end++ {
+		switch line[end] {
+		case '\\':
+			end++
+		case '"':
+			end++
+			err = json.Unmarshal([]byte(line[i:end]), &str)
+			return
+		}
+	}
+	return "", end, fmt.Errorf("unterminated string at column %d", i+1)
+}
+
+// bare decodes a bare value. logg/slog quotes all strings, so a bare
+// value is usually a number, a bool, or a slice.
+func bare(str string) any {
+	switch str {
+	case "true":
+		return true
+	case "false":
+		return false
+	case "":
+		return ""
+	}
+	if c := str[0]; c == '-' || c >= '0' && c <= '9' {
+		if v := number(str); v != str {
+			return v
+		}
+	}
+	if str[0] == '[' || str[0] == '{' {
+		dec := json.NewDecoder(strings.NewReader(str))
+		dec.UseNumber()
+		if v, err := jsonValue(dec); err == nil && !dec.More() {
+			return v
+		}
+	}
+	return str
+}
+
+// insert adds a value by the path of a dotted key, the groups are
+// made if necessary.
+func insert(attrs slog.Attrs, path []string, val any) slog.Attrs {
+	if len(path) == 1 {
+		return append(attrs, slog.NewAttr(path[0], val))
+	}
+	for _, a := range attrs {
+		if g, ok := a.Value().(slog.Attrs); ok && a.Key() == path[0] {
+			a.SetValue(insert(g, path[1:], val))
+			return attrs
+		}
+	}
+	return append(attrs, slog.NewGroupedAttr(path[0], insert(nil, path[1:], val)...))
+}
+
+//
+//
+//
+
+// builtin sets a built-in field of rec, and reports whether key is
+// the name of it.
+func (s *Decoder) builtin(rec *Record, key string, val any) bool {
+	names := &s.names
+	str, isString := val.(string)
+	switch key {
+	case orDefault(names.Time, slog.FieldNamesDefault.Time):
+		if isString {
+			for _, layout := range s.layouts {
+				if tm, err := time.Parse(layout, str); err == nil {
+					rec.Time = tm
+					return true
+				}
+			}
+		}
+	case orDefault(names.Level, slog.FieldNamesDefault.Level):
+		if isString {
+			for _, lvl := range slog.AllLevels() {
+				if strings.EqualFold(lvl.String(), str) {
+					rec.Level = lvl
+					return true
+				}
+			}
+		}
+	case orDefault(names.Logger, slog.FieldNamesDefault.Logger):
+		if isString {
+			rec.Name = str
+			return true
+		}
+	case orDefault(names.Message, slog.FieldNamesDefault.Message):
+		if isString {
+			rec.Msg = str
+			return true
+		}
+	case orDefault(names.Caller, slog.FieldNamesDefault.Caller):
+		if g, ok := val.(slog.Attrs); ok {
+			var src slog.Source
+			for _, a := range g {
+				if !setSource(&src, a.Key(), a.Value()) {
+					return false
+				}
+			}
+			rec.Caller = &src
+			return true
+		}
+	}
+	return false
+}
+
+// setSource sets a field of the caller info.
+func setSource(src *slog.Source, field string, val any) bool {
+	switch v := val.(type) {
+	case string:
+		switch field {
+		case "file":
+			src.File = v
+			return true
+		case "function":
+			src.Function = v
+			return true
+		}
+	case int64:
+		if field == "line" {
+			src.Line = int(v)
+			return true
+		}
+	}
+	return false
+}
+
+func orDefault(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
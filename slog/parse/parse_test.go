@@ -0,0 +1,157 @@
+package parse
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/hedzr/logg/slog"
+)
+
+// dump writes attrs in the order, the groups in braces.
+func dump(attrs slog.Attrs) string {
+	var sb strings.Builder
+	for i, a := range attrs {
+		if i > 0 {
+			sb.WriteByte(' ')
+		}
+		if g, ok := a.Value().(slog.Attrs); ok {
+			fmt.Fprintf(&sb, "%s{%s}", a.Key(), dump(g))
+		} else {
+			v := a.Value()
+			if list, ok := v.([]any); ok {
+				for j, e := range list {
+					if g, ok := e.(slog.Attrs); ok {
+						list[j] = "{" + dump(g) + "}"
+					}
+				}
+			}
+			fmt.Fprintf(&sb, "%s=%v(%T)", a.Key(), v, v)
+		}
+	}
+	return sb.String()
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, c := range []struct {
+		mode  slog.Mode
+		attrs string
+	}{
+		{slog.ModeJSON, `a=1(int64) b=true(bool) g{x=y(string) h{z=2(int64)}} ints=[1 2]([]interface {}) s=x y(string)`},
+		{slog.ModeLogFmt, `a=1(int64) b=true(bool) g{x=y(string) h{z=2(int64)}} ints=[1 2]([]interface {}) s=x y(string)`},
+	} {
+		t.Run(c.mode.String(), func(t *testing.T) {
+			var buf bytes.Buffer
+			var captured []*slog.Record
+			l := slog.New("rt", slog.WithWriter(&buf), slog.WithErrorWriter(&buf), slog.WithLevel(slog.InfoLevel), slog.WithMode(c.mode),
+				slog.WithRecorder(func(_ context.Context, rec *slog.Record) { captured = append(captured, rec.Clone()) }))
+			l.Info("hello \"w\"\nsecond line", "a", 1, "b", true,
+				slog.Group("g", "x", "y", slog.Group("h", "z", 2)), "ints", []int{1, 2}, "s", "x y")
+			l.Warn("bye")
+			t.Log(buf.String())
+
+			records, err := Parse(&buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(records) != 2 {
+				t.Fatalf("expect 2 records, but got %d", len(records))
+			}
+
+			for i, rec := range records {
+				want := captured[i]
+				if rec.Level != want.Level || rec.Msg != want.Msg || rec.LoggerName() != want.LoggerName() ||
+					rec.Source().Line != want.Source().Line || len(rec.Attrs) != len(want.Attrs) {
+					t.Fatalf("record %d: expect %+v, but got %+v", i, want, rec)
+				}
+			}
+
+			rec := records[0]
+			if rec.LoggerName() != "rt" || rec.Level != slog.InfoLevel || rec.Msg != "hello \"w\"\nsecond line" {
+				t.Fatalf("unexpected record: %+v", rec)
+			}
+			if rec.Time.IsZero() {
+				t.Fatal("expect the time is decoded")
+			}
+			if rec.Caller == nil || !strings.HasSuffix(rec.Caller.File, "parse_test.go") || rec.Caller.Line == 0 || !strings.HasSuffix(rec.Caller.Function, "TestRoundTrip.func1") {
+				t.Fatalf("unexpected caller: %+v", rec.Caller)
+			}
+			if got := dump(rec.Attrs); got != c.attrs {
+				t.Fatalf("expect attrs\n%s\nbut got\n%s", c.attrs, got)
+			}
+
+			if rec = records[1]; rec.Level != slog.WarnLevel || rec.Msg != "bye" || len(rec.Attrs) != 0 {
+				t.Fatalf("unexpected record: %+v", rec)
+			}
+		})
+	}
+}
+
+func TestDecoder(t *testing.T) {
+	const input = `
+time="2024-05-06T07:08:09.5Z" level="error" msg="first" k=v n=-3 f=0.25 flag
+    continued
+	and tabbed
+{"time": "2024-05-06 07:08:09", "lvl": "debug",
+ "msg": "multi-line {json}", "caller": {"file": "a.go", "line": 3}, "arr": [{"x": null}]}
+name="custom" severity=notice at.x=1 at.y="2" at.x2.z=[1,2]
+`
+	dec := NewDecoder(strings.NewReader(input), WithFieldNames(slog.FieldNames{Level: "lvl"}))
+	var got []string
+	for {
+		rec, err := dec.Decode()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		caller := "<nil>"
+		if rec.Caller != nil {
+			caller = fmt.Sprintf("%s:%d:%s", rec.Caller.File, rec.Caller.Line, rec.Caller.Function)
+		}
+		got = append(got, fmt.Sprintf("%s|%v|%q|%s|%s", rec.Time.Format("2006-01-02T15:04:05.999"), rec.Level, rec.Msg, caller, dump(rec.Attrs)))
+	}
+
+	want := []string{
+		`2024-05-06T07:08:09.5|info|"first\ncontinued\n\tand tabbed"|<nil>|level=error(string) k=v(string) n=-3(int64) f=0.25(float64) flag=true(bool)`,
+		`2024-05-06T07:08:09|debug|"multi-line {json}"|a.go:3:|arr=[{x=<nil>(<nil>)}]([]interface {})`,
+		`0001-01-01T00:00:00|info|""|<nil>|name=custom(string) severity=notice(string) at{x=1(int64) y=2(string) x2{z=[1 2]([]interface {})}}`,
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expect %d records, but got %d:\n%s", len(want), len(got), strings.Join(got, "\n"))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("record %d:\nexpect %s\nbut got %s", i, want[i], got[i])
+		}
+	}
+}
+
+func TestSyntaxError(t *testing.T) {
+	for _, c := range []struct {
+		input  string
+		format Format
+	}{
+		{"msg=\"unterminated", FormatAuto},
+		{"msg=\"ok\"\n=1", FormatAuto},
+		{"a=b=c", FormatLogfmt},
+		{"{\"msg\": 1,", FormatAuto},
+		{"{\"msg\": 1} 2", FormatAuto},
+		{"[1]", FormatJSON},
+	} {
+		_, err := Parse(strings.NewReader(c.input), WithFormat(c.format))
+		if !errors.Is(err, ErrSyntax) {
+			t.Fatalf("%q: expect ErrSyntax, but got %v", c.input, err)
+		}
+	}
+
+	_, err := Parse(strings.NewReader("msg=\"ok\"\n\nmsg=\"bad"))
+	if err == nil || !strings.Contains(err.Error(), "line 3:") {
+		t.Fatalf("expect an error at line 3, but got %v", err)
+	}
+}
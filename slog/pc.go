@@ -2,9 +2,11 @@ package slog
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"io"
+	"net/url"
 	"os"
 	"strconv"
 	"sync"
@@ -14,6 +16,8 @@ import (
 	"github.com/hedzr/is"
 	"github.com/hedzr/is/term/color"
 	errorsv3 "gopkg.in/hedzr/errors.v3"
+
+	"github.com/hedzr/logg/slog/internal/times"
 )
 
 var poolPrintCtx = sync.Pool{New: func() any {
@@ -31,26 +35,24 @@ var poolPrintCtx = sync.Pool{New: func() any {
 
 func newPrintCtx() *PrintCtx {
 	return &PrintCtx{
-		buf:         make([]byte, 0, 1024),
-		noQuoted:    true,
-		dedupeAttrs: true,
-		clr:         clrBasic,
-		bg:          clrNone,
-		mode1:       ModeColorful,
-		ip:          &colorfulPainter{},
+		buf:      make([]byte, 0, 1024),
+		noQuoted: true,
+		clr:      clrBasic,
+		bg:       clrNone,
+		mode1:    ModeColorful,
+		ip:       &colorfulPainter{},
 		// colorful:     is.NoColorMode(),
 	}
 }
 
 func NewPrintCtx(opts ...PCOpt) *PrintCtx {
 	s := &PrintCtx{
-		buf:         make([]byte, 0, 1024),
-		noQuoted:    true,
-		dedupeAttrs: true,
-		clr:         clrBasic,
-		bg:          clrNone,
-		mode1:       ModeColorful,
-		ip:          &colorfulPainter{},
+		buf:      make([]byte, 0, 1024),
+		noQuoted: true,
+		clr:      clrBasic,
+		bg:       clrNone,
+		mode1:    ModeColorful,
+		ip:       &colorfulPainter{},
 	}
 	for _, opt := range opts {
 		opt(s)
@@ -64,6 +66,13 @@ func WithPCMode(mode Mode) PCOpt {
 	}
 }
 
+// WithPCLimits sets the size limits of a standalone PrintCtx.
+func WithPCLimits(limits Limits) PCOpt {
+	return func(pc *PrintCtx) {
+		pc.limits = limits
+	}
+}
+
 type PCOpt func(*PrintCtx)
 
 // NewPrintCtxBytes creates and initializes a new Buffer using buf as its
@@ -97,12 +106,14 @@ type PrintCtx struct {
 	// jsonMode    bool   // should print out the logging with JSON format? default is NO.
 	// noColor     bool   // use ansi escape sequences in console/terminal? default is ON.
 
-	mode1       Mode
-	noQuoted    bool   // should quote the string values? default is YES
-	layout      string // time layout for formatting
-	utcTime     int    // non-set(0), local(1) or utc(2) time? default is local time mode.
-	dedupeAttrs bool   // remove dup attrs when printing
+	mode1    Mode
+	noQuoted bool       // should quote the string values? default is YES
+	layout   string     // time layout for formatting
+	utcTime  int        // non-set(0), local(1) or utc(2) time? default is local time mode.
+	dedupe   DedupeMode // the strategy of the duplicated attrs
 
+	ctx        context.Context // the context of the logging call, nil if not rendering
+	name       string          // the logger name
 	lvl        Level
 	now        time.Time
 	stackFrame uintptr // the caller's stack frames
@@ -121,8 +132,22 @@ type PrintCtx struct {
 	// curdir string
 
 	valueStringer ValueStringer
+	fieldNames    *FieldNames
+	theme         *Theme
+	valueStyled   bool // the colour of the attribute value has been emitted
 
 	ip Painter
+
+	bin       binaryEncoder // the painter if it is of a binary format
+	binFrames []binFrame    // the open maps and arrays of bin
+
+	reflectRefs []reflectRef // the composite values being encoded by reflection
+
+	limits    Limits   // the size limits of the logger
+	encoding  Encoding // the value encodings of the logger
+	attrDepth int      // the nesting depth of serializeAttrs
+	lineFull  bool     // MaxLineBytes is reached, the rest attributes are skipped
+	event     *Event   // the Event whose fields are written after the attributes
 }
 
 func (s *PrintCtx) set(e *Entry, lvl Level, timestamp time.Time, stackFrame uintptr, msg string, kvps Attrs) {
@@ -132,15 +157,24 @@ func (s *PrintCtx) set(e *Entry, lvl Level, timestamp time.Time, stackFrame uint
 	s.now = timestamp
 	s.stackFrame = stackFrame
 	s.msg = msg
+	if limit := s.stringLimit(); limit >= 0 {
+		s.msg = truncateText(msg, limit)
+	}
 	s.kvps = kvps
 }
 
 func (s *PrintCtx) internalsetentry(e *Entry) {
 	s.buf = s.buf[:0]
+	s.reflectRefs = s.reflectRefs[:0]
+	s.limits = e.Limits()
+	s.encoding = e.Encoding()
+	s.dedupe = e.Dedupe()
+	s.attrDepth, s.lineFull = 0, false
 
 	// s.colorful = !is.NoColorMode()
 
-	s.SetMode(e.mode)
+	s.theme = e.Theme()
+	s.SetMode(smartMode(e.mode))
 
 	// if s.mode == ModePlain {
 	// 	s.colorful = false
@@ -152,6 +186,7 @@ func (s *PrintCtx) internalsetentry(e *Entry) {
 	// }
 	// s.noColor = !useColor // || !s.colorful
 
+	s.name = e.name
 	s.layout = e.timeLayout
 	s.utcTime = e.modeUTC
 	s.valueStringer = e.valueStringer
@@ -162,10 +197,64 @@ func (s *PrintCtx) internalsetentry(e *Entry) {
 	if e.painter != nil {
 		s.ip = e.painter
 	}
+
+	s.bin, _ = s.ip.(binaryEncoder)
+	s.binFrames = s.binFrames[:0]
+
+	s.fieldNames = e.FieldNames()
+	if fna, ok := s.ip.(FieldNamesAware); ok {
+		if names := fna.FieldNames(); names != nil {
+			s.fieldNames = names
+		}
+	}
+}
+
+// FieldNames returns the names of the built-in fields for this session.
+func (s *PrintCtx) FieldNames() *FieldNames {
+	if s.fieldNames == nil {
+		return &FieldNamesDefault
+	}
+	return s.fieldNames
+}
+
+// Context returns the context of the logging call. It is never nil
+// while a painter is working.
+func (s *PrintCtx) Context() context.Context {
+	if s.ctx == nil {
+		return context.Background()
+	}
+	return s.ctx
+}
+
+// LoggerName returns the name of the logger for this session.
+func (s *PrintCtx) LoggerName() string { return s.name }
+
+// Level returns the level of the logging line.
+func (s *PrintCtx) Level() Level { return s.lvl }
+
+// Time returns the timestamp of the logging line.
+func (s *PrintCtx) Time() time.Time { return s.now }
+
+// Msg returns the message of the logging line.
+func (s *PrintCtx) Msg() string { return s.msg }
+
+// Attrs returns the attributes of the logging line.
+func (s *PrintCtx) Attrs() Attrs { return s.kvps }
+
+// Source returns the caller info of the logging line, or nil if
+// it was not captured.
+func (s *PrintCtx) Source() *Source {
+	if s.stackFrame == 0 {
+		return nil
+	}
+	return s.source()
 }
 
 func (s *PrintCtx) putBack() {
-	s.ip = &colorfulPainter{}
+	s.ctx = nil
+	s.event = nil
+	s.ip = thePlainPainter
+	s.bin = nil
 	poolPrintCtx.Put(s)
 }
 
@@ -184,25 +273,53 @@ func (s *PrintCtx) IsColorfulStyle() bool { return s.ip.Colorful() && s.IsColorS
 func (s *PrintCtx) IsJSONStyle() bool     { return s.mode1 == ModeJSON || s.mode1 == ModeLogFmt }
 func (s *PrintCtx) Colorful() bool        { return s.ip.Colorful() }
 
+// the built-in painters are stateless, so they are shared.
+var (
+	thePlainPainter    = &colorfulPainter{false}
+	theColorfulPainter = &colorfulPainter{true}
+	theLogfmtPainter   = &logfmtPainter{}
+	theJSONPainter     = &jsonPainter{}
+)
+
 func (s *PrintCtx) SetMode(mode Mode) {
 	s.mode1 = mode
 	switch mode {
 	case ModeColorful:
-		s.ip = &colorfulPainter{!is.NoColorMode()}
+		if is.NoColorMode() || s.Theme().Plain {
+			s.ip = thePlainPainter
+		} else {
+			s.ip = theColorfulPainter
+		}
 	case ModePlain:
-		s.ip = &colorfulPainter{false}
+		s.ip = thePlainPainter
 	case ModeLogFmt:
-		s.ip = &logfmtPainter{}
+		s.ip = theLogfmtPainter
 	case ModeJSON:
-		s.ip = &jsonPainter{}
+		s.ip = theJSONPainter
+	default:
+		if m := lookupMode(mode); m != nil {
+			s.ip = m.getPainter()
+		}
 	}
+	s.bin, _ = s.ip.(binaryEncoder)
 }
 
 func (s *PrintCtx) GetColorTableByLevel() (colors []color.Color) {
+	if st, ok := s.Theme().levelStyle(s.lvl); ok {
+		return []color.Color{st.Fg, st.Bg}
+	}
 	colors = GetColorTableByLevel(s.lvl)
 	return
 }
 
+// Theme returns the [Theme] of the logger.
+func (s *PrintCtx) Theme() *Theme {
+	if s.theme == nil {
+		return defaultTheme()
+	}
+	return s.theme
+}
+
 func (s *PrintCtx) SetupColors() {
 	if s.Colorful() {
 		if colors := s.GetColorTableByLevel(); len(colors) > 0 {
@@ -897,12 +1014,20 @@ func (s *PrintCtx) AddString(name string, value string) {
 	s.pcAppendColon()
 	// s.pcAppendStringValue(intToString(value))
 	// if s.noColor {
-	s.AppendQuotedStringValue(value)
+	s.AppendQuotedStringValue(s.limitString(value))
 	// } else {
 	// 	s.pcAppendString(value)
 	// }
 }
 
+// AddAny adds a field of any value, it is encoded like an attribute
+// value.
+func (s *PrintCtx) AddAny(name string, value any) {
+	s.AppendStringKey(name)
+	s.pcAppendColon()
+	s.appendValue(value)
+}
+
 func (s *PrintCtx) AddPrefixedString(prefix, name string, value string) {
 	s.ip.AddPrefixedString(s, prefix, name, value)
 }
@@ -955,6 +1080,10 @@ func (s *PrintCtx) AppendRuneTimes(r rune, times int) {
 
 // pcAppendStringValue append string without quotes, the string represents a value
 func (s *PrintCtx) AppendStringValue(str string) {
+	if s.bin != nil {
+		s.bin.appendString(s, str)
+		return
+	}
 	s.preCheck()
 	_, _ = s.WriteString(str)
 }
@@ -1017,7 +1146,7 @@ func (s *PrintCtx) pcQuoteValue(val string) {
 	// s.pcAppendByte('"')
 	// s.appendEscapedJSONString(val)
 	// s.pcAppendByte('"')
-	s.AppendQuotedString(val)
+	s.AppendQuotedString(s.limitString(val))
 }
 
 func (s *PrintCtx) pcAppendColon() {
@@ -1030,6 +1159,12 @@ func (s *PrintCtx) pcAppendComma() {
 	s.ip.AppendComma(s)
 }
 
+// atObjectBegin reports whether a JSON object was just began, so
+// no comma is needed for the first field.
+func (s *PrintCtx) atObjectBegin() bool {
+	return len(s.buf) > s.off && s.buf[len(s.buf)-1] == '{'
+}
+
 // AddComma shall be inserted at middle of two fields.
 //
 // See Begin and End, BeginArray and EndArray.
@@ -1111,7 +1246,17 @@ func (s *PrintCtx) AppendKey(key string) {
 }
 
 func (s *PrintCtx) AppendQuotedString(str string) {
+	if s.bin != nil {
+		s.bin.appendString(s, str)
+		return
+	}
 	s.PreAlloc(len(str)*2 + 2)
+	if s.IsJSONStyle() { // the strict escapes of JSON, which are used by logfmt too
+		s.buf = append(s.buf, '"')
+		s.appendEscapedJSONString(str)
+		s.buf = append(s.buf, '"')
+		return
+	}
 	s.buf = appendQuotedWith(s.buf, str, '"', false, false)
 }
 
@@ -1591,9 +1736,22 @@ func (s *PrintCtx) stringerSer(val Stringer) {
 	s.pcQuoteValue(val.String())
 }
 
+// AppendValue appends a value, it is encoded like an attribute value.
+func (s *PrintCtx) AppendValue(val any) {
+	s.appendValue(val)
+}
+
 func (s *PrintCtx) appendValue(val any) {
 	switch z := val.(type) {
 	case nil:
+		if s.bin != nil {
+			s.bin.appendNil(s)
+			break
+		}
+		if s.mode1 == ModeJSON {
+			s.AppendStringValue("null")
+			break
+		}
 		s.AppendStringValue("<nil>")
 
 	case ObjectSerializer:
@@ -1615,6 +1773,9 @@ func (s *PrintCtx) appendValue(val any) {
 			break
 		}
 
+	case Value:
+		s.appendTypedValue(z)
+
 	case time.Duration:
 		s.AppendDuration(z)
 	case time.Time:
@@ -1633,17 +1794,28 @@ func (s *PrintCtx) appendValue(val any) {
 	case ToString:
 		s.pcQuoteValue(z.ToString())
 
+	case *url.URL:
+		if !s.appendURLValue(z) {
+			s.stringerSer(z)
+		}
+	case url.URL:
+		if !s.appendURLValue(&z) {
+			s.stringerSer(&z)
+		}
+
 	case Stringer:
 		s.stringerSer(z) // pcQuoteValue(z.String())
 
 	case string:
 		s.pcQuoteValue(z)
+	case limitMarker:
+		s.AppendQuotedString(string(z))
 
 	case bool:
 		btoaS(s, z)
 
 	case []byte:
-		s.AppendBytes(z)
+		s.appendBytesValue(z)
 
 	case []string:
 		s.AppendStringSlice(z)
@@ -1718,6 +1890,9 @@ func (s *PrintCtx) appendValue(val any) {
 		if handled, err := s.ip.MarshalValue(s, val); handled || err != nil {
 			return
 		}
+		if s.appendReflect(val) {
+			return
+		}
 
 		// switch s.mode {
 		// case ModeJSON:
@@ -1779,7 +1954,7 @@ func (s *PrintCtx) appendValue(val any) {
 		// 	break
 		// }
 
-		// TODO remove usage to fmt.Sprintf
+		// the channels and the functions
 		s.pcTryQuoteValue(fmt.Sprintf("{{%+v}}", z))
 	}
 }
@@ -2051,22 +2226,35 @@ var safeSet = [utf8.RuneSelf]bool{
 }
 
 func (s *PrintCtx) AppendStringSlice(val []string) {
+	if s.bin != nil {
+		binSliceTo(s, val, func(v string) { s.AppendQuotedString(s.limitString(v)) })
+		return
+	}
+	keep, more := s.SliceLimit(len(val))
+	val = val[:keep]
 	s.buf = append(s.buf, '[')
 	if l := len(val); l > 0 {
-		s.AppendQuotedString(val[0])
+		s.AppendQuotedString(s.limitString(val[0]))
 		for i := 1; i < len(val); i++ {
 			s.buf = append(s.buf, ',')
 			// s.buf = strconv.AppendQuote(s.buf, val[i])
-			s.AppendQuotedString(val[i])
+			s.AppendQuotedString(s.limitString(val[i]))
 			// s.buf = append(s.buf, '"')
 			// s.appendEscapedJSONString(val[i])
 			// s.buf = append(s.buf, '"')
 		}
 	}
+	s.appendMoreItems(keep > 0, more)
 	s.buf = append(s.buf, ']')
 }
 
 func (s *PrintCtx) AppendBoolSlice(val []bool) {
+	if s.bin != nil {
+		binSliceTo(s, val, func(v bool) { btoaS(s, v) })
+		return
+	}
+	keep, more := s.SliceLimit(len(val))
+	val = val[:keep]
 	s.buf = append(s.buf, '[')
 	if l := len(val); l > 0 {
 		s.buf = strconv.AppendBool(s.buf, val[0])
@@ -2075,10 +2263,17 @@ func (s *PrintCtx) AppendBoolSlice(val []bool) {
 			s.buf = strconv.AppendBool(s.buf, val[i])
 		}
 	}
+	s.appendMoreItems(keep > 0, more)
 	s.buf = append(s.buf, ']')
 }
 
 func intSliceTo[T Integers](s *PrintCtx, val IntSlice[T]) {
+	if s.bin != nil {
+		binSliceTo(s, val, func(v T) { itoaS(s, v) })
+		return
+	}
+	keep, more := s.SliceLimit(len(val))
+	val = val[:keep]
 	s.buf = append(s.buf, '[')
 	if l := len(val); l > 0 {
 		s.buf = strconv.AppendInt(s.buf, int64(val[0]), 10)
@@ -2087,10 +2282,17 @@ func intSliceTo[T Integers](s *PrintCtx, val IntSlice[T]) {
 			s.buf = strconv.AppendInt(s.buf, int64(val[i]), 10)
 		}
 	}
+	s.appendMoreItems(keep > 0, more)
 	s.buf = append(s.buf, ']')
 }
 
 func uintSliceTo[T Uintegers](s *PrintCtx, val UintSlice[T]) {
+	if s.bin != nil {
+		binSliceTo(s, val, func(v T) { utoaS(s, v) })
+		return
+	}
+	keep, more := s.SliceLimit(len(val))
+	val = val[:keep]
 	s.buf = append(s.buf, '[')
 	if l := len(val); l > 0 {
 		s.buf = strconv.AppendUint(s.buf, uint64(val[0]), 10)
@@ -2099,10 +2301,17 @@ func uintSliceTo[T Uintegers](s *PrintCtx, val UintSlice[T]) {
 			s.buf = strconv.AppendUint(s.buf, uint64(val[i]), 10)
 		}
 	}
+	s.appendMoreItems(keep > 0, more)
 	s.buf = append(s.buf, ']')
 }
 
 func floatSliceTo[T Floats](s *PrintCtx, val FloatSlice[T]) {
+	if s.bin != nil {
+		binSliceTo(s, val, func(v T) { ftoaS(s, v) })
+		return
+	}
+	keep, more := s.SliceLimit(len(val))
+	val = val[:keep]
 	s.buf = append(s.buf, '[')
 	if l := len(val); l > 0 {
 		ftoaS(s, val[0])
@@ -2111,10 +2320,17 @@ func floatSliceTo[T Floats](s *PrintCtx, val FloatSlice[T]) {
 			ftoaS(s, val[i])
 		}
 	}
+	s.appendMoreItems(keep > 0, more)
 	s.buf = append(s.buf, ']')
 }
 
 func complexSliceTo[T Complexes](s *PrintCtx, val ComplexSlice[T]) {
+	if s.bin != nil {
+		binSliceTo(s, val, func(v T) { ctoaS(s, v) })
+		return
+	}
+	keep, more := s.SliceLimit(len(val))
+	val = val[:keep]
 	s.buf = append(s.buf, '[')
 	if l := len(val); l > 0 {
 		ctoaS(s, val[0])
@@ -2123,9 +2339,23 @@ func complexSliceTo[T Complexes](s *PrintCtx, val ComplexSlice[T]) {
 			ctoaS(s, val[i])
 		}
 	}
+	s.appendMoreItems(keep > 0, more)
 	s.buf = append(s.buf, ']')
 }
 
+// binSliceTo writes a slice as an array of a binary format.
+func binSliceTo[S ~[]E, E any](s *PrintCtx, val S, appendValue func(v E)) {
+	keep, more := s.SliceLimit(len(val))
+	s.BeginArray()
+	for _, v := range val[:keep] {
+		appendValue(v)
+	}
+	if more > 0 {
+		s.AppendQuotedString(moreMarker(more, "items"))
+	}
+	s.EndArray(false)
+}
+
 func (s *PrintCtx) Buf(cb func(buf []byte) []byte) {
 	s.buf = cb(s.buf)
 }
@@ -2134,32 +2364,66 @@ func (s *PrintCtx) AppendDuration(z time.Duration) {
 	// s.pcAppendByte('"')
 	// s.appendEscapedJSONString(z.String())
 	// s.pcAppendByte('"')
-	s.ip.AppendDuration(s, z)
+	if !s.appendDurationValue(z) {
+		s.ip.AppendDuration(s, z)
+	}
+}
+
+// appendDurationString writes the quoted text of time.Duration.String
+// in the text formats, without allocation. The text needs no escape.
+func (s *PrintCtx) appendDurationString(z time.Duration) {
+	if s.bin != nil {
+		s.bin.appendString(s, z.String())
+		return
+	}
+	s.buf = append(s.buf, '"')
+	s.buf = times.AppendDuration(s.buf, z)
+	s.buf = append(s.buf, '"')
 }
 
 func (s *PrintCtx) AppendDurationSlice(z []time.Duration) {
-	s.ip.AppendDurationSlice(s, z)
+	keep, more := s.SliceLimit(len(z))
+	if (more > 0 && s.bin != nil) || s.encoding.Duration != DurationString {
+		sliceTo(s, z, s.AppendDuration)
+		return
+	}
+	s.ip.AppendDurationSlice(s, z[:keep])
+	s.closeMoreItems(keep > 0, more)
 }
 
 func (s *PrintCtx) AppendTime(z time.Time) {
-	s.ip.AppendTime(s, z)
+	if !s.appendTimeValue(z) {
+		s.ip.AppendTime(s, z)
+	}
 }
 
 func (s *PrintCtx) AppendTimeSlice(z []time.Time) {
-	s.ip.AppendTimeSlice(s, z)
+	keep, more := s.SliceLimit(len(z))
+	if (more > 0 && s.bin != nil) || s.encoding.Time != TimeDefault {
+		sliceTo(s, z, s.AppendTime)
+		return
+	}
+	s.ip.AppendTimeSlice(s, z[:keep])
+	s.closeMoreItems(keep > 0, more)
 }
 
 // appendTimestamp is specially for printing logging timestamp
 func (s *PrintCtx) AppendTimestamp(z time.Time) {
-	var tm time.Time
+	tm, layout := s.timestamp(z)
+
+	// return tm.Format(layout)
+	s.ip.AppendTimestamp(s, tm, layout)
+}
 
+// timestamp returns the time in the local or UTC time zone, and
+// the layout for printing, in according to the setting in flags.
+func (s *PrintCtx) timestamp(z time.Time) (tm time.Time, layout string) {
 	if s.utcTime == 2 || (s.utcTime == 0 && flags&LlocalTime == 0) {
 		tm = z.UTC()
 	} else {
 		tm = z
 	}
 
-	var layout string
 	if s.layout != "" {
 		layout = s.layout
 	} else {
@@ -2168,9 +2432,7 @@ func (s *PrintCtx) AppendTimestamp(z time.Time) {
 			layout = TimeNano
 		}
 	}
-
-	// return tm.Format(layout)
-	s.ip.AppendTimestamp(s, tm, layout)
+	return
 }
 
 func itoaS[T Integers](s *PrintCtx, val T) {
@@ -2187,6 +2449,10 @@ func itoaS[T Integers](s *PrintCtx, val T) {
 	// 	itoasimple(s, val, 10)
 	// }
 
+	if s.bin != nil {
+		s.bin.appendInt(s, int64(val))
+		return
+	}
 	itoasimple(s, val, 10)
 }
 
@@ -2199,7 +2465,9 @@ func utoaS[T Uintegers](s *PrintCtx, val T) {
 
 	// s.pcAppendStringValue(intToString(value))
 
-	if s.mode1 == ModeJSON {
+	if s.bin != nil {
+		s.bin.appendUint(s, uint64(val))
+	} else if s.mode1 == ModeJSON {
 		// if s.jsonMode {
 		s.checkerr(s.WriteByte('"'))
 		utoasimple(s, val, 10)
@@ -2218,13 +2486,24 @@ func ftoaS[T Floats](s *PrintCtx, val T) {
 
 	// s.pcAppendStringValue(intToString(value))
 
-	if s.mode1 == ModeJSON {
+	prec := s.floatPrecision()
+	if s.bin != nil {
+		v := float64(val)
+		if prec >= 0 {
+			v = roundFloat(v, prec)
+		}
+		if _, ok := any(val).(float32); ok {
+			s.bin.appendFloat(s, v, 32)
+		} else {
+			s.bin.appendFloat(s, v, 64)
+		}
+	} else if s.mode1 == ModeJSON {
 		// if s.jsonMode {
 		s.checkerr(s.WriteByte('"'))
-		ftoasimple(s, val, 'f', -1, 64)
+		ftoasimple(s, val, 'f', prec, 64)
 		s.checkerr(s.WriteByte('"'))
 	} else {
-		ftoasimple(s, val, 'f', -1, 64)
+		ftoasimple(s, val, 'f', prec, 64)
 	}
 }
 
@@ -2237,7 +2516,13 @@ func ctoaS[T Complexes](s *PrintCtx, val T) {
 
 	// s.pcAppendStringValue(intToString(value))
 
-	if s.mode1 == ModeJSON {
+	if s.bin != nil {
+		if _, ok := any(val).(complex64); ok {
+			s.bin.appendString(s, strconv.FormatComplex(complex128(val), 'f', -1, 64))
+		} else {
+			s.bin.appendString(s, strconv.FormatComplex(complex128(val), 'f', -1, 128))
+		}
+	} else if s.mode1 == ModeJSON {
 		// if s.jsonMode {
 		s.checkerr(s.WriteByte('"'))
 		ctoasimple(s, val, 'f', -1, 64)
@@ -2266,5 +2551,9 @@ func ctoasimple[T Complexes](s *PrintCtx, val T, format byte, prec, bitSize int)
 }
 
 func btoaS(s *PrintCtx, val bool) {
+	if s.bin != nil {
+		s.bin.appendBool(s, val)
+		return
+	}
 	s.buf = strconv.AppendBool(s.buf, val)
 }
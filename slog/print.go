@@ -8,6 +8,36 @@ import (
 )
 
 func (s *Entry) print(ctx context.Context, lvl Level, timestamp time.Time, stackFrame uintptr, msg string, kvps Attrs) {
+	if h := s.pipeline(); h != nil {
+		s.dispatch(ctx, h, lvl, timestamp, stackFrame, msg, kvps)
+		return
+	}
+	s.handle(ctx, nil, lvl, timestamp, stackFrame, msg, kvps)
+}
+
+// handle is the terminal of the logging pipeline, it paints a
+// record and writes it out. rec can be nil if the record is not
+// built yet.
+func (s *Entry) handle(ctx context.Context, rec *Record, lvl Level, timestamp time.Time, stackFrame uintptr, msg string, kvps Attrs) {
+	if s.hooked() && !inHooking(ctx) {
+		if rec == nil {
+			rec = newRecord(s, lvl, timestamp, stackFrame, msg, kvps)
+		}
+		if !s.runHooks(ctx, rec) {
+			return
+		}
+		lvl, timestamp, stackFrame, msg, kvps = rec.Level, rec.Time, rec.PC, rec.Msg, rec.Attrs
+	}
+
+	if rules := s.redactors(); len(rules) > 0 {
+		msg, kvps = redact(rules, msg, kvps)
+		if rec != nil {
+			rec.Msg, rec.Attrs = msg, kvps
+		}
+	}
+
+	s.record(ctx, rec, lvl, timestamp, stackFrame, msg, kvps)
+
 	pc := poolPrintCtx.Get().(*PrintCtx)
 
 	// pc.set will truncate internal buffer and reset all states for
@@ -15,45 +45,61 @@ func (s *Entry) print(ctx context.Context, lvl Level, timestamp time.Time, stack
 	// takes wasted bytes.
 	pc.set(s, lvl, timestamp, stackFrame, msg, kvps)
 
-	s.printImpl(ctx, pc)
+	data, n, err := s.printImpl(ctx, pc)
+	if !inHooking(ctx) {
+		s.written(ctx, rec, pc, data, n, err)
+	}
 
 	pc.putBack()
+
+	if len(s.modeWriters) > 0 {
+		s.printModeWriters(ctx, lvl, timestamp, stackFrame, msg, kvps)
+	}
+}
+
+func (s *Entry) printImpl(ctx context.Context, pc *PrintCtx) (data []byte, n int, err error) {
+	data = s.render(ctx, pc)
+	n, err = s.printOut(pc.lvl, data)
+	return
 }
 
-func (s *Entry) printImpl(ctx context.Context, pc *PrintCtx) {
-	_ = ctx
+// render paints a logging line into pc, and returns the result.
+func (s *Entry) render(ctx context.Context, pc *PrintCtx) (data []byte) {
+	pc.ctx = ctx
 
 	// s.Println() or s.Println("") will print out just an empty line,
 	// without timestamp, loggername, and others decorated fields.
-	if pc.lvl == AlwaysLevel && strings.Trim(pc.msg, "\n\r \t") == "" {
-		s.printOut(pc.lvl, []byte{'\n'})
-		return
+	if pc.lvl == AlwaysLevel && pc.bin == nil && strings.Trim(pc.msg, "\n\r \t") == "" {
+		return []byte{'\n'}
 	}
 
+	lp, painting := pc.ip.(LinePainter)
 	colorStyle := pc.IsColorStyle()
-	if colorStyle {
+	if colorStyle || painting {
 		pc.SetupColors()
 	}
 
 	pc.Begin()
 
-	s.printTimestamp(pc)
-	s.printLoggerName(pc)
-	s.printSeverity(pc)
-
-	if colorStyle {
+	var holdErrorValue error
+	if painting {
+		holdErrorValue = lp.PaintLine(pc)
+	} else if colorStyle {
+		s.printTimestamp(pc)
+		s.printLoggerName(pc)
+		s.printSeverity(pc)
 		s.printFirstLineOfMsg(pc)
-	} else { // json or logfmt
-		s.printMsg(pc)
-	}
 
-	holdErrorValue := serializeAttrs(pc, pc.kvps)
+		holdErrorValue = serializeAttrs(pc, pc.kvps)
 
-	if IsAnyBitsSet(Lcaller) {
-		s.printPC(pc)
-	}
+		if IsAnyBitsSet(Lcaller) {
+			s.printPC(pc)
+		}
 
-	s.printRestLinesOfMsg(pc)
+		s.printRestLinesOfMsg(pc)
+	} else { // json or logfmt
+		holdErrorValue = s.printFields(pc)
+	}
 
 	pc.appendErrorAfterPrinted(holdErrorValue)
 
@@ -61,9 +107,52 @@ func (s *Entry) printImpl(ctx context.Context, pc *PrintCtx) {
 
 	// ret = pc.String()
 	// s.printOut(pc.lvl, []byte(ret))
-	msg := pc.Bytes()
-	s.printOut(pc.lvl, msg)
-	// return
+	return pc.Bytes()
+}
+
+// printFields prints the built-in fields in the order of
+// FieldNames, and the attributes after the message.
+func (s *Entry) printFields(pc *PrintCtx) (holdErrorValue error) {
+	var sep, msgDone bool
+	comma := func() {
+		if sep {
+			pc.AddComma()
+		}
+		sep = true
+	}
+	for _, f := range pc.FieldNames().order() {
+		switch f {
+		case TimeField:
+			comma()
+			s.printTimestamp(pc)
+		case LoggerField:
+			if s.name != "" {
+				comma()
+				s.printLoggerName(pc)
+			}
+		case LevelField:
+			comma()
+			s.printSeverity(pc)
+		case MessageField:
+			if !msgDone {
+				comma()
+				s.printMsg(pc)
+				holdErrorValue = serializeAttrs(pc, pc.kvps)
+				msgDone = true
+			}
+		case CallerField:
+			if IsAnyBitsSet(Lcaller) {
+				comma()
+				s.printPC(pc)
+			}
+		}
+	}
+	if !msgDone {
+		comma()
+		s.printMsg(pc)
+		holdErrorValue = serializeAttrs(pc, pc.kvps)
+	}
+	return
 }
 
 func (s *Entry) printTimestamp(pc *PrintCtx) {
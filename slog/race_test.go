@@ -0,0 +1,9 @@
+//go:build race
+// +build race
+
+package slog
+
+// raceEnabled reports whether the tests are built with -race, which
+// allocates for the instrumentation, so the allocation tests are
+// skipped.
+const raceEnabled = true
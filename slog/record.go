@@ -0,0 +1,125 @@
+package slog
+
+import (
+	"context"
+	"time"
+)
+
+// Record holds the structured form of a logging line, before it
+// is encoded by a [Painter].
+//
+// The Attrs field is borrowed from an internal pool, it is valid
+// only during the call which receives the Record. Use Clone if you
+// want to keep it.
+type Record struct {
+	Time   time.Time // the timestamp of this logging line
+	Level  Level     // the logging level
+	Msg    string    // the message
+	PC     uintptr   // the caller, zero means no caller info
+	Attrs  Attrs     // the attributes, including the ones from logger and its parents
+	Logger *Entry    // the logger which emits this record
+}
+
+func newRecord(s *Entry, lvl Level, timestamp time.Time, stackFrame uintptr, msg string, kvps Attrs) *Record {
+	return &Record{
+		Time:   timestamp,
+		Level:  lvl,
+		Msg:    msg,
+		PC:     stackFrame,
+		Attrs:  kvps,
+		Logger: s,
+	}
+}
+
+// Source returns the caller info of this record. It returns
+// nil if PC is zero.
+func (r *Record) Source() *Source {
+	if r.PC == 0 {
+		return nil
+	}
+	var src Source
+	return src.Extract(r.PC)
+}
+
+// LoggerName returns the name of the logger which emits this record.
+func (r *Record) LoggerName() string {
+	if r.Logger == nil {
+		return ""
+	}
+	return r.Logger.Name()
+}
+
+// Clone returns a deep copy of this record, so it can be retained
+// after the logging call returned.
+func (r *Record) Clone() *Record {
+	c := *r
+	c.Attrs = cloneAttrs(r.Attrs)
+	return &c
+}
+
+func cloneAttrs(as Attrs) Attrs {
+	if as == nil {
+		return nil
+	}
+	ret := make(Attrs, 0, len(as))
+	for _, a := range as {
+		if a == nil {
+			continue
+		}
+		if g, ok := a.Value().(Attrs); ok {
+			ret = append(ret, NewGroupedAttr(a.Key(), cloneAttrs(g)...))
+			continue
+		}
+		ret = append(ret, NewAttr(a.Key(), a.Value()))
+	}
+	return ret
+}
+
+// Recorder will be invoked with each record before it is encoded.
+//
+// Recorders run in the terminal handler, that is, after the
+// middlewares (see [Middleware]), so a dropped record won't be
+// recorded.
+//
+// The record and its Attrs are valid only during the call, see
+// also [Record.Clone].
+type Recorder func(ctx context.Context, rec *Record)
+
+// WithRecorder adds a [Recorder] to a logger.
+//
+// A recorder is inherited by the child loggers.
+//
+// It is useful for capturing the logging lines in structured
+// form, such as what package logtest does.
+func WithRecorder(r Recorder) Opt {
+	return func(s *Entry) {
+		s.AddRecorder(r)
+	}
+}
+
+// AddRecorder adds a [Recorder] to this logger. See also [WithRecorder].
+func (s *Entry) AddRecorder(r Recorder) *Entry {
+	if r != nil {
+		s.recorders = append(s.recorders, r)
+	}
+	return s
+}
+
+// WithRecorder makes a child logger with the given [Recorder].
+func (s *Entry) WithRecorder(r Recorder) (newLogger *Entry) {
+	return s.newChildLogger(WithRecorder(r))
+}
+
+// record invokes the recorders of this logger and its parents.
+//
+// rec will be built from the other args if it is nil.
+func (s *Entry) record(ctx context.Context, rec *Record, lvl Level, timestamp time.Time, stackFrame uintptr, msg string, kvps Attrs) {
+	for p := s; p != nil; p = p.owner {
+		for _, r := range p.recorders {
+			if rec == nil {
+				rec = newRecord(s, lvl, timestamp, stackFrame, msg, kvps)
+			}
+			r(ctx, rec)
+		}
+	}
+}
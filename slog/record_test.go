@@ -0,0 +1,38 @@
+package slog
+
+import (
+	"context"
+	"io"
+	"testing"
+)
+
+func TestWithRecorder(t *testing.T) {
+	var recs []*Record
+	l := New("rec", WithWriter(io.Discard), WithErrorWriter(io.Discard), WithLevel(WarnLevel), WithRecorder(func(ctx context.Context, rec *Record) {
+		recs = append(recs, rec.Clone())
+	}))
+
+	l.Warn("hello", "a", 1, Group("g", "b", 2))
+	l.New("child").Error("from child")
+	l.Info("disabled")
+
+	if len(recs) != 2 {
+		t.Fatalf("expect 2 records, but got %d", len(recs))
+	}
+	if r := recs[0]; r.Msg != "hello" || r.Level != WarnLevel || r.LoggerName() != "rec" || len(r.Attrs) != 2 {
+		t.Fatalf("bad record: %+v", r)
+	}
+	if r := recs[1]; r.Msg != "from child" || r.LoggerName() != "child" {
+		t.Fatalf("bad record: %+v", r)
+	}
+	if src := recs[0].Source(); src == nil || src.Line == 0 {
+		t.Fatalf("expect source info, but got %v", src)
+	}
+
+	// Clone makes a deep copy
+	c := recs[0].Clone()
+	c.Attrs[1].Value().(Attrs)[0].SetValue(3)
+	if v := recs[0].Attrs[1].Value().(Attrs)[0].Value(); v != 2 {
+		t.Fatalf("Clone should be deep, but the original was changed to %v", v)
+	}
+}
@@ -0,0 +1,328 @@
+package slog
+
+import (
+	"crypto/sha256"
+	enchex "encoding/hex"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// RedactAction is the way to redact the sensitive data matched by
+// a [RedactRule].
+type RedactAction int
+
+const (
+	RedactMask     RedactAction = iota // replace the data with the mask, default is "******"
+	RedactHash                         // replace the data with "sha256:" and the first 16 hex digits of its hash, so the equal values can still be correlated
+	RedactTruncate                     // keep the leading characters of the data and append "…"
+	RedactDrop                         // remove the attribute, or remove the matched text from the message
+)
+
+// The patterns of the common sensitive values, for [RedactPattern].
+var (
+	PatternCreditCard = regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`)
+	PatternJWT        = regexp.MustCompile(`\beyJ[A-Za-z0-9_-]+\.eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`)
+	PatternEmail      = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+)
+
+// RedactRule tells which data are sensitive, and how to redact them.
+//
+// If Keys is set, the values of the matched keys are redacted. A key
+// pattern without dot matches the key in any group, or else it
+// matches the dotted path of the key, such as "user.password" and
+// "*.token". The patterns are case-insensitive, and "*" matches any
+// characters. A matched group is redacted as a whole.
+//
+// If Pattern is set, the matched parts of the string values are
+// redacted; if Keys is set too, only the values of the matched keys
+// are checked. With InMessage, the matched parts of the message are
+// redacted as well.
+//
+// RedactDrop removes the whole attribute if its value is matched.
+type RedactRule struct {
+	Keys      []string
+	Pattern   *regexp.Regexp
+	InMessage bool
+	Action    RedactAction
+	Mask      string // the replacement of RedactMask, default is "******"
+	Keep      int    // the characters kept by RedactTruncate, default is 4
+}
+
+// RedactKeys returns a rule which redacts the values of the keys.
+//
+//	logger := slog.New(slog.WithRedaction(
+//		slog.RedactKeys(slog.RedactMask, "password", "token", "*secret*"),
+//		slog.RedactPattern(slog.PatternCreditCard, slog.RedactTruncate),
+//	))
+func RedactKeys(action RedactAction, patterns ...string) RedactRule {
+	return RedactRule{Keys: patterns, Action: action}
+}
+
+// RedactPattern returns a rule which redacts the matched parts of
+// the string values and of the message.
+func RedactPattern(re *regexp.Regexp, action RedactAction) RedactRule {
+	return RedactRule{Pattern: re, InMessage: true, Action: action}
+}
+
+// redactRule is a RedactRule with the lower-cased key patterns.
+type redactRule struct {
+	RedactRule
+	leaves []string // the patterns without dot
+	paths  []string // the patterns of dotted paths
+}
+
+func newRedactRule(r RedactRule) (*redactRule, error) {
+	if len(r.Keys) == 0 && r.Pattern == nil {
+		return nil, fmt.Errorf("slog: redact rule without keys and pattern")
+	}
+	rr := &redactRule{RedactRule: r}
+	if rr.Mask == "" {
+		rr.Mask = "******"
+	}
+	if rr.Keep <= 0 {
+		rr.Keep = 4
+	}
+	for _, k := range r.Keys {
+		k = strings.ToLower(k)
+		if _, err := path.Match(k, ""); err != nil {
+			return nil, fmt.Errorf("slog: bad redact key %q: %w", k, err)
+		}
+		if strings.Contains(k, ".") {
+			rr.paths = append(rr.paths, k)
+		} else {
+			rr.leaves = append(rr.leaves, k)
+		}
+	}
+	return rr, nil
+}
+
+// matchKey reports whether the key, or its dotted path, is matched.
+func (s *redactRule) matchKey(key, fullKey string) bool {
+	if len(s.leaves) > 0 {
+		key = strings.ToLower(key)
+		for _, p := range s.leaves {
+			if ok, _ := path.Match(p, key); ok {
+				return true
+			}
+		}
+	}
+	if len(s.paths) > 0 {
+		fullKey = strings.ToLower(fullKey)
+		for _, p := range s.paths {
+			if ok, _ := path.Match(p, fullKey); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// redactText applies the action to the whole text.
+func (s *redactRule) redactText(text string) string {
+	switch s.Action {
+	case RedactHash:
+		sum := sha256.Sum256([]byte(text))
+		return "sha256:" + enchex.EncodeToString(sum[:8])
+	case RedactTruncate:
+		if utf8.RuneCountInString(text) <= s.Keep {
+			return text
+		}
+		i, n := 0, 0
+		for i = range text {
+			if n == s.Keep {
+				break
+			}
+			n++
+		}
+		return text[:i] + "…"
+	case RedactDrop:
+		return ""
+	}
+	return s.Mask
+}
+
+// redactMatches applies the action to the matched parts of text.
+func (s *redactRule) redactMatches(text string) (string, bool) {
+	if !s.Pattern.MatchString(text) {
+		return text, false
+	}
+	return s.Pattern.ReplaceAllStringFunc(text, s.redactText), true
+}
+
+// WithRedaction adds the redaction rules to a logger. See [Entry.AddRedaction].
+func WithRedaction(rules ...RedactRule) Opt {
+	return func(s *Entry) {
+		s.AddRedaction(rules...)
+	}
+}
+
+// AddRedaction adds the rules to redact the sensitive data before
+// a record is recorded and encoded, so the rules apply to all
+// painters, mode writers and recorders identically. The hooks run
+// before redaction, they see the original data.
+//
+// The rules are inherited by the child loggers. The ones of a
+// parent logger apply before the ones of its children.
+//
+// It panics on a bad rule, such as a rule without keys and
+// pattern, or a bad key pattern.
+func (s *Entry) AddRedaction(rules ...RedactRule) *Entry {
+	for _, r := range rules {
+		rr, err := newRedactRule(r)
+		if err != nil {
+			panic(err)
+		}
+		s.redactRules = append(s.redactRules, rr)
+	}
+	return s
+}
+
+// WithRedaction makes a child logger with more redaction rules.
+func (s *Entry) WithRedaction(rules ...RedactRule) (newLogger *Entry) {
+	return s.newChildLogger(WithRedaction(rules...))
+}
+
+// ResetRedaction removes the redaction rules of this logger. The
+// ones of its parents are kept.
+func (s *Entry) ResetRedaction() *Entry {
+	s.redactRules = nil
+	return s
+}
+
+// redactors collects the redaction rules from root to s.
+func (s *Entry) redactors() (rules []*redactRule) {
+	var chain []*Entry
+	for p := s; p != nil; p = p.owner {
+		if len(p.redactRules) > 0 {
+			chain = append(chain, p)
+		}
+	}
+	if len(chain) == 1 {
+		return chain[0].redactRules
+	}
+	for i := len(chain) - 1; i >= 0; i-- {
+		rules = append(rules, chain[i].redactRules...)
+	}
+	return
+}
+
+// redact applies the rules to the message and the attributes. The
+// attributes are never modified, a new slice is returned if any
+// of them is redacted.
+func redact(rules []*redactRule, msg string, kvps Attrs) (string, Attrs) {
+	for _, r := range rules {
+		if r.InMessage && r.Pattern != nil {
+			msg, _ = r.redactMatches(msg)
+		}
+	}
+	if as, changed := redactAttrs(rules, "", kvps); changed {
+		kvps = as
+	}
+	return msg, kvps
+}
+
+func redactAttrs(rules []*redactRule, prefix string, as Attrs) (ret Attrs, changed bool) {
+	for i, a := range as {
+		var (
+			na   = a
+			drop bool
+		)
+		if a != nil {
+			na, drop = redactAttr(rules, prefix, a)
+		}
+		if na != a || drop {
+			if !changed {
+				ret, changed = make(Attrs, i, len(as)), true
+				copy(ret, as[:i])
+			}
+		}
+		if changed && !drop {
+			ret = append(ret, na)
+		}
+	}
+	if !changed {
+		ret = as
+	}
+	return
+}
+
+func redactAttr(rules []*redactRule, prefix string, a Attr) (na Attr, drop bool) {
+	key, val := a.Key(), a.Value()
+	fullKey := key
+	if prefix != "" {
+		fullKey = prefix + "." + key
+	}
+	na = a
+
+	var text string
+	var textual, redacted bool
+	for _, r := range rules {
+		if len(r.Keys) > 0 && !r.matchKey(key, fullKey) {
+			continue
+		}
+		if r.Pattern == nil { // the whole value of the key
+			if r.Action == RedactDrop {
+				return nil, true
+			}
+			if !textual {
+				text = textOf(val)
+			}
+			text, textual, redacted = r.redactText(text), true, true
+			continue
+		}
+		if !textual {
+			var ok bool
+			if text, ok = stringValueOf(val); !ok {
+				continue
+			}
+			textual = true
+		}
+		var matched bool
+		if text, matched = r.redactMatches(text); matched {
+			if r.Action == RedactDrop {
+				return nil, true
+			}
+			redacted = true
+		}
+	}
+	if redacted {
+		return NewAttr(key, text), false
+	}
+
+	if g, ok := val.(Attrs); ok {
+		if items, changed := redactAttrs(rules, fullKey, g); changed {
+			na = NewGroupedAttr(key, items...)
+		}
+	}
+	return
+}
+
+// stringValueOf returns the text of the string-like values, which
+// are checked by the patterns.
+func stringValueOf(val any) (string, bool) {
+	switch z := val.(type) {
+	case string:
+		return z, true
+	case []byte:
+		return string(z), true
+	case error:
+		return z.Error(), true
+	case Stringer:
+		return z.String(), true
+	}
+	return "", false
+}
+
+// textOf returns the text of any value, for redacting it as a whole.
+func textOf(val any) string {
+	if s, ok := stringValueOf(val); ok {
+		return s
+	}
+	if _, ok := val.(Attrs); ok {
+		return "" // a group
+	}
+	return fmt.Sprint(val)
+}
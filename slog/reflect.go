@@ -0,0 +1,578 @@
+package slog
+
+import (
+	"encoding"
+	"fmt"
+	"net/url"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// reflectMaxDepth is the max nesting depth of the structs, maps and
+// slices encoded by reflection. The deeper values are printed as
+// "<max depth>".
+const reflectMaxDepth = 16
+
+// fieldInfo is the cached encoding info of a struct field.
+//
+// The options are read from the tag `logg:"name,omitempty,redact,string"`,
+// or from the json tag if there is no logg tag:
+//
+//   - name: the key, default is the field name; "-" skips the field
+//   - omitempty: skips the field if it has the zero value
+//   - redact: prints a mask instead of the value
+//   - string: prints the value as a quoted string
+type fieldInfo struct {
+	name      string
+	index     []int
+	omitEmpty bool
+	redact    bool
+	asString  bool
+}
+
+var structFieldsCache sync.Map // map[reflect.Type][]fieldInfo
+
+// structFields returns the fields of a struct type to be encoded,
+// the fields of the embedded structs without tag name are inlined.
+func structFields(typ reflect.Type) []fieldInfo {
+	if fields, ok := structFieldsCache.Load(typ); ok {
+		return fields.([]fieldInfo)
+	}
+	fields := appendStructFields(nil, typ, nil, 0)
+	actual, _ := structFieldsCache.LoadOrStore(typ, fields)
+	return actual.([]fieldInfo)
+}
+
+func appendStructFields(fields []fieldInfo, typ reflect.Type, index []int, depth int) []fieldInfo {
+	for i := 0; i < typ.NumField(); i++ {
+		sf := typ.Field(i)
+		tag, ok := sf.Tag.Lookup("logg")
+		if !ok {
+			tag = sf.Tag.Get("json")
+		}
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+
+		idx := append(slices.Clip(index), i)
+		if sf.Anonymous && name == "" {
+			t := sf.Type
+			if t.Kind() == reflect.Pointer {
+				t = t.Elem()
+			}
+			if t.Kind() == reflect.Struct && depth < reflectMaxDepth {
+				fields = appendStructFields(fields, t, idx, depth+1)
+				continue
+			}
+		}
+		if !sf.IsExported() {
+			continue
+		}
+
+		fi := fieldInfo{name: name, index: idx}
+		if fi.name == "" {
+			fi.name = sf.Name
+		}
+		for opts != "" {
+			var opt string
+			opt, opts, _ = strings.Cut(opts, ",")
+			switch opt {
+			case "omitempty":
+				fi.omitEmpty = true
+			case "redact":
+				fi.redact = true
+			case "string":
+				fi.asString = true
+			}
+		}
+		fields = append(fields, fi)
+	}
+	return fields
+}
+
+// reflectRef identifies a pointer, map or slice being encoded, for
+// detecting the cycles.
+type reflectRef struct {
+	ptr uintptr
+	len int
+	typ reflect.Type
+}
+
+// enterRef pushes ref onto the stack of the values being encoded. It
+// prints a marker and returns false if the value is too deep, or if
+// it is being encoded (a cycle).
+func (s *PrintCtx) enterRef(ref reflectRef) bool {
+	if len(s.reflectRefs) >= s.reflectDepth() {
+		s.pcTryQuoteValue("<max depth>")
+		return false
+	}
+	if ref.ptr != 0 && slices.Contains(s.reflectRefs, ref) {
+		s.pcTryQuoteValue("<cycle>")
+		return false
+	}
+	s.reflectRefs = append(s.reflectRefs, ref)
+	return true
+}
+
+// EnterValue guards a marshaller of a pointer value against the
+// cycles and the too-deep values. If it returns false, a marker has
+// been printed instead of the value, or else LeaveValue must be
+// called after the value is encoded:
+//
+//	func (u *user) MarshalSlogObject(enc *slog.PrintCtx) error {
+//		if !enc.EnterValue(u) {
+//			return nil
+//		}
+//		defer enc.LeaveValue()
+//		enc.Begin()
+//		...
+//	}
+//
+// The code generated by logg-gen does it.
+func (s *PrintCtx) EnterValue(ptr any) bool {
+	v := reflect.ValueOf(ptr)
+	ref := reflectRef{len: -1}
+	if v.Kind() == reflect.Pointer {
+		ref.ptr, ref.typ = v.Pointer(), v.Type()
+	}
+	return s.enterRef(ref)
+}
+
+// LeaveValue ends a value began by EnterValue.
+func (s *PrintCtx) LeaveValue() {
+	if n := len(s.reflectRefs); n > 0 {
+		s.reflectRefs = s.reflectRefs[:n-1]
+	}
+}
+
+// appendReflect encodes the structs, maps, slices, pointers and the
+// values of named basic types natively, and reports whether val is
+// encoded. The struct tags are honored, see fieldInfo.
+func (s *PrintCtx) appendReflect(val any) bool {
+	v := reflect.ValueOf(val)
+	switch v.Kind() {
+	case reflect.Invalid, reflect.Chan, reflect.Func, reflect.UnsafePointer:
+		return false
+	}
+	s.appendReflectValue(v)
+	return true
+}
+
+func (s *PrintCtx) appendReflectValue(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Bool:
+		btoaS(s, v.Bool())
+		return
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		itoaS(s, v.Int())
+		return
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		utoaS(s, v.Uint())
+		return
+	case reflect.Float32:
+		ftoaS(s, float32(v.Float()))
+		return
+	case reflect.Float64:
+		ftoaS(s, v.Float())
+		return
+	case reflect.Complex64, reflect.Complex128:
+		ctoaS(s, v.Complex())
+		return
+	case reflect.String:
+		s.pcQuoteValue(v.String())
+		return
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			s.appendValue(nil)
+			return
+		}
+	case reflect.Map, reflect.Slice:
+		if v.IsNil() {
+			s.appendValue(nil)
+			return
+		}
+	case reflect.Struct, reflect.Array:
+	default:
+		s.pcTryQuoteValue(fmt.Sprintf("{{%+v}}", v))
+		return
+	}
+
+	ref := reflectRef{len: -1}
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Map:
+		ref.ptr, ref.typ = v.Pointer(), v.Type()
+	case reflect.Slice:
+		ref.ptr, ref.len, ref.typ = v.Pointer(), v.Len(), v.Type()
+	}
+	if !s.enterRef(ref) {
+		return
+	}
+	defer s.LeaveValue()
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		s.appendElem(v.Elem())
+	case reflect.Struct:
+		s.appendStruct(v)
+	case reflect.Map:
+		s.appendMap(v)
+	default: // slice, array
+		keep, more := s.SliceLimit(v.Len())
+		s.BeginArray()
+		if v.Len() == 0 {
+			s.appendEmptyText("[]")
+		}
+		for i := 0; i < keep; i++ {
+			if i > 0 {
+				s.AddComma()
+			}
+			s.appendElem(v.Index(i))
+		}
+		s.AddMoreItems(keep, more)
+		s.EndArray(false)
+	}
+}
+
+// appendElem encodes an element of a composite value. The elements
+// implementing the known interfaces, such as ObjectMarshaller and
+// Stringer, are encoded by appendValue.
+func (s *PrintCtx) appendElem(v reflect.Value) {
+	if v.Kind() == reflect.Interface && !v.IsNil() {
+		v = v.Elem()
+	}
+	if v.CanInterface() {
+		typ := v.Type()
+		if typ.PkgPath() != "" || typ.Name() == "" || v.Kind() == reflect.Interface {
+			s.appendValue(v.Interface()) // named types may implement the interfaces
+			return
+		}
+	}
+	s.appendReflectValue(v)
+}
+
+// appendStruct encodes a struct with the typed Add* methods, as the
+// code generated by logg-gen does, so both print the same output.
+func (s *PrintCtx) appendStruct(v reflect.Value) {
+	s.Begin()
+	first := true
+	for _, fi := range structFields(v.Type()) {
+		fv, err := v.FieldByIndexErr(fi.index)
+		if err != nil { // through a nil embedded pointer
+			continue
+		}
+		if fi.omitEmpty && isEmptyValue(fv) {
+			continue
+		}
+		if !first {
+			s.AddComma()
+		}
+		first = false
+		switch {
+		case fi.redact:
+			s.AddString(fi.name, "******")
+		case fi.asString:
+			s.AddString(fi.name, reflectText(fv))
+		default:
+			s.addField(fi.name, fv)
+		}
+	}
+	if first {
+		s.appendEmptyText("{}")
+	}
+	s.End(false)
+}
+
+var (
+	timeType     = reflect.TypeFor[time.Time]()
+	durationType = reflect.TypeFor[time.Duration]()
+)
+
+// addField encodes a struct field. The fields of the predeclared
+// types, time.Time and time.Duration are encoded by the typed Add*
+// methods, the others by AddAny.
+func (s *PrintCtx) addField(name string, v reflect.Value) {
+	switch typ := v.Type(); {
+	case typ == timeType && v.CanInterface():
+		s.AddTime(name, v.Interface().(time.Time))
+		return
+	case typ == durationType:
+		s.AddDuration(name, time.Duration(v.Int()))
+		return
+	case typ.PkgPath() != "" || typ.Name() == "" || v.Kind() == reflect.Interface: // error is predeclared too
+		if v.CanInterface() {
+			s.AddAny(name, v.Interface())
+			return
+		}
+		s.AppendStringKey(name)
+		s.pcAppendColon()
+		s.appendReflectValue(v)
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		s.AddString(name, v.String())
+	case reflect.Bool:
+		s.AddBool(name, v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		s.AddInt64(name, v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		s.AddUint64(name, v.Uint())
+	case reflect.Float32:
+		s.AddFloat32(name, float32(v.Float()))
+	case reflect.Float64:
+		s.AddFloat64(name, v.Float())
+	case reflect.Complex64:
+		s.AddComplex64(name, complex64(v.Complex()))
+	default:
+		s.AddComplex128(name, v.Complex())
+	}
+}
+
+// appendMap encodes a map with the keys sorted, see mapEntries.
+func (s *PrintCtx) appendMap(v reflect.Value) {
+	entries := mapEntries(v)
+	keep, more := s.SliceLimit(len(entries))
+	s.Begin()
+	if len(entries) == 0 {
+		s.appendEmptyText("{}")
+	}
+	for i, e := range entries[:keep] {
+		if i > 0 {
+			s.AddComma()
+		}
+		s.AppendStringKey(e.key)
+		s.pcAppendColon()
+		s.appendElem(e.val)
+	}
+	if more > 0 {
+		if keep > 0 {
+			s.AddComma()
+		}
+		s.AppendStringKey("…")
+		s.pcAppendColon()
+		s.AppendQuotedString(moreMarker(more, "items"))
+	}
+	s.End(false)
+}
+
+// appendEmptyText writes "{}" or "[]" for an empty composite value
+// in the text modes, in which Begin and End print nothing, so that
+// the key isn't left without a value, such as "ms=".
+func (s *PrintCtx) appendEmptyText(brackets string) {
+	if s.mode1 != ModeJSON {
+		s.AppendString(brackets)
+	}
+}
+
+type mapEntry struct {
+	key string
+	num int64
+	val reflect.Value
+}
+
+// mapEntries returns the entries of a map with the keys sorted, the
+// integer keys are sorted numerically.
+func mapEntries(v reflect.Value) []mapEntry {
+	numeric := false
+	switch v.Type().Key().Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		numeric = true
+	}
+	entries := make([]mapEntry, 0, v.Len())
+	for it := v.MapRange(); it.Next(); {
+		k := it.Key()
+		e := mapEntry{key: reflectText(k), val: it.Value()}
+		if numeric {
+			e.num = k.Int()
+		}
+		entries = append(entries, e)
+	}
+	slices.SortFunc(entries, func(a, b mapEntry) int {
+		if numeric {
+			return compareInt(a.num, b.num)
+		}
+		return strings.Compare(a.key, b.key)
+	})
+	return entries
+}
+
+// reflected reports whether val would be encoded by reflection, see
+// appendValue.
+func reflected(val any) bool {
+	switch val.(type) {
+	case nil, ObjectSerializer, ArrayMarshaller, ObjectMarshaller, Value, Attrs,
+		time.Time, []time.Duration, []time.Time, error, ToString, *url.URL, url.URL,
+		Stringer, encoding.TextMarshaler, []byte, []string, []bool,
+		[]int, []int8, []int16, []int32, []int64, []uint, []uint16, []uint32, []uint64,
+		[]float32, []float64, []complex64, []complex128:
+		return false
+	}
+	return true
+}
+
+// flatValue returns the struct, map, slice or array of an attribute
+// value, which is encoded by reflection and is to be flattened to the
+// dotted keys in the text modes. The pointers are dereferenced, ref
+// identifies the value for detecting the cycles.
+func flatValue(val Value) (v reflect.Value, ref reflectRef, ok bool) {
+	if val.typ != TypeAny || !reflected(val.any) {
+		return
+	}
+	v, ref = reflect.ValueOf(val.any), reflectRef{len: -1}
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return
+		}
+		if ref.ptr == 0 {
+			ref.ptr, ref.typ = v.Pointer(), v.Type()
+		}
+		if v = v.Elem(); v.CanInterface() && !reflected(v.Interface()) {
+			return // named types may implement the interfaces
+		}
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		ok = len(structFields(v.Type())) > 0
+	case reflect.Map, reflect.Slice:
+		if ref.ptr == 0 {
+			ref.ptr, ref.typ = v.Pointer(), v.Type()
+			if v.Kind() == reflect.Slice {
+				ref.len = v.Len()
+			}
+		}
+		ok = v.Len() > 0
+	case reflect.Array:
+		ok = v.Len() > 0
+	}
+	return
+}
+
+// enterFlat pushes ref as enterRef, but prints nothing. If it returns
+// false, the value is encoded by reflection, which prints the marker.
+func (s *PrintCtx) enterFlat(ref reflectRef) bool {
+	if len(s.reflectRefs) >= s.reflectDepth() ||
+		ref.ptr != 0 && slices.Contains(s.reflectRefs, ref) {
+		return false
+	}
+	s.reflectRefs = append(s.reflectRefs, ref)
+	return true
+}
+
+// appendFlat writes the fields of a struct, the entries of a map or
+// the elements of a slice as the attributes under s.prefix, such as
+// "user.name=alice" and "user.tags.0=x", as the groups are flattened.
+// It reports whether anything is written.
+func (s *PrintCtx) appendFlat(v reflect.Value) (written bool) {
+	switch v.Kind() {
+	case reflect.Struct:
+		for _, fi := range structFields(v.Type()) {
+			fv, err := v.FieldByIndexErr(fi.index)
+			if err != nil { // through a nil embedded pointer
+				continue
+			}
+			if fi.omitEmpty && isEmptyValue(fv) {
+				continue
+			}
+			switch {
+			case fi.redact:
+				s.appendFlatAttr(fi.name, StringValue("******"))
+			case fi.asString:
+				s.appendFlatAttr(fi.name, StringValue(reflectText(fv)))
+			default:
+				s.appendFlatAttr(fi.name, flatElem(fv))
+			}
+			written = true
+		}
+		return
+
+	case reflect.Map:
+		entries := mapEntries(v)
+		keep, more := s.SliceLimit(len(entries))
+		for _, e := range entries[:keep] {
+			s.appendFlatAttr(e.key, flatElem(e.val))
+		}
+		s.appendFlatMore(more)
+
+	default: // slice, array
+		keep, more := s.SliceLimit(v.Len())
+		for i := 0; i < keep; i++ {
+			s.appendFlatAttr(strconv.Itoa(i), flatElem(v.Index(i)))
+		}
+		s.appendFlatMore(more)
+	}
+	return true
+}
+
+func (s *PrintCtx) appendFlatAttr(key string, val Value) {
+	_ = s.appendAttr(key, val) // the error values inside aren't held, as appendReflect
+}
+
+// appendFlatMore writes the marker of the truncated entries.
+func (s *PrintCtx) appendFlatMore(more int) {
+	if more > 0 {
+		s.appendFlatAttr("…", Value{any: limitMarker(moreMarker(more, "items"))})
+	}
+}
+
+// flatElem returns the Value of a field or an element.
+func flatElem(v reflect.Value) Value {
+	if v.CanInterface() {
+		return Value{any: v.Interface()}
+	}
+	return StringValue(reflectText(v))
+}
+
+func compareInt(a, b int64) int {
+	if a < b {
+		return -1
+	} else if a > b {
+		return 1
+	}
+	return 0
+}
+
+// reflectText returns the text of a map key or a value with the
+// "string" option.
+func reflectText(v reflect.Value) string {
+	switch v.Kind() {
+	case reflect.String:
+		return v.String()
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(v.Uint(), 10)
+	case reflect.Float32:
+		return strconv.FormatFloat(v.Float(), 'g', -1, 32)
+	case reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, 64)
+	}
+	if v.CanInterface() {
+		switch z := v.Interface().(type) {
+		case encoding.TextMarshaler:
+			if data, err := z.MarshalText(); err == nil {
+				return string(data)
+			}
+		case Stringer:
+			return z.String()
+		}
+	}
+	return fmt.Sprint(v)
+}
+
+// isEmptyValue reports whether v is empty for the omitempty option,
+// like encoding/json does.
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	}
+	return v.IsZero()
+}
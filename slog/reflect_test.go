@@ -0,0 +1,165 @@
+package slog
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type reflBase struct {
+	ID int `logg:"id"`
+}
+
+type reflUser struct {
+	reflBase
+	Name    string            `json:"name"`
+	Pass    string            `logg:"pass,redact"`
+	Count   int64             `logg:"count,string"`
+	Note    string            `logg:",omitempty"`
+	Skipped string            `logg:"-"`
+	Scores  map[int]string    `json:"scores,omitempty"`
+	Labels  map[string]uint16 `logg:"labels"`
+	Created time.Time         `logg:"created"`
+	Level   Level             `logg:"level"`
+	Friend  *reflUser         `logg:"friend,omitempty"`
+	hidden  int
+}
+
+func TestReflectEncoder(t *testing.T) {
+	u := &reflUser{
+		reflBase: reflBase{7},
+		Name:     "alice",
+		Pass:     "hunter2",
+		Count:    5,
+		Skipped:  "no",
+		Scores:   map[int]string{10: "ten", 2: "two", -1: "neg"},
+		Labels:   map[string]uint16{"z": 1, "a": 2},
+		Created:  time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC),
+		Level:    WarnLevel,
+		hidden:   1,
+	}
+	u.Friend = &reflUser{Name: "bob", Friend: u} // a cycle
+
+	var buf bytes.Buffer
+	l := New("refl", WithWriter(&buf), WithLevel(InfoLevel), WithJSONMode())
+	l.Info("hi", "user", u, "any", map[string]any{"b": []any{1, "x", nil}, "a": struct{ X float64 }{0.5}})
+
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(buf.Bytes(), &m); err != nil {
+		t.Fatalf("bad json %q: %v", buf.String(), err)
+	}
+	for k, want := range map[string]string{
+		"user": `{"id":7,"name":"alice","pass":"******","count":"5","scores":{"-1":"neg","2":"two","10":"ten"},"labels":{"a":"2","z":"1"},` +
+			`"created":"2024-05-06T07:08:09Z","level":"warning","friend":{"id":0,"name":"bob","pass":"******","count":"0","labels":null,` +
+			`"created":"0001-01-01T00:00:00Z","level":"panic","friend":"<cycle>"}}`,
+		"any": `{"a":{"X":"0.5"},"b":[1,"x",null]}`,
+	} {
+		if got := string(m[k]); got != want {
+			t.Fatalf("expect %s =\n  %s\nbut got\n  %s", k, want, got)
+		}
+	}
+
+	// the same fields in the text modes
+	for _, opt := range []Opt{WithMode(ModeLogFmt), WithMode(ModePlain), WithColorMode()} {
+		buf.Reset()
+		l = New("refl", WithWriter(&buf), WithLevel(InfoLevel), opt)
+		l.Info("hi", "user", u)
+		out := buf.String()
+		if strings.Contains(out, "hunter2") || strings.Contains(out, "{{") ||
+			!strings.Contains(out, "alice") || !strings.Contains(out, "<cycle>") {
+			t.Fatalf("unexpected output: %q", out)
+		}
+	}
+}
+
+func TestReflectDepth(t *testing.T) {
+	var v any = "leaf"
+	for i := 0; i < reflectMaxDepth+4; i++ {
+		v = []any{v}
+	}
+
+	var buf bytes.Buffer
+	l := New("refl", WithWriter(&buf), WithLevel(InfoLevel), WithJSONMode())
+	l.Info("deep", "v", v)
+	if !strings.Contains(buf.String(), `"<max depth>"`) || strings.Contains(buf.String(), "leaf") {
+		t.Fatalf("expect the depth limited, but got %q", buf.String())
+	}
+	var m map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &m); err != nil {
+		t.Fatalf("bad json %q: %v", buf.String(), err)
+	}
+}
+
+func TestStructFieldsCache(t *testing.T) {
+	typ := func() any { return reflUser{} }()
+	f1 := structFields(reflect.TypeOf(typ))
+	f2 := structFields(reflect.TypeOf(typ))
+	if len(f1) != 10 || &f1[0] != &f2[0] {
+		t.Fatalf("expect the cached fields, but got %+v", f1)
+	}
+	if f1[0].name != "id" || len(f1[0].index) != 2 {
+		t.Fatalf("expect the embedded field inlined, but got %+v", f1[0])
+	}
+}
+
+type reflTeam struct {
+	Name    string             `logg:"name"`
+	Members []reflUser         `logg:"members"`
+	Leads   map[string]reflKey `logg:"leads"`
+	Tags    []string           `logg:"tags"`
+	Empty   []reflKey          `logg:"empty"`
+}
+
+type reflKey struct {
+	A, B int
+}
+
+func TestReflectFlatten(t *testing.T) {
+	team := &reflTeam{
+		Name:    "core",
+		Members: []reflUser{{Name: "alice", Pass: "x"}, {Name: "bob", Note: "n"}},
+		Leads:   map[string]reflKey{"z": {1, 2}, "a": {3, 4}},
+		Tags:    []string{"x", "y"},
+	}
+	for _, opt := range []Opt{WithMode(ModeLogFmt), WithColorMode(false)} {
+		var buf bytes.Buffer
+		l := New("refl", WithWriter(&buf), WithLevel(InfoLevel), opt)
+		l.Info("hi", "team", team)
+		l = New("refl", WithWriter(&buf), WithLevel(InfoLevel), WithLimits(Limits{MaxSliceLen: 1}), opt)
+		l.Info("hi", "keys", []reflKey{{5, 6}, {7, 8}})
+		out := buf.String()
+		for _, want := range []string{
+			` team.name="core" team.members.0.id=0 team.members.0.name="alice" team.members.0.pass="******" `,
+			` team.members.1.name="bob" team.members.1.pass="******" team.members.1.count="0" team.members.1.Note="n" `,
+			` team.leads.a.A=3 team.leads.a.B=4 team.leads.z.A=1 team.leads.z.B=2 `,
+			` team.empty=<nil> `,
+			` keys.0.A=5 keys.0.B=6 keys.…="…(+1 items)" `,
+		} {
+			if !strings.Contains(out, want) {
+				t.Fatalf("expect the dotted keys %q, but got %q", want, out)
+			}
+		}
+	}
+}
+
+func TestReflectFlattenEmpty(t *testing.T) {
+	type empty struct{}
+	type omitted struct {
+		A int `json:"a,omitempty"`
+	}
+	for _, opt := range []Opt{WithMode(ModeLogFmt), WithColorMode(false)} {
+		var buf bytes.Buffer
+		l := New("refl", WithWriter(&buf), WithLevel(InfoLevel), opt)
+		l.Info("hi", "e", empty{}, "o", omitted{}, "ms", map[string]int{}, "s", []reflKey{}, "a", [0]int{},
+			"nested", struct {
+				M map[string]int
+				S []string
+			}{map[string]int{}, []string{}})
+		if out, want := buf.String(), ` e={} o={} ms={} s=[] a=[] nested.M={} nested.S=[] `; !strings.Contains(out, want) {
+			t.Fatalf("expect %q, but got %q", want, out)
+		}
+	}
+}
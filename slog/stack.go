@@ -18,6 +18,8 @@ type Source struct {
 	// line. These may be the empty string and zero, respectively, if not known.
 	File string `json:"file"`
 	Line int    `json:"line"`
+
+	abs string // the original path of File, for the hyperlinks
 }
 
 func (s Source) toGroup() (as Attr) {
@@ -55,6 +57,7 @@ func (s *Source) Extract(pc uintptr) *Source {
 	frame, _ := frames.Next()
 	s.Function = frame.Function
 	s.File = checkpath(frame.File)
+	s.abs = frame.File
 	s.Line = frame.Line
 	return s
 }
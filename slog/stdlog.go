@@ -0,0 +1,61 @@
+package slog
+
+import (
+	"log"
+	logslog "log/slog"
+)
+
+// InstallAsDefault takes over the standard log package and the
+// default logger of log/slog, so that the outputs from third-party
+// libraries can be routed into logger.
+//
+// After installed,
+//
+//   - log.Print, log.Printf, ... will be logged at InfoLevel. The
+//     prefix and flags of the standard logger are still honored: the
+//     header (date, time, file:line) is stripped and the caller is
+//     recomputed, the prefix will be emitted as a "prefix" attribute.
+//   - log/slog.Info, log/slog.Default().Warn, ... will be logged
+//     through a handler created by NewSlogHandler.
+//
+// If logger is nil, Default() will be used. If opts is nil, the
+// logger's settings (mode, level, and Lcaller flag) are kept as is,
+// or else they will be applied as NewSlogHandler does.
+//
+// The returned undo function restores the standard log package and
+// log/slog to their previous states. It is useful in testing:
+//
+//	undo := slog.InstallAsDefault(logger, nil)
+//	defer undo()
+func InstallAsDefault(logger Logger, opts *HandlerOptions) (undo func()) {
+	if logger == nil {
+		logger = Default()
+	}
+
+	var h logslog.Handler
+	if opts == nil {
+		h = &handler4LogSlog{logger}
+	} else {
+		h = NewSlogHandler(logger, opts)
+	}
+
+	std := log.Default()
+	savedDefault := logslog.Default()
+	savedWriter, savedFlags, savedPrefix := std.Writer(), std.Flags(), std.Prefix()
+
+	// log/slog.SetDefault resets the flags of the standard logger,
+	// and redirects it to log/slog, so we override its output later.
+	logslog.SetDefault(logslog.New(h))
+	std.SetFlags(savedFlags)
+	std.SetOutput(&handlerWriter{l: logger, lvl: InfoLevel, capturePC: true, std: std})
+
+	return func() {
+		// log/slog.SetDefault changes the standard logger if the
+		// saved one is not the builtin default handler, so restore
+		// the standard logger after it.
+		logslog.SetDefault(savedDefault)
+		std.SetOutput(savedWriter)
+		std.SetFlags(savedFlags)
+		std.SetPrefix(savedPrefix)
+	}
+}
@@ -0,0 +1,83 @@
+package slog
+
+import (
+	"bytes"
+	"log"
+	logslog "log/slog"
+	"strings"
+	"testing"
+)
+
+func TestInstallAsDefault(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New("stdlog", WithWriter(&buf), WithJSONMode(true), WithLevel(InfoLevel))
+
+	saved := log.Writer()
+	undo := InstallAsDefault(logger, nil)
+
+	log.SetPrefix("[app] ")
+	log.SetFlags(log.LstdFlags | log.Lshortfile)
+	log.Print("from std log")
+
+	out := buf.String()
+	t.Log(out)
+	if !strings.Contains(out, `"msg":"from std log"`) {
+		t.Fatalf("std log message not routed, got: %q", out)
+	}
+	if !strings.Contains(out, `"prefix":"[app]"`) {
+		t.Fatalf("std log prefix should be emitted as an attribute, got: %q", out)
+	}
+	if !strings.Contains(out, "stdlog_test.go") {
+		t.Fatalf("caller should point to the test file, got: %q", out)
+	}
+
+	buf.Reset()
+	logslog.Info("from log/slog", "k", "v")
+	if out = buf.String(); !strings.Contains(out, `"msg":"from log/slog"`) {
+		t.Fatalf("log/slog message not routed, got: %q", out)
+	}
+
+	buf.Reset()
+	savedLevel := logger.Level()
+	logger.SetLevel(WarnLevel)
+	log.Print("suppressed")
+	if out = buf.String(); out != "" {
+		t.Fatalf("std log should honor logger level, got: %q", out)
+	}
+	logger.SetLevel(savedLevel)
+
+	undo()
+
+	if log.Writer() != saved {
+		t.Fatalf("std log writer is not restored")
+	}
+	if log.Prefix() != "" || log.Flags() != log.LstdFlags {
+		t.Fatalf("std log prefix/flags are not restored: %q, %v", log.Prefix(), log.Flags())
+	}
+	buf.Reset()
+	logslog.Info("after undo")
+	if out = buf.String(); out != "" {
+		t.Fatalf("log/slog should be restored, got: %q", out)
+	}
+}
+
+func TestParseStdLogHeader(t *testing.T) {
+	for i, c := range []struct {
+		line   string
+		prefix string
+		flag   int
+		pfx    string
+		msg    string
+	}{
+		{"hello\n", "", 0, "", "hello\n"},
+		{"2009/01/23 01:23:23 hello\n", "", log.LstdFlags, "", "hello\n"},
+		{"[app] 2009/01/23 01:23:23.123123 x.go:23: hello\n", "[app] ", log.LstdFlags | log.Lmicroseconds | log.Lshortfile, "[app]", "hello\n"},
+		{"01:23:23 [app] hello\n", "[app] ", log.Ltime | log.Lmsgprefix, "[app]", "hello\n"},
+		{"/a/b/x.go:23: hello: world\n", "", log.Llongfile, "", "hello: world\n"},
+	} {
+		pfx, msg := parseStdLogHeader([]byte(c.line), c.prefix, c.flag)
+		if pfx != c.pfx || string(msg) != c.msg {
+			t.Fatalf("%5d. expect (%q, %q) but got (%q, %q)", i, c.pfx, c.msg, pfx, msg)
+		}
+	}
+}
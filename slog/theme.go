@@ -0,0 +1,431 @@
+package slog
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/hedzr/is/term/color"
+)
+
+// Style is the fore- and background colour of an element. The
+// background may be an effect too, such as color.BgBoldOrBright.
+type Style struct {
+	Fg color.Color
+	Bg color.Color
+}
+
+// Theme holds the colours of all elements printed in colorful mode.
+//
+// Use WithTheme or SetTheme to apply a theme to a logger, it is
+// inherited by the child loggers. A logger without theme uses the
+// one selected by DetectTheme.
+type Theme struct {
+	Name  string
+	Plain bool // print no colours even if the logger is in colorful mode
+
+	Timestamp Style
+	Logger    Style // the logger name
+	Caller    Style // the caller info and the function names
+	AttrKey   Style // the keys of attributes
+	Error     Style // the errors and the stack traces
+
+	// Levels holds the colours of the level tags and the messages.
+	// The levels not in it use the colours set by SetLevelColors
+	// or RegWithColor.
+	Levels map[Level]Style
+
+	// Values holds the colours of the attribute values by their
+	// kinds. The values of the kinds not in it are printed in the
+	// colour of the level.
+	Values map[ValueKind]Style
+	// Highlights paint the values of the specified keys, such as
+	// the 5xx http status codes, they are checked before Values.
+	Highlights []Highlight
+}
+
+// The built-in themes.
+var (
+	// ThemeDark is the default theme for the dark backgrounds, with
+	// the truecolor info level.
+	ThemeDark = &Theme{
+		Name:      "dark",
+		Timestamp: Style{clrTimestamp, clrNone},
+		Logger:    Style{clrLoggerName, clrLoggerNameBg},
+		Caller:    Style{clrFuncName, clrNone},
+		AttrKey:   Style{clrAttrKey, clrAttrKeyBg},
+		Error:     Style{clrError, clrNone},
+		Values: map[ValueKind]Style{
+			KindNumber:   {color.FgCyan, clrNone},
+			KindBool:     {color.FgLightMagenta, clrNone},
+			KindNil:      {darkGray, clrNone},
+			KindDuration: {color.FgLightCyan, clrNone},
+			KindTime:     {color.FgGreen, clrNone},
+			KindString:   {color.NewColor16m(206, 145, 120, false), clrNone},
+			KindGroup:    {color.FgLightBlue, clrNone},
+		},
+	}
+	// ThemeLight is for the light backgrounds.
+	ThemeLight = &Theme{
+		Name:      "light",
+		Timestamp: Style{color.FgGreen, clrNone},
+		Logger:    Style{color.FgBlack, clrNone},
+		Caller:    Style{darkGray, clrNone},
+		AttrKey:   Style{darkGray, clrNone},
+		Error:     Style{red, clrNone},
+		Levels: map[Level]Style{
+			ErrorLevel:  {red, color.BgBoldOrBright},
+			WarnLevel:   {color.NewColor16m(149, 108, 30, false), clrNone},
+			InfoLevel:   {color.FgBlue, clrNone},
+			DebugLevel:  {color.FgMagenta, clrNone},
+			TraceLevel:  {darkGray, clrNone},
+			AlwaysLevel: {color.FgBlack, clrNone},
+		},
+		Values: map[ValueKind]Style{
+			KindNumber:   {color.FgBlue, clrNone},
+			KindBool:     {color.FgMagenta, clrNone},
+			KindNil:      {darkGray, clrNone},
+			KindDuration: {color.FgCyan, clrNone},
+			KindTime:     {color.FgGreen, clrNone},
+			KindString:   {color.NewColor16m(163, 21, 21, false), clrNone},
+			KindGroup:    {color.FgBlue, clrNone},
+		},
+	}
+	// ThemeHighContrast uses the bright colours and the bold text.
+	ThemeHighContrast = &Theme{
+		Name:      "high-contrast",
+		Timestamp: Style{color.FgLightGreen, clrNone},
+		Logger:    Style{color.FgWhite, color.BgBoldOrBright},
+		Caller:    Style{color.FgLightGray, clrNone},
+		AttrKey:   Style{color.FgLightCyan, clrNone},
+		Error:     Style{hiRed, color.BgBoldOrBright},
+		Levels: map[Level]Style{
+			PanicLevel: {hiRed, color.BgInverse},
+			FatalLevel: {hiRed, color.BgInverse},
+			ErrorLevel: {hiRed, color.BgBoldOrBright},
+			WarnLevel:  {color.FgLightYellow, color.BgBoldOrBright},
+			InfoLevel:  {color.FgWhite, color.BgBoldOrBright},
+			DebugLevel: {color.FgLightMagenta, clrNone},
+			TraceLevel: {color.FgLightYellow, clrNone},
+		},
+		Values: map[ValueKind]Style{
+			KindNumber:   {color.FgLightCyan, color.BgBoldOrBright},
+			KindBool:     {color.FgLightMagenta, color.BgBoldOrBright},
+			KindNil:      {color.FgLightGray, clrNone},
+			KindDuration: {color.FgLightCyan, clrNone},
+			KindTime:     {color.FgLightGreen, clrNone},
+			KindString:   {color.FgLightYellow, clrNone},
+			KindError:    {hiRed, color.BgBoldOrBright},
+			KindGroup:    {color.FgLightBlue, color.BgBoldOrBright},
+		},
+	}
+	// Theme16 uses the 16 basic colours only, for the terminals
+	// without 256 colours.
+	Theme16 = &Theme{
+		Name:      "16",
+		Timestamp: Style{color.FgGreen, clrNone},
+		Logger:    Style{lightGray, clrNone},
+		Caller:    Style{darkGray, clrNone},
+		AttrKey:   Style{darkGray, clrNone},
+		Error:     Style{red, clrNone},
+		Levels: map[Level]Style{
+			InfoLevel: {color.FgLightBlue, clrNone},
+		},
+		Values: map[ValueKind]Style{
+			KindNumber:   {color.FgCyan, clrNone},
+			KindBool:     {color.FgMagenta, clrNone},
+			KindNil:      {darkGray, clrNone},
+			KindDuration: {color.FgLightCyan, clrNone},
+			KindTime:     {color.FgGreen, clrNone},
+			KindString:   {color.FgYellow, clrNone},
+			KindGroup:    {color.FgLightBlue, clrNone},
+		},
+	}
+	// Theme256 uses the 256 colours.
+	Theme256 = &Theme{
+		Name:      "256",
+		Timestamp: Style{color.NewColor256(71, false), clrNone},
+		Logger:    Style{color.NewColor256(250, false), clrNone},
+		Caller:    Style{color.NewColor256(244, false), clrNone},
+		AttrKey:   Style{color.NewColor256(244, false), clrNone},
+		Error:     Style{color.NewColor256(160, false), clrNone},
+		Levels: map[Level]Style{
+			PanicLevel: {color.NewColor256(196, false), color.BgBoldOrBright},
+			FatalLevel: {color.NewColor256(196, false), color.BgBoldOrBright},
+			ErrorLevel: {color.NewColor256(160, false), color.BgBoldOrBright},
+			WarnLevel:  {color.NewColor256(178, false), clrNone},
+			InfoLevel:  {color.NewColor256(68, false), clrNone},
+			DebugLevel: {color.NewColor256(170, false), clrNone},
+			TraceLevel: {color.NewColor256(136, false), color.BgDim},
+		},
+		Values: map[ValueKind]Style{
+			KindNumber:   {color.NewColor256(80, false), clrNone},
+			KindBool:     {color.NewColor256(176, false), clrNone},
+			KindNil:      {color.NewColor256(242, false), clrNone},
+			KindDuration: {color.NewColor256(116, false), clrNone},
+			KindTime:     {color.NewColor256(107, false), clrNone},
+			KindString:   {color.NewColor256(180, false), clrNone},
+			KindGroup:    {color.NewColor256(75, false), clrNone},
+		},
+	}
+	// ThemeNoColor prints no colours.
+	ThemeNoColor = &Theme{Name: "none", Plain: true}
+)
+
+// themes maps the names to the built-in themes, for the "base" of
+// the JSON themes.
+var themes = map[string]*Theme{
+	"dark":          ThemeDark,
+	"truecolor":     ThemeDark,
+	"light":         ThemeLight,
+	"high-contrast": ThemeHighContrast,
+	"16":            Theme16,
+	"256":           Theme256,
+	"none":          ThemeNoColor,
+}
+
+// levelStyle returns the colours of a level.
+func (s *Theme) levelStyle(lvl Level) (st Style, ok bool) {
+	st, ok = s.Levels[lvl]
+	return
+}
+
+//
+//
+//
+
+// themeJSON is the JSON form of a Theme.
+type themeJSON struct {
+	Name       string           `json:"name"`
+	Base       string           `json:"base"` // the name of a built-in theme, default is "dark"
+	Plain      *bool            `json:"plain"`
+	Timestamp  *Style           `json:"timestamp"`
+	Logger     *Style           `json:"logger"`
+	Caller     *Style           `json:"caller"`
+	AttrKey    *Style           `json:"key"`
+	Error      *Style           `json:"error"`
+	Levels     map[string]Style `json:"levels"`
+	Values     map[string]Style `json:"values"`
+	Highlights []struct {
+		Key   string `json:"key"`
+		When  string `json:"when"`
+		Style Style  `json:"style"`
+	} `json:"highlights"`
+}
+
+// LoadTheme decodes a theme from JSON. The elements absent are taken
+// from the base theme. For example:
+//
+//	{
+//	  "name": "mine",
+//	  "base": "light",
+//	  "timestamp": "#5f8700",
+//	  "logger": {"fg": "white", "bg": "bold"},
+//	  "key": "244",
+//	  "levels": {"info": "cyan", "error": {"fg": "red", "bg": "bold"}},
+//	  "values": {"number": "lightblue", "string": "#ce9178"},
+//	  "highlights": [{"key": "status", "when": ">=500", "style": "red"}]
+//	}
+//
+// The value kinds are number, bool, nil, duration, time, string,
+// error and group. A highlight without "when" matches any value,
+// see NewHighlight.
+//
+// A colour is a name (such as "red", "lightblue", "bold"), a number
+// of the 256 colours, or "#rrggbb" for truecolor. A style is a
+// colour for the foreground, or an object with "fg" and "bg".
+func LoadTheme(data []byte) (theme *Theme, err error) {
+	var tj themeJSON
+	if err = json.Unmarshal(data, &tj); err != nil {
+		return nil, fmt.Errorf("slog: bad theme: %w", err)
+	}
+
+	base := ThemeDark
+	if tj.Base != "" {
+		var ok bool
+		if base, ok = themes[tj.Base]; !ok {
+			return nil, fmt.Errorf("slog: bad theme: unknown base %q", tj.Base)
+		}
+	}
+	theme = base.clone()
+	theme.Name = tj.Name
+	if tj.Plain != nil {
+		theme.Plain = *tj.Plain
+	}
+	for _, f := range []struct {
+		from *Style
+		to   *Style
+	}{
+		{tj.Timestamp, &theme.Timestamp},
+		{tj.Logger, &theme.Logger},
+		{tj.Caller, &theme.Caller},
+		{tj.AttrKey, &theme.AttrKey},
+		{tj.Error, &theme.Error},
+	} {
+		if f.from != nil {
+			*f.to = *f.from
+		}
+	}
+	for name, st := range tj.Levels {
+		lvl, ok := stringToLevel[strings.ToLower(name)]
+		if !ok {
+			return nil, fmt.Errorf("slog: bad theme: unknown level %q", name)
+		}
+		theme.Levels[lvl] = st
+	}
+	for name, st := range tj.Values {
+		kind, ok := valueKindNames[strings.ToLower(name)]
+		if !ok {
+			return nil, fmt.Errorf("slog: bad theme: unknown value kind %q", name)
+		}
+		theme.Values[kind] = st
+	}
+	for _, h := range tj.Highlights {
+		var hl Highlight
+		if hl, err = NewHighlight(h.Key, h.When, h.Style); err != nil {
+			return nil, err
+		}
+		theme.Highlights = append(theme.Highlights, hl)
+	}
+	return
+}
+
+// LoadThemeFile decodes a theme from a JSON file, see LoadTheme.
+func LoadThemeFile(filename string) (theme *Theme, err error) {
+	var data []byte
+	if data, err = os.ReadFile(filename); err != nil {
+		return
+	}
+	return LoadTheme(data)
+}
+
+func (s *Theme) clone() *Theme {
+	t := *s
+	t.Levels = make(map[Level]Style, len(s.Levels))
+	for k, v := range s.Levels {
+		t.Levels[k] = v
+	}
+	t.Values = make(map[ValueKind]Style, len(s.Values))
+	for k, v := range s.Values {
+		t.Values[k] = v
+	}
+	t.Highlights = append([]Highlight(nil), s.Highlights...)
+	return &t
+}
+
+// UnmarshalJSON decodes a colour name, or an object with "fg" and
+// "bg". See LoadTheme.
+func (s *Style) UnmarshalJSON(data []byte) (err error) {
+	var fg string
+	if err = json.Unmarshal(data, &fg); err == nil {
+		s.Fg, err = parseThemeColor(fg, false)
+		s.Bg = clrNone
+		return
+	}
+	var obj struct {
+		Fg string `json:"fg"`
+		Bg string `json:"bg"`
+	}
+	if err = json.Unmarshal(data, &obj); err != nil {
+		return
+	}
+	if s.Fg, err = parseThemeColor(obj.Fg, false); err == nil {
+		s.Bg, err = parseThemeColor(obj.Bg, true)
+	}
+	return
+}
+
+var themeEffects = map[string]color.Color{
+	"none":      clrNone,
+	"":          clrNone,
+	"underline": color.BgUnderline,
+	"blink":     color.BgBlink,
+	"inverse":   color.BgInverse,
+}
+
+func parseThemeColor(name string, bg bool) (clr color.Color, err error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if c, ok := themeEffects[name]; ok {
+		return c, nil
+	}
+	if c, ok := layoutColors[name]; ok {
+		return c, nil
+	}
+	if hex, ok := strings.CutPrefix(name, "#"); ok && len(hex) == 6 {
+		if v, e := strconv.ParseUint(hex, 16, 32); e == nil {
+			return color.NewColor16m(byte(v>>16), byte(v>>8), byte(v), bg), nil
+		}
+	}
+	if v, e := strconv.ParseUint(name, 10, 8); e == nil {
+		return color.NewColor256(byte(v), bg), nil
+	}
+	return nil, fmt.Errorf("unknown color %q", name)
+}
+
+//
+//
+//
+
+// detectedTheme caches the result of DetectTheme.
+var detectedTheme atomic.Pointer[Theme]
+
+// DetectTheme selects a built-in theme by the environment variables:
+//
+//   - NO_COLOR: ThemeNoColor, unless CLICOLOR_FORCE is set
+//   - COLORFGBG: ThemeLight if the background is light, such as
+//     "0;15", or ThemeDark
+func DetectTheme() *Theme {
+	if force := os.Getenv("CLICOLOR_FORCE"); force == "" || force == "0" {
+		if os.Getenv("NO_COLOR") != "" {
+			return ThemeNoColor
+		}
+	}
+	if fgbg := os.Getenv("COLORFGBG"); fgbg != "" {
+		// "fg;bg" or "fg;default;bg"
+		if bg, err := strconv.Atoi(fgbg[strings.LastIndexByte(fgbg, ';')+1:]); err == nil && (bg == 7 || bg >= 9 && bg <= 15) {
+			return ThemeLight
+		}
+	}
+	return ThemeDark
+}
+
+func defaultTheme() *Theme {
+	t := detectedTheme.Load()
+	if t == nil {
+		t = DetectTheme()
+		detectedTheme.Store(t)
+	}
+	return t
+}
+
+// WithTheme sets the [Theme] of a logger.
+func WithTheme(theme *Theme) Opt {
+	return func(s *Entry) {
+		s.SetTheme(theme)
+	}
+}
+
+// SetTheme sets the [Theme] of this logger, it is inherited by the
+// child loggers. Passing nil makes it use the theme of the parent.
+func (s *Entry) SetTheme(theme *Theme) *Entry {
+	s.theme = theme
+	return s
+}
+
+// WithTheme makes a child logger with the given [Theme].
+func (s *Entry) WithTheme(theme *Theme) (newLogger *Entry) {
+	return s.newChildLogger(WithTheme(theme))
+}
+
+// Theme returns the [Theme] of this logger or its parents.
+func (s *Entry) Theme() *Theme {
+	for p := s; p != nil; p = p.owner {
+		if p.theme != nil {
+			return p.theme
+		}
+	}
+	return defaultTheme()
+}
@@ -0,0 +1,122 @@
+package slog
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/hedzr/is/term/color"
+)
+
+func TestLoadTheme(t *testing.T) {
+	theme, err := LoadTheme([]byte(`{
+		"name": "mine",
+		"base": "light",
+		"timestamp": "#5f8700",
+		"logger": {"fg": "white", "bg": "bold"},
+		"key": "244",
+		"levels": {"info": "cyan", "WARN": {"fg": "red", "bg": "underline"}}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if theme.Name != "mine" || theme.Plain {
+		t.Fatalf("unexpected theme: %+v", theme)
+	}
+	for _, c := range []struct {
+		got  color.Color
+		want string
+	}{
+		{theme.Timestamp.Fg, color.NewColor16m(0x5f, 0x87, 0, false).Color()},
+		{theme.Logger.Fg, color.FgWhite.Color()},
+		{theme.Logger.Bg, color.BgBoldOrBright.Color()},
+		{theme.AttrKey.Fg, color.NewColor256(244, false).Color()},
+		{theme.Caller.Fg, ThemeLight.Caller.Fg.Color()}, // from the base
+		{theme.Levels[InfoLevel].Fg, color.FgCyan.Color()},
+		{theme.Levels[WarnLevel].Bg, color.BgUnderline.Color()},
+		{theme.Levels[DebugLevel].Fg, ThemeLight.Levels[DebugLevel].Fg.Color()},
+	} {
+		if c.got.Color() != c.want {
+			t.Fatalf("expect %q, but got %q", c.want, c.got.Color())
+		}
+	}
+	if len(ThemeLight.Levels) != 6 {
+		t.Fatal("the base theme was modified")
+	}
+
+	for _, data := range []string{
+		`{"base": "unknown"}`,
+		`{"timestamp": "nocolor"}`,
+		`{"levels": {"nolevel": "red"}}`,
+		`{"logger": 1}`,
+		`[`,
+	} {
+		if _, err = LoadTheme([]byte(data)); err == nil {
+			t.Fatalf("expect an error for %s", data)
+		}
+	}
+}
+
+func TestDetectTheme(t *testing.T) {
+	for _, c := range []struct {
+		noColor, force, fgbg string
+		want                 *Theme
+	}{
+		{"", "", "", ThemeDark},
+		{"1", "", "", ThemeNoColor},
+		{"1", "1", "", ThemeDark},
+		{"1", "0", "", ThemeNoColor},
+		{"", "", "15;0", ThemeDark},
+		{"", "", "0;15", ThemeLight},
+		{"", "", "0;default;7", ThemeLight},
+		{"", "", "7;8", ThemeDark},
+	} {
+		t.Setenv("NO_COLOR", c.noColor)
+		t.Setenv("CLICOLOR_FORCE", c.force)
+		t.Setenv("COLORFGBG", c.fgbg)
+		if got := DetectTheme(); got != c.want {
+			t.Fatalf("NO_COLOR=%q CLICOLOR_FORCE=%q COLORFGBG=%q: expect %q, but got %q",
+				c.noColor, c.force, c.fgbg, c.want.Name, got.Name)
+		}
+	}
+}
+
+func TestEntryTheme(t *testing.T) {
+	mine := ThemeDark.clone()
+	mine.Timestamp = Style{color.NewColor256(99, false), clrNone}
+	mine.Levels[InfoLevel] = Style{color.NewColor256(33, false), clrNone}
+
+	var buf bytes.Buffer
+	parent := New("themed", WithWriter(&buf), WithLevel(InfoLevel), WithColorMode(), WithTheme(mine))
+	child := parent.New("child", WithWriter(&buf))
+	other := New("other", WithWriter(&buf), WithLevel(InfoLevel), WithColorMode())
+
+	for _, l := range []Logger{parent, child} {
+		buf.Reset()
+		l.Info("hello")
+		if out := buf.String(); !strings.HasPrefix(out, "\x1b[38;5;99m") || !strings.Contains(out, "\x1b[38;5;33m[INF]") {
+			t.Fatalf("expect the colours of the theme, but got %q", out)
+		}
+	}
+
+	buf.Reset()
+	other.Info("hello")
+	if strings.Contains(buf.String(), "38;5;") {
+		t.Fatalf("expect the default theme, but got %q", buf.String())
+	}
+
+	buf.Reset()
+	child.SetTheme(ThemeNoColor)
+	child.Info("hello", "k", 1)
+	if strings.Contains(buf.String(), "\x1b[") {
+		t.Fatalf("expect no colours, but got %q", buf.String())
+	}
+
+	buf.Reset()
+	layout := New("layout", WithWriter(&buf), WithLevel(InfoLevel), WithTheme(ThemeNoColor),
+		WithPainter(MustLayoutPainter("{color:red}{level} {msg}", LayoutColorful(true))))
+	layout.Info("hello\nworld")
+	if strings.Contains(buf.String(), "\x1b[") {
+		t.Fatalf("expect no colours, but got %q", buf.String())
+	}
+}
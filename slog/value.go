@@ -0,0 +1,283 @@
+package slog
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+// ValueType is the type of the value held by a [Value].
+type ValueType int
+
+const (
+	TypeAny      ValueType = iota // any other value, it is boxed in an interface
+	TypeBool                      // bool
+	TypeInt64                     // int, int8, int16, int32 and int64
+	TypeUint64                    // uint, uint8, uint16, uint32, uint64 and uintptr
+	TypeFloat32                   // float32
+	TypeFloat64                   // float64
+	TypeString                    // string
+	TypeDuration                  // time.Duration
+	TypeTime                      // time.Time
+)
+
+// Value is a union of the common types of the attribute values, like
+// log/slog.Value. The numbers, the strings, the durations and the
+// times are held without boxing, so that the painters write them
+// without allocation. The typed constructors, such as String, Int and
+// Duration, allocate the attribute only.
+//
+// The zero Value is a TypeAny value holding nil.
+type Value struct {
+	typ ValueType
+	num uint64 // the bits of bool, numbers, durations, and the Unix nanoseconds of times
+	str string // the string
+	any any    // the value of TypeAny, the *time.Location of TypeTime, or a zero of the original type of integers
+}
+
+// BoolValue returns the Value of a bool.
+func BoolValue(v bool) Value {
+	var n uint64
+	if v {
+		n = 1
+	}
+	return Value{typ: TypeBool, num: n}
+}
+
+func IntValue(v int) Value                { return intValue(int64(v), 0) }                                     // the Value of an int.
+func Int64Value(v int64) Value            { return Value{typ: TypeInt64, num: uint64(v)} }                     // the Value of an int64.
+func Uint64Value(v uint64) Value          { return Value{typ: TypeUint64, num: v} }                            // the Value of an uint64.
+func Float32Value(v float32) Value        { return Value{typ: TypeFloat32, num: uint64(math.Float32bits(v))} } // the Value of a float32.
+func Float64Value(v float64) Value        { return Value{typ: TypeFloat64, num: math.Float64bits(v)} }         // the Value of a float64.
+func StringValue(v string) Value          { return Value{typ: TypeString, str: v} }                            // the Value of a string.
+func DurationValue(v time.Duration) Value { return Value{typ: TypeDuration, num: uint64(v)} }                  // the Value of a time.Duration.
+
+// intValue and uintValue return the Value of an integer, which keeps
+// zero, a constant of the original type, so that Any returns the
+// integer in the type. A constant is boxed without allocation.
+func intValue(v int64, zero any) Value   { return Value{typ: TypeInt64, num: uint64(v), any: zero} }
+func uintValue(v uint64, zero any) Value { return Value{typ: TypeUint64, num: v, any: zero} }
+
+// TimeValue returns the Value of a time.Time. The monotonic clock
+// reading is dropped. The times out of the range of Unix nanoseconds,
+// about the years 1678 to 2262, are boxed.
+func TimeValue(v time.Time) Value {
+	if v.IsZero() {
+		return Value{typ: TypeTime} // a nil location means the zero time
+	}
+	if y := v.Year(); y < 1678 || y > 2261 {
+		return Value{typ: TypeTime, any: v}
+	}
+	return Value{typ: TypeTime, num: uint64(v.UnixNano()), any: v.Location()}
+}
+
+// AnyValue returns the Value of v, the common types are held without
+// boxing, and the others are held as TypeAny.
+func AnyValue(v any) Value {
+	switch z := v.(type) {
+	case Value:
+		return z
+	case bool:
+		return BoolValue(z)
+	case int:
+		return IntValue(z)
+	case int8:
+		return intValue(int64(z), int8(0))
+	case int16:
+		return intValue(int64(z), int16(0))
+	case int32:
+		return intValue(int64(z), int32(0))
+	case int64:
+		return Int64Value(z)
+	case uint:
+		return uintValue(uint64(z), uint(0))
+	case uint8:
+		return uintValue(uint64(z), uint8(0))
+	case uint16:
+		return uintValue(uint64(z), uint16(0))
+	case uint32:
+		return uintValue(uint64(z), uint32(0))
+	case uint64:
+		return Uint64Value(z)
+	case uintptr:
+		return uintValue(uint64(z), uintptr(0))
+	case float32:
+		return Float32Value(z)
+	case float64:
+		return Float64Value(z)
+	case string:
+		return StringValue(z)
+	case time.Duration:
+		return DurationValue(z)
+	case time.Time:
+		return TimeValue(z)
+	}
+	return Value{any: v}
+}
+
+func (v Value) Type() ValueType { return v.typ } // the type of the held value.
+
+// Any returns the held value. The integers are returned in their
+// original types, such as an int for IntValue and Int, and the ones
+// of Int64Value and Uint64Value as int64 and uint64.
+func (v Value) Any() any {
+	switch v.typ {
+	case TypeBool:
+		return v.Bool()
+	case TypeInt64:
+		switch v.any.(type) {
+		case int:
+			return int(v.Int64())
+		case int8:
+			return int8(v.Int64())
+		case int16:
+			return int16(v.Int64())
+		case int32:
+			return int32(v.Int64())
+		}
+		return v.Int64()
+	case TypeUint64:
+		switch v.any.(type) {
+		case uint:
+			return uint(v.num)
+		case uint8:
+			return uint8(v.num)
+		case uint16:
+			return uint16(v.num)
+		case uint32:
+			return uint32(v.num)
+		case uintptr:
+			return uintptr(v.num)
+		}
+		return v.num
+	case TypeFloat32:
+		return v.Float32()
+	case TypeFloat64:
+		return v.Float64()
+	case TypeString:
+		return v.str
+	case TypeDuration:
+		return v.Duration()
+	case TypeTime:
+		return v.Time()
+	}
+	return v.any
+}
+
+func (v Value) Bool() bool              { return v.num != 0 }                          // the bool, for TypeBool.
+func (v Value) Int64() int64            { return int64(v.num) }                        // the int64, for TypeInt64.
+func (v Value) Uint64() uint64          { return v.num }                               // the uint64, for TypeUint64.
+func (v Value) Float32() float32        { return math.Float32frombits(uint32(v.num)) } // the float32, for TypeFloat32.
+func (v Value) Float64() float64        { return math.Float64frombits(v.num) }         // the float64, for TypeFloat64.
+func (v Value) Duration() time.Duration { return time.Duration(int64(v.num)) }         // the time.Duration, for TypeDuration.
+
+// Time returns the time.Time, for TypeTime.
+func (v Value) Time() time.Time {
+	switch z := v.any.(type) {
+	case *time.Location:
+		return time.Unix(0, int64(v.num)).In(z)
+	case time.Time:
+		return z
+	}
+	return time.Time{}
+}
+
+// group returns the attributes of a group value.
+func (v Value) group() (g Attrs, ok bool) {
+	if v.typ == TypeAny {
+		switch z := v.any.(type) {
+		case Attrs:
+			return z, true
+		case Attr: // an attribute as a value is a group of it
+			return Attrs{z}, z != nil
+		}
+	}
+	return
+}
+
+// timeOf returns the time of a time value.
+func (v Value) timeOf() (z time.Time, ok bool) {
+	if v.typ == TypeTime {
+		return v.Time(), true
+	}
+	if v.typ == TypeAny {
+		z, ok = v.any.(time.Time)
+	}
+	return
+}
+
+// String returns the string of TypeString, or the text of the other
+// values.
+func (v Value) String() string {
+	switch v.typ {
+	case TypeString:
+		return v.str
+	case TypeBool:
+		return strconv.FormatBool(v.Bool())
+	case TypeInt64:
+		return strconv.FormatInt(v.Int64(), 10)
+	case TypeUint64:
+		return strconv.FormatUint(v.num, 10)
+	case TypeFloat32:
+		return strconv.FormatFloat(float64(v.Float32()), 'g', -1, 32)
+	case TypeFloat64:
+		return strconv.FormatFloat(v.Float64(), 'g', -1, 64)
+	case TypeDuration:
+		return v.Duration().String()
+	case TypeTime:
+		return v.Time().String()
+	}
+	return fmt.Sprint(v.any)
+}
+
+// NewValueAttr creates an attribute with a [Value], it holds the
+// common types of values without boxing.
+func NewValueAttr(key string, val Value) Attr { return &vkvp{key, val} }
+
+// vkvp is a key-value pair holding a Value.
+type vkvp struct {
+	key string
+	val Value
+}
+
+// valueAttr is implemented by the attributes holding a Value, which
+// are serialized without boxing the value.
+type valueAttr interface {
+	Attr
+	TypedValue() Value
+}
+
+func (s *vkvp) Key() string       { return s.key }
+func (s *vkvp) Value() any        { return s.val.Any() }
+func (s *vkvp) SetValue(v any)    { s.val = AnyValue(v) }
+func (s *vkvp) TypedValue() Value { return s.val }
+
+// SerializeValueTo writes an attribute used as a value as a group of
+// it, see kvp.SerializeValueTo.
+func (s *vkvp) SerializeValueTo(pc *PrintCtx) { Attrs{s}.SerializeValueTo(pc) }
+
+// appendTypedValue writes a Value, the common types are written
+// without boxing.
+func (s *PrintCtx) appendTypedValue(v Value) {
+	switch v.typ {
+	case TypeBool:
+		btoaS(s, v.Bool())
+	case TypeInt64:
+		itoaS(s, v.Int64())
+	case TypeUint64:
+		utoaS(s, v.num)
+	case TypeFloat32:
+		ftoaS(s, v.Float32())
+	case TypeFloat64:
+		ftoaS(s, v.Float64())
+	case TypeString:
+		s.pcQuoteValue(v.str)
+	case TypeDuration:
+		s.AppendDuration(v.Duration())
+	case TypeTime:
+		s.AppendTime(v.Time())
+	default:
+		s.appendValue(v.any)
+	}
+}
@@ -0,0 +1,150 @@
+package slog
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestValueAny(t *testing.T) {
+	tm := time.Date(2024, 5, 6, 7, 8, 9, 500, time.FixedZone("X", 3600))
+	for i, c := range []struct {
+		val  any
+		typ  ValueType
+		want any
+	}{
+		{true, TypeBool, true},
+		{-3, TypeInt64, -3},
+		{int8(-8), TypeInt64, int8(-8)},
+		{int64(-64), TypeInt64, int64(-64)},
+		{uint16(16), TypeUint64, uint16(16)},
+		{uint64(64), TypeUint64, uint64(64)},
+		{uintptr(1), TypeUint64, uintptr(1)},
+		{float32(0.1), TypeFloat32, float32(0.1)},
+		{2.5, TypeFloat64, 2.5},
+		{"s", TypeString, "s"},
+		{time.Second, TypeDuration, time.Second},
+		{tm, TypeTime, tm},
+		{time.Time{}, TypeTime, time.Time{}},
+		{[]int{1}, TypeAny, nil},
+		{nil, TypeAny, nil},
+	} {
+		v := AnyValue(c.val)
+		if v.Type() != c.typ {
+			t.Fatalf("%d. expect type %v, but got %v", i, c.typ, v.Type())
+		}
+		if c.typ == TypeTime {
+			if got := v.Any().(time.Time); !got.Equal(c.want.(time.Time)) || got.Location().String() != c.want.(time.Time).Location().String() {
+				t.Fatalf("%d. expect %v, but got %v", i, c.want, got)
+			}
+			continue
+		}
+		if c.typ != TypeAny && v.Any() != c.want {
+			t.Fatalf("%d. expect %v (%T), but got %v (%T)", i, c.want, c.want, v.Any(), v.Any())
+		}
+	}
+
+	far := time.Date(3000, 1, 2, 3, 4, 5, 6, time.UTC)
+	if got := TimeValue(far).Time(); !got.Equal(far) {
+		t.Fatalf("expect %v, but got %v", far, got)
+	}
+	if got := AnyValue(-3).String(); got != "-3" {
+		t.Fatalf("expect -3, but got %q", got)
+	}
+}
+
+func TestValueAttrs(t *testing.T) {
+	tm := time.Date(2024, 5, 6, 7, 8, 9, 500, time.UTC)
+	typed := Attrs{String("s", "a b"), Int("i", -1), Uint8("u", 8), Float32("f32", 0.1),
+		Float64("f", 2.5), Bool("b", true), Duration("d", time.Second), Time("t", tm),
+		NewValueAttr("v", AnyValue([]int{1, 2})), Time("time", tm)}
+	boxed := Attrs{NewAttr("s", "a b"), NewAttr("i", -1), NewAttr("u", uint8(8)), NewAttr("f32", float32(0.1)),
+		NewAttr("f", 2.5), NewAttr("b", true), NewAttr("d", time.Second), NewAttr("t", tm),
+		NewAttr("v", []int{1, 2}), NewAttr("time", tm)}
+	for _, mode := range []Mode{ModeJSON, ModeLogFmt, ModePlain, ModeColorful, ModeCBOR, ModeMsgPack} {
+		var out [2][]byte
+		for i, kvps := range []Attrs{typed, boxed} {
+			pc := NewPrintCtx(WithPCMode(mode), WithPCLimits(Limits{MaxStringLen: 2}))
+			_ = serializeAttrs(pc, kvps)
+			out[i] = pc.Bytes()
+		}
+		if !bytes.Equal(out[0], out[1]) {
+			t.Fatalf("%v: expect %q, but got %q", mode, out[1], out[0])
+		}
+	}
+
+	for i, c := range []struct {
+		a    Attr
+		want any
+	}{
+		{Int("i", 1), 1},
+		{Int8("i", 1), int8(1)},
+		{Int64("i", 1), int64(1)},
+		{Uint("u", 1), uint(1)},
+		{Uint32("u", 1), uint32(1)},
+		{Uint64("u", 1), uint64(1)},
+	} {
+		if got := c.a.Value(); got != c.want {
+			t.Fatalf("%d. expect %v (%T), but got %v (%T)", i, c.want, c.want, got, got)
+		}
+	}
+
+	a := Int("i", 1)
+	a.SetValue("x")
+	if a.Value() != "x" {
+		t.Fatalf("expect the value set, but got %v", a.Value())
+	}
+}
+
+func TestValueAllocs(t *testing.T) {
+	if raceEnabled {
+		t.Skip("skipping the allocation test under the race detector")
+	}
+	sink := make(Attrs, 4)
+	if n := testing.AllocsPerRun(100, func() {
+		sink[0] = Int("i", 1)
+		sink[1] = Duration("d", time.Second)
+		sink[2] = String("s", "text")
+		sink[3] = Numeric("n", uint16(1))
+	}); n != 4 {
+		t.Fatalf("expect one allocation for each attr, but got %v", n)
+	}
+	var v Value
+	if n := testing.AllocsPerRun(100, func() {
+		v = IntValue(1000)
+		v = AnyValue(uint16(1000))
+	}); n != 0 || v.Any() != uint16(1000) {
+		t.Fatalf("expect the integers kept their types without allocation, but got %v allocs", n)
+	}
+
+	kvps := Attrs{String("s", "a"), Int("i", 1), Uint64("u", 2), Float64("f", 2.5), Bool("b", true),
+		Time("t", time.Unix(1, 0).UTC()), Duration("d", time.Second)}
+	for _, mode := range []Mode{ModeJSON, ModeLogFmt, ModePlain, ModeCBOR} {
+		pc := NewPrintCtx(WithPCMode(mode))
+		if mode == ModeCBOR {
+			kvps = kvps[:len(kvps)-1] // the text of a duration is allocated in binary formats
+		}
+		if n := testing.AllocsPerRun(100, func() {
+			pc.Reset()
+			_ = serializeAttrs(pc, kvps)
+		}); n != 0 {
+			t.Fatalf("%v: expect no allocation, but got %v", mode, n)
+		}
+	}
+	defer SaveFlagsAndMod(Lempty, Lcaller|LattrsR)()
+	args := make([]any, len(kvps))
+	for i, a := range kvps {
+		args[i] = a
+	}
+	ctx := context.Background()
+	for _, opt := range []Opt{WithJSONMode(), WithMode(ModeLogFmt), WithColorMode(false)} {
+		l := New("value", WithWriter(io.Discard), WithLevel(InfoLevel), opt)
+		if n := testing.AllocsPerRun(100, func() {
+			l.InfoContext(ctx, "hello", args...)
+		}); n != 0 {
+			t.Fatalf("%v: expect no allocation in logging, but got %v", l.(*logimp).mode, n)
+		}
+	}
+}
@@ -250,6 +250,15 @@ func (s LWs) Close() (err error) {
 	return
 }
 
+// setLevel sets lvl to the writers which are LevelSettable.
+func (s LWs) setLevel(lvl Level) {
+	for _, w := range s {
+		if x, ok := w.(LevelSettable); ok {
+			x.SetLevel(lvl)
+		}
+	}
+}
+
 func (s LWs) Write(p []byte) (n int, err error) {
 	// TO/DO implement me
 	// /panic("implement me")